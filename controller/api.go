@@ -0,0 +1,640 @@
+package controller
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// API のエラーコード
+const (
+	apiErrorBadRequest   = "bad_request"
+	apiErrorUnauthorized = "unauthorized"
+	apiErrorForbidden    = "forbidden"
+	apiErrorNotFound     = "not_found"
+	apiErrorConflict     = "conflict"
+	apiErrorGone         = "gone"
+	apiErrorInternal     = "internal_error"
+
+	apiErrorTooLarge             = "payload_too_large"
+	apiErrorUnsupportedMediaType = "unsupported_media_type"
+)
+
+// ページングの既定値と上限
+const (
+	apiDefaultLimit = 20
+	apiMaxLimit     = 100
+)
+
+type IAPIController interface {
+	getMe(c *gin.Context)
+	getNotes(c *gin.Context)
+	getSearchNotes(c *gin.Context)
+	postNote(c *gin.Context)
+	getNote(c *gin.Context)
+	patchNote(c *gin.Context)
+	deleteNote(c *gin.Context)
+	getNoteShares(c *gin.Context)
+	postNoteShare(c *gin.Context)
+	getShare(c *gin.Context)
+	deleteShare(c *gin.Context)
+	getExport(c *gin.Context)
+	postImport(c *gin.Context)
+}
+
+type apiController struct {
+	noteUsecase   usecase.INoteUsecase
+	shareUsecase  usecase.IShareUsecase
+	exportUsecase usecase.IExportUsecase
+	importUsecase usecase.IImportUsecase
+}
+
+// apiError はすべてのエラーレスポンスで共通の形式
+// {"error": {"code": "...", "message": "...", "details": ...}}
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Details any    `json:"details,omitempty"`
+}
+
+type apiPagination struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// apiCursorPagination はカーソルで続きを取得する一覧のページ情報。nextCursor は最後のページでは空
+type apiCursorPagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"nextCursor"`
+}
+
+type apiUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// apiNote はメモのリソース表現。一覧では本文（content）を省略する
+type apiNote struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Content   *string    `json:"content,omitempty"`
+	Format    string     `json:"format"`
+	FolderID  *string    `json:"folderId"`
+	Version   int32      `json:"version"`
+	Pinned    bool       `json:"pinned"`
+	Favorite  bool       `json:"favorite"`
+	CreatedAt *time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type apiNoteSearchResult struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	SnippetHTML string     `json:"snippetHtml"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+}
+
+// apiShare は共有リンクを保存されている設定のまま返す。expired は期限切れか閲覧回数の上限に達したか
+type apiShare struct {
+	ID                string     `json:"id"`
+	NoteID            string     `json:"noteId"`
+	Editable          bool       `json:"editable"`
+	URL               string     `json:"url"`
+	ExpiresAt         *time.Time `json:"expiresAt"`
+	MaxViews          *int32     `json:"maxViews"`
+	ViewCount         int32      `json:"viewCount"`
+	EditableUntil     *time.Time `json:"editableUntil"`
+	Expired           bool       `json:"expired"`
+	PasswordProtected bool       `json:"passwordProtected"`
+}
+
+type apiCreateNoteRequest struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+	Format  *string `json:"format"`
+}
+
+// apiUpdateNoteRequest は指定した項目だけを更新する。version は競合検知のため必須
+type apiUpdateNoteRequest struct {
+	Title   *string `json:"title"`
+	Content *string `json:"content"`
+	Format  *string `json:"format"`
+	Version *int32  `json:"version"`
+}
+
+type apiCreateShareRequest struct {
+	Editable bool   `json:"editable"`
+	Password string `json:"password"`
+	shareLimits
+}
+
+func NewAPIController(router *gin.Engine) IAPIController {
+	instance := &apiController{
+		noteUsecase:   usecase.NewNoteUsecase(),
+		shareUsecase:  usecase.NewShareUsecase(),
+		exportUsecase: usecase.NewExportUsecase(),
+		importUsecase: usecase.NewImportUsecase(),
+	}
+	setupAPIRoute(instance, router)
+	return instance
+}
+
+func setupAPIRoute(api IAPIController, router *gin.Engine) {
+	v1 := router.Group("/api/v1")
+	v1.Use(secure.APIAuthMiddleware(usecase.NewAccessTokenUsecase(), func(c *gin.Context) {
+		abortWithAPIError(c, http.StatusUnauthorized, apiErrorUnauthorized, "authentication required")
+	}))
+	{
+		v1.GET("/me", api.getMe)
+
+		notesRead := requireAPIScope(usecase.ScopeNotesRead)
+		notesWrite := requireAPIScope(usecase.ScopeNotesWrite)
+		sharesManage := requireAPIScope(usecase.ScopeSharesManage)
+
+		v1.GET("/notes", notesRead, api.getNotes)
+		v1.POST("/notes", notesWrite, api.postNote)
+		v1.GET("/notes/search", notesRead, api.getSearchNotes)
+		v1.GET("/notes/:id", notesRead, api.getNote)
+		v1.PATCH("/notes/:id", notesWrite, api.patchNote)
+		v1.DELETE("/notes/:id", notesWrite, api.deleteNote)
+		v1.GET("/notes/:id/shares", sharesManage, api.getNoteShares)
+		v1.POST("/notes/:id/shares", sharesManage, api.postNoteShare)
+
+		v1.GET("/shares/:id", sharesManage, api.getShare)
+		v1.DELETE("/shares/:id", sharesManage, api.deleteShare)
+
+		v1.GET("/export", notesRead, api.getExport)
+		v1.POST("/import", notesWrite, api.postImport)
+	}
+}
+
+// requireAPIScope はアクセストークンに scope が付与されていない場合に 403 を返す
+func requireAPIScope(scope string) gin.HandlerFunc {
+	return secure.RequireScope(scope, func(c *gin.Context) {
+		abortWithAPIError(c, http.StatusForbidden, apiErrorForbidden, "access token lacks the "+scope+" scope")
+	})
+}
+
+func abortWithAPIError(c *gin.Context, status int, code string, message string) {
+	c.AbortWithStatusJSON(status, gin.H{"error": apiError{Code: code, Message: message}})
+}
+
+// parseLimit は limit を読み取る。不正な値の場合は 400 を返して false を返す
+func parseLimit(c *gin.Context) (int, bool) {
+	value := c.Query("limit")
+	if value == "" {
+		return apiDefaultLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > apiMaxLimit {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "limit must be between 1 and "+strconv.Itoa(apiMaxLimit))
+		return 0, false
+	}
+	return limit, true
+}
+
+// parsePagination は limit と offset を読み取る。不正な値の場合は 400 を返して false を返す
+func parsePagination(c *gin.Context) (apiPagination, bool) {
+	limit, ok := parseLimit(c)
+	if !ok {
+		return apiPagination{}, false
+	}
+	page := apiPagination{Limit: limit}
+	if value := c.Query("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "offset must be a non-negative integer")
+			return page, false
+		}
+		page.Offset = offset
+	}
+	return page, true
+}
+
+func newAPINote(note *domain.Note, withContent bool) apiNote {
+	resource := apiNote{
+		ID:        note.ID,
+		Title:     note.Title,
+		Format:    note.Format,
+		FolderID:  note.FolderID,
+		Version:   note.Version,
+		Pinned:    note.Pinned,
+		Favorite:  note.Favorite,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+	}
+	if withContent {
+		content := note.Content
+		resource.Content = &content
+	}
+	return resource
+}
+
+func newAPIShare(share *domain.SharingInfo) apiShare {
+	return apiShare{
+		ID:                share.ID,
+		NoteID:            share.NoteID,
+		Editable:          share.Editable,
+		URL:               "/share/" + share.ID,
+		ExpiresAt:         share.ExpiresAt,
+		MaxViews:          share.MaxViews,
+		ViewCount:         share.ViewCount,
+		EditableUntil:     share.EditableUntil,
+		Expired:           usecase.ShareExpired(share, time.Now()),
+		PasswordProtected: usecase.SharePasswordProtected(share),
+	}
+}
+
+// findOwnedNote はログイン中のユーザーのメモを取得する。
+// 他のユーザーのメモは存在を明かさないよう 404 とする
+func (a *apiController) findOwnedNote(c *gin.Context, noteID string) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := a.noteUsecase.Find(noteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		if trashed, findErr := a.noteUsecase.FindTrashed(noteID); findErr == nil && trashed.AuthorID == user.ID {
+			abortWithAPIError(c, http.StatusGone, apiErrorGone, "note is in the trash")
+			return nil, false
+		}
+	}
+	if err != nil || note.AuthorID != user.ID {
+		abortWithAPIError(c, http.StatusNotFound, apiErrorNotFound, "note not found")
+		return nil, false
+	}
+	return note, true
+}
+
+// findOwnedShare は共有リンクとその対象のメモを取得する。対象のメモの所有者以外には 404 とする
+func (a *apiController) findOwnedShare(c *gin.Context, shareID string) (*domain.SharingInfo, bool) {
+	share, err := a.shareUsecase.FindIncludingExpired(shareID)
+	if err != nil || share == nil {
+		abortWithAPIError(c, http.StatusNotFound, apiErrorNotFound, "share not found")
+		return nil, false
+	}
+	user := secure.GetSessionUser(c)
+	note, err := a.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		note, err = a.noteUsecase.FindTrashed(share.NoteID)
+	}
+	if err != nil || note.AuthorID != user.ID {
+		abortWithAPIError(c, http.StatusNotFound, apiErrorNotFound, "share not found")
+		return nil, false
+	}
+	return share, true
+}
+
+func (a *apiController) getMe(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	c.JSON(http.StatusOK, gin.H{"data": apiUser{ID: user.ID, Email: user.Email}})
+}
+
+// getNotes はエディターのメモ一覧と同じ並び順とカーソルでメモを返す
+func (a *apiController) getNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	limit, ok := parseLimit(c)
+	if !ok {
+		return
+	}
+
+	page, err := a.noteUsecase.ListNotes(user.ID, usecase.NoteListOptions{
+		TagID:    c.Query("tag"),
+		FolderID: c.Query("folder"),
+		Favorite: c.Query("favorite") == "1",
+		Sort:     c.Query("sort"),
+		Cursor:   c.Query("cursor"),
+		Limit:    limit,
+	})
+	if errors.Is(err, usecase.ErrInvalidNoteSort) {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid sort")
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidNoteCursor) {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid cursor")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to list notes", "userId", user.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to list notes")
+		return
+	}
+
+	data := make([]apiNote, 0, len(page.Notes))
+	for _, note := range page.Notes {
+		data = append(data, apiNote{
+			ID:        note.ID,
+			Title:     note.Title,
+			Format:    note.Format,
+			FolderID:  note.FolderID,
+			Version:   note.Version,
+			Pinned:    note.Pinned,
+			Favorite:  note.Favorite,
+			CreatedAt: note.CreatedAt,
+			UpdatedAt: note.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "pagination": apiCursorPagination{Limit: limit, NextCursor: page.NextCursor}})
+}
+
+func (a *apiController) getSearchNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	query, valid := secure.ValidateTextInput(c.Query("q"), 200)
+	if !valid || query == "" {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "q is required and must be at most 200 characters")
+		return
+	}
+	page, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	results, err := a.noteUsecase.Search(user.ID, query, page.Limit, page.Offset)
+	if err != nil {
+		slog.Error("failed to search notes", "query", query, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to search notes")
+		return
+	}
+
+	data := make([]apiNoteSearchResult, 0, len(results))
+	for _, result := range results {
+		data = append(data, apiNoteSearchResult{
+			ID:          result.ID,
+			Title:       result.Title,
+			SnippetHTML: result.SnippetHTML,
+			UpdatedAt:   result.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data, "pagination": page})
+}
+
+func (a *apiController) postNote(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	var req apiCreateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid request body")
+		return
+	}
+	if req.Format != nil && *req.Format != usecase.NoteFormatPlain && *req.Format != usecase.NoteFormatMarkdown {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "format must be plain or markdown")
+		return
+	}
+
+	// メモを作成する前に入力を検証する
+	var validated domain.Note
+	if !applyNoteFields(c, &validated, req.Title, req.Content) {
+		return
+	}
+
+	// タイトル・本文・書式はメモの作成と同時に保存する
+	fields := usecase.NewNoteFields{Format: req.Format}
+	if req.Title != nil {
+		fields.Title = &validated.Title
+	}
+	if req.Content != nil {
+		fields.Content = &validated.Content
+	}
+	note, err := a.noteUsecase.CreateNoteWithFields(user, fields, nil)
+	if err != nil {
+		slog.Error("failed to create note", "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to create note")
+		return
+	}
+
+	created, err := a.noteUsecase.Find(note.ID)
+	if err != nil {
+		slog.Error("failed to get created note", "noteId", note.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to create note")
+		return
+	}
+	c.Header("Location", "/api/v1/notes/"+created.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": newAPINote(created, true)})
+}
+
+// applyNoteFields は指定されたタイトルと本文を検証して note に設定する。不正な値の場合は 400 を返して false を返す
+func applyNoteFields(c *gin.Context, note *domain.Note, title *string, content *string) bool {
+	if title != nil {
+		validated, valid := secure.ValidateTextInput(*title, maxNoteTitleLength)
+		if !valid {
+			abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "title is invalid or longer than 500 characters")
+			return false
+		}
+		note.Title = validated
+	}
+	if content != nil {
+		validated, valid := secure.ValidateTextInput(*content, maxNoteContentLength)
+		if !valid {
+			abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "content is invalid or longer than 1000000 characters")
+			return false
+		}
+		note.Content = validated
+	}
+	return true
+}
+
+func (a *apiController) getNote(c *gin.Context) {
+	note, ok := a.findOwnedNote(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": newAPINote(note, true)})
+}
+
+func (a *apiController) patchNote(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	note, ok := a.findOwnedNote(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req apiUpdateNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid request body")
+		return
+	}
+	if req.Version == nil {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "version is required")
+		return
+	}
+	if req.Format != nil && *req.Format != usecase.NoteFormatPlain && *req.Format != usecase.NoteFormatMarkdown {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "format must be plain or markdown")
+		return
+	}
+	if *req.Version != note.Version {
+		a.abortWithNoteConflict(c, note)
+		return
+	}
+
+	if req.Title != nil || req.Content != nil {
+		if !applyNoteFields(c, note, req.Title, req.Content) {
+			return
+		}
+		// 書式も指定された場合は、タイトルや本文と同じ更新で変更する
+		format := ""
+		if req.Format != nil {
+			format = *req.Format
+		}
+		updated, err := a.noteUsecase.UpdateNoteWithFormat(note, format, userEditor(user))
+		if errors.Is(err, usecase.ErrNoteConflict) {
+			if current, findErr := a.noteUsecase.Find(note.ID); findErr == nil {
+				a.abortWithNoteConflict(c, current)
+				return
+			}
+		}
+		if err != nil {
+			slog.Error("failed to update note", "noteId", note.ID, "error", err)
+			abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to update note")
+			return
+		}
+		note = updated
+	} else if req.Format != nil && *req.Format != note.Format {
+		if err := a.noteUsecase.SetFormat(note, *req.Format); err != nil {
+			slog.Error("failed to set note format", "noteId", note.ID, "error", err)
+			abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to update note")
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": newAPINote(note, true)})
+}
+
+// abortWithNoteConflict は 409 とともに保存されている最新のメモを details に返す
+func (a *apiController) abortWithNoteConflict(c *gin.Context, current *domain.Note) {
+	c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": apiError{
+		Code:    apiErrorConflict,
+		Message: "note was updated by someone else",
+		Details: gin.H{"note": newAPINote(current, true)},
+	}})
+}
+
+func (a *apiController) deleteNote(c *gin.Context) {
+	note, ok := a.findOwnedNote(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := a.noteUsecase.Delete(note); err != nil {
+		slog.Error("failed to delete note", "noteId", note.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to delete note")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (a *apiController) getNoteShares(c *gin.Context) {
+	note, ok := a.findOwnedNote(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	shares, err := a.shareUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get shares", "noteId", note.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to list shares")
+		return
+	}
+	data := make([]apiShare, 0, len(shares))
+	for _, share := range shares {
+		data = append(data, newAPIShare(share))
+	}
+	c.JSON(http.StatusOK, gin.H{"data": data})
+}
+
+func (a *apiController) postNoteShare(c *gin.Context) {
+	note, ok := a.findOwnedNote(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	var req apiCreateShareRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid request body")
+		return
+	}
+	options, err := req.options(req.Editable)
+	if err != nil {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid share limits")
+		return
+	}
+	options.Password = req.Password
+	share, err := a.shareUsecase.ShareNote(note, options)
+	if errors.Is(err, usecase.ErrInvalidShareOptions) {
+		abortWithAPIError(c, http.StatusBadRequest, apiErrorBadRequest, "invalid share limits or password")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to share note", "noteId", note.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to share note")
+		return
+	}
+	c.Header("Location", "/api/v1/shares/"+share.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": newAPIShare(share)})
+}
+
+func (a *apiController) getShare(c *gin.Context) {
+	share, ok := a.findOwnedShare(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": newAPIShare(share)})
+}
+
+func (a *apiController) deleteShare(c *gin.Context) {
+	share, ok := a.findOwnedShare(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := a.shareUsecase.Delete(share); err != nil {
+		slog.Error("failed to delete share", "shareId", share.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to delete share")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// getExport はすべてのメモを Markdown の ZIP として返す。
+// 共有リンクはアクセストークンに shares:manage がある場合のみ含める
+func (a *apiController) getExport(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	archive, err := a.exportUsecase.Prepare(user, usecase.ExportOptions{
+		ShareBaseURL:  requestOrigin(c),
+		IncludeShares: secure.HasScope(c, usecase.ScopeSharesManage),
+	})
+	if err != nil {
+		slog.Error("failed to prepare export", "userId", user.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to export notes")
+		return
+	}
+	if err := writeNoteArchive(c, archive); err != nil {
+		slog.Error("failed to write export", "userId", user.ID, "error", err)
+	}
+}
+
+// postImport は ZIP または ENEX からメモを取り込み、ファイルごとの結果を返す
+func (a *apiController) postImport(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	files, err := readImportUpload(c, a.importUsecase)
+	if err != nil {
+		switch status := importUploadErrorStatus(err); status {
+		case http.StatusInternalServerError:
+			slog.Error("failed to read import", "userId", user.ID, "error", err)
+			abortWithAPIError(c, status, apiErrorInternal, "failed to import notes")
+		case http.StatusRequestEntityTooLarge:
+			abortWithAPIError(c, status, apiErrorTooLarge, err.Error())
+		case http.StatusUnsupportedMediaType:
+			abortWithAPIError(c, status, apiErrorUnsupportedMediaType, err.Error())
+		default:
+			abortWithAPIError(c, status, apiErrorBadRequest, err.Error())
+		}
+		return
+	}
+	if err := a.importUsecase.Import(user, files); err != nil {
+		slog.Error("failed to import notes", "userId", user.ID, "error", err)
+		abortWithAPIError(c, http.StatusInternalServerError, apiErrorInternal, "failed to import notes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": newImportSummary(files)})
+}
@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// multipart のヘッダーなどのためにファイルサイズの上限に加える余裕
+const multipartOverhead = 1 << 20
+
+type IAttachmentController interface {
+	getAttachments(c *gin.Context)
+	postUploadAttachment(c *gin.Context)
+	getAttachment(c *gin.Context)
+	deleteAttachment(c *gin.Context)
+	getSharedAttachment(c *gin.Context)
+}
+
+type attachmentController struct {
+	attachmentUsecase usecase.IAttachmentUsecase
+	noteUsecase       usecase.INoteUsecase
+	shareUsecase      usecase.IShareUsecase
+	memberUsecase     usecase.INoteMemberUsecase
+}
+
+// attachmentItem は添付ファイル一覧表示用のビューモデル
+type attachmentItem struct {
+	ID       string
+	FileName string
+	Size     string
+	IsImage  bool
+}
+
+func newAttachmentItems(attachments []*domain.Attachment) []attachmentItem {
+	items := make([]attachmentItem, 0, len(attachments))
+	for _, attachment := range attachments {
+		items = append(items, attachmentItem{
+			ID:       attachment.ID,
+			FileName: attachment.FileName,
+			Size:     formatFileSize(attachment.Size),
+			IsImage:  strings.HasPrefix(attachment.ContentType, "image/"),
+		})
+	}
+	return items
+}
+
+func NewAttachmentController(router *gin.Engine) IAttachmentController {
+	instance := &attachmentController{
+		attachmentUsecase: usecase.NewAttachmentUsecase(),
+		noteUsecase:       usecase.NewNoteUsecase(),
+		shareUsecase:      usecase.NewShareUsecase(),
+		memberUsecase:     usecase.NewNoteMemberUsecase(),
+	}
+	setupAttachmentRoute(instance, router)
+	return instance
+}
+
+func setupAttachmentRoute(api IAttachmentController, router *gin.Engine) {
+	attachmentGroup := router.Group("/note/:id/attachments")
+	attachmentGroup.Use(secure.AuthMiddleware())
+	{
+		attachmentGroup.GET("", api.getAttachments)
+		attachmentGroup.POST("", api.postUploadAttachment)
+		attachmentGroup.GET("/:attachmentId", api.getAttachment)
+		attachmentGroup.DELETE("/:attachmentId", api.deleteAttachment)
+	}
+
+	router.GET("/share/:id/attachments/:attachmentId", api.getSharedAttachment)
+}
+
+// findOwnedNote はログインユーザーが所有するメモを取得する
+func (a *attachmentController) findOwnedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := a.noteUsecase.Find(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+// findReadableNote はログインユーザーが所有するか、共有されたメモを取得する
+func (a *attachmentController) findReadableNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := a.noteUsecase.Find(c.Param("id"))
+	if err != nil {
+		return nil, false
+	}
+	role, err := a.memberUsecase.NoteRole(note, user)
+	if err != nil || role == "" {
+		return nil, false
+	}
+	return note, true
+}
+
+// sendAttachment は添付ファイルを返す。画像と PDF はブラウザで表示し、それ以外はダウンロードさせる
+func (a *attachmentController) sendAttachment(c *gin.Context, attachment *domain.Attachment) {
+	reader, err := a.attachmentUsecase.Open(attachment)
+	if err != nil {
+		slog.Error("failed to open attachment", "attachmentId", attachment.ID, "error", err)
+		showNotFoundPage(c)
+		return
+	}
+	defer reader.Close()
+
+	disposition := "attachment"
+	if strings.HasPrefix(attachment.ContentType, "image/") || attachment.ContentType == "application/pdf" {
+		disposition = "inline"
+	}
+	c.DataFromReader(http.StatusOK, int64(attachment.Size), attachment.ContentType, reader, map[string]string{
+		"Content-Disposition": mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}),
+		"Cache-Control":       "private, max-age=86400",
+	})
+}
+
+func (a *attachmentController) getAttachments(c *gin.Context) {
+	note, ok := a.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	attachments, err := a.attachmentUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get attachments", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get attachments"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+func (a *attachmentController) postUploadAttachment(c *gin.Context) {
+	note, ok := a.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	maxBytes := env.Get().AttachmentMaxBytes
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	if fileHeader.Size > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		slog.Error("failed to open uploaded file", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := a.attachmentUsecase.Upload(note, fileHeader.Filename, file)
+	switch {
+	case errors.Is(err, usecase.ErrAttachmentTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large"})
+		return
+	case errors.Is(err, usecase.ErrAttachmentTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "file type not allowed"})
+		return
+	case err != nil:
+		slog.Error("failed to upload attachment", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to upload attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "attachment": attachment})
+}
+
+// getAttachment はオーナーのほか、メモを共有されたユーザーにも添付ファイルを返す
+func (a *attachmentController) getAttachment(c *gin.Context) {
+	note, ok := a.findReadableNote(c)
+	if !ok {
+		showNotFoundPage(c)
+		return
+	}
+
+	attachment, err := a.attachmentUsecase.Find(note, c.Param("attachmentId"))
+	if err != nil {
+		showNotFoundPage(c)
+		return
+	}
+	a.sendAttachment(c, attachment)
+}
+
+func (a *attachmentController) deleteAttachment(c *gin.Context) {
+	note, ok := a.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	attachment, err := a.attachmentUsecase.Find(note, c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+		return
+	}
+
+	if err := a.attachmentUsecase.Delete(attachment); err != nil {
+		slog.Error("failed to delete attachment", "attachmentId", attachment.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete attachment"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// getSharedAttachment は共有リンク経由で、そのメモの添付ファイルだけを返す
+func (a *attachmentController) getSharedAttachment(c *gin.Context) {
+	share, err := sharePageFinder(c, a.shareUsecase)(c.Param("id"))
+	if errors.Is(err, usecase.ErrShareExpired) {
+		showExpiredPage(c)
+		return
+	}
+	if err != nil || share == nil {
+		showNotFoundPage(c)
+		return
+	}
+	if !shareUnlocked(c, share) {
+		showShareUnlockPage(c, http.StatusUnauthorized, share, "")
+		return
+	}
+
+	note, err := a.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		showGonePage(c)
+		return
+	}
+	if err != nil {
+		showNotFoundPage(c)
+		return
+	}
+
+	attachment, err := a.attachmentUsecase.Find(note, c.Param("attachmentId"))
+	if err != nil {
+		showNotFoundPage(c)
+		return
+	}
+	a.sendAttachment(c, attachment)
+}
+
+// formatFileSize は添付ファイルのサイズを表示用に整形する
+func formatFileSize(size int32) string {
+	switch {
+	case size >= 1<<20:
+		return strconv.FormatFloat(float64(size)/(1<<20), 'f', 1, 64) + " MB"
+	case size >= 1<<10:
+		return strconv.FormatFloat(float64(size)/(1<<10), 'f', 1, 64) + " KB"
+	default:
+		return strconv.Itoa(int(size)) + " B"
+	}
+}
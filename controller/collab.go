@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	collabWriteWait  = 10 * time.Second
+	collabPongWait   = 60 * time.Second
+	collabPingPeriod = collabPongWait * 9 / 10
+	// 本文の上限 1MB の挿入を含む操作を受け取れる大きさにする
+	collabMaxMessageSize = 4 << 20
+)
+
+// クライアント ID は UUID などの英数字とハイフンのみ許可する
+var collabClientIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]{8,64}$`)
+
+// 同一オリジン以外からの接続は Upgrader の既定の Origin チェックで拒否される
+var collabUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+}
+
+type ICollabController interface {
+	getNoteSocket(c *gin.Context)
+	getSharedNoteSocket(c *gin.Context)
+}
+
+type collabController struct {
+	collabUsecase usecase.ICollabUsecase
+	noteUsecase   usecase.INoteUsecase
+	shareUsecase  usecase.IShareUsecase
+	memberUsecase usecase.INoteMemberUsecase
+}
+
+// collabRequest はクライアントから届くメッセージ
+type collabRequest struct {
+	Type  string         `json:"type"`
+	Rev   int            `json:"rev"`
+	Seq   int            `json:"seq"`
+	Op    usecase.TextOp `json:"op"`
+	Title string         `json:"title"`
+}
+
+func NewCollabController(router *gin.Engine) ICollabController {
+	instance := &collabController{
+		collabUsecase: usecase.NewCollabUsecase(),
+		noteUsecase:   usecase.NewNoteUsecase(),
+		shareUsecase:  usecase.NewShareUsecase(),
+		memberUsecase: usecase.NewNoteMemberUsecase(),
+	}
+	setupCollabRoute(instance, router)
+	return instance
+}
+
+func setupCollabRoute(api ICollabController, router *gin.Engine) {
+	router.GET("/note/:id/ws", secure.AuthMiddleware(), api.getNoteSocket)
+	router.GET("/share/:id/ws", api.getSharedNoteSocket)
+}
+
+func (co *collabController) getNoteSocket(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	note, err := co.noteUsecase.Find(c.Param("id"))
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		c.JSON(http.StatusGone, gin.H{"error": "note is in the trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+	// オーナーと編集者として共有されたユーザーが参加できる
+	role, err := co.memberUsecase.NoteRole(note, user)
+	if err != nil || !usecase.CanEditNote(role) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+	label := "オーナー"
+	if role != usecase.NoteRoleOwner {
+		label = user.Email
+	}
+	// 共有が解除されたり閲覧のみに変わったりしたら接続を切る
+	access := func() bool {
+		role, err := co.memberUsecase.NoteRole(note, user)
+		return err == nil && usecase.CanEditNote(role)
+	}
+	co.serveSocket(c, note, label, userEditor(user), access, nil)
+}
+
+// getSharedNoteSocket は編集可能な共有リンクにのみ共同編集を許可する
+func (co *collabController) getSharedNoteSocket(c *gin.Context) {
+	findShare := sharePageFinder(c, co.shareUsecase)
+	share, err := findShare(c.Param("id"))
+	if errors.Is(err, usecase.ErrShareExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": "share link has expired"})
+		return
+	}
+	if err != nil || share == nil || !share.Editable {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	if !shareUnlocked(c, share) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "password required"})
+		return
+	}
+	note, err := co.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		c.JSON(http.StatusGone, gin.H{"error": "note is in the trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+	// 接続中の編集者には表示名を見せる。未入力なら「共有リンクの編集者」と表示する
+	editor := shareEditor(c, share)
+	label := editor.DisplayName
+	if label == "" {
+		label = "共有リンクの編集者"
+	}
+	// 共有リンクが削除されたり、パスワードが変わったりしたら接続を切る
+	unlockKey := usecase.ShareUnlockKey(share)
+	access := func() bool {
+		current, err := findShare(share.ID)
+		return err == nil && current.Editable && usecase.ShareUnlockKey(current) == unlockKey
+	}
+	co.serveSocket(c, note, label, editor, access, shareSocketDeadline(share))
+}
+
+// shareSocketDeadline は共有リンクの期限切れか閲覧のみへの切り替えのうち早い方の日時を返す
+func shareSocketDeadline(share *domain.SharingInfo) *time.Time {
+	deadline := share.ExpiresAt
+	if share.EditableUntil != nil && (deadline == nil || share.EditableUntil.Before(*deadline)) {
+		deadline = share.EditableUntil
+	}
+	return deadline
+}
+
+// serveSocket は WebSocket に切り替えてセッションに参加し、切断されるまでメッセージを中継する。
+// access が false を返すようになるか、deadline があればその日時に接続を切る
+func (co *collabController) serveSocket(c *gin.Context, note *domain.Note, label string, editor usecase.NoteEditor, access usecase.CollabAccess, deadline *time.Time) {
+	clientID := c.Query("client")
+	if !collabClientIDPattern.MatchString(clientID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid client id"})
+		return
+	}
+	resume := usecase.CollabResume{Epoch: c.Query("epoch")}
+	if rev, err := strconv.Atoi(c.Query("rev")); err == nil {
+		resume.Rev = rev
+	}
+
+	conn, err := collabUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade が既にエラーレスポンスを返している
+		slog.Warn("failed to upgrade collab connection", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	client, err := co.collabUsecase.Join(note, clientID, label, editor, resume, access)
+	if err != nil {
+		closeMessage := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, err.Error())
+		conn.WriteControl(websocket.CloseMessage, closeMessage, time.Now().Add(collabWriteWait))
+		return
+	}
+	defer client.Leave()
+
+	if deadline != nil {
+		// 接続を閉じると readCollabMessages が戻り、セッションから抜ける
+		timer := time.AfterFunc(time.Until(*deadline), func() { conn.Close() })
+		defer timer.Stop()
+	}
+
+	go writeCollabMessages(conn, client)
+	readCollabMessages(conn, client)
+}
+
+// readCollabMessages はクライアントからのメッセージをセッションに渡す。接続が切れると戻る
+func readCollabMessages(conn *websocket.Conn, client *usecase.CollabClient) {
+	conn.SetReadLimit(collabMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(collabPongWait))
+	})
+
+	for {
+		var req collabRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Warn("collab connection closed unexpectedly", "clientId", client.ID(), "error", err)
+			}
+			return
+		}
+
+		switch req.Type {
+		case "op":
+			if err := client.SubmitOp(req.Rev, req.Seq, req.Op); err != nil {
+				slog.Warn("rejected collab operation", "clientId", client.ID(), "error", err)
+			}
+		case "title":
+			title, valid := secure.ValidateTextInput(req.Title, maxNoteTitleLength)
+			if valid {
+				client.SetTitle(title)
+			}
+		case "save":
+			client.Save()
+		}
+	}
+}
+
+// writeCollabMessages はセッションからのメッセージを送信し、定期的に ping を送る。
+// Outbox が閉じられると接続を閉じる
+func writeCollabMessages(conn *websocket.Conn, client *usecase.CollabClient) {
+	ticker := time.NewTicker(collabPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-client.Outbox():
+			conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(collabWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package controller
+
+import (
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// requestOrigin はリクエストされたスキームとホスト（例: https://example.com）を返す
+func requestOrigin(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+// writeNoteArchive はメモの ZIP をメモリに溜めずにそのままレスポンスに書き出す。
+// 書き出しの途中で失敗した場合はステータスを変更できないため、不完全な ZIP になる
+func writeNoteArchive(c *gin.Context, archive *usecase.NoteArchive) error {
+	filename := "elib-export-" + time.Now().Format("20060102") + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	return archive.WriteZip(c.Writer)
+}
@@ -0,0 +1,290 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type IFolderController interface {
+	getFolders(c *gin.Context)
+	postCreateFolder(c *gin.Context)
+	putRenameFolder(c *gin.Context)
+	putMoveFolder(c *gin.Context)
+	deleteFolder(c *gin.Context)
+	putNoteFolder(c *gin.Context)
+}
+
+type folderController struct {
+	folderUsecase usecase.IFolderUsecase
+	noteUsecase   usecase.INoteUsecase
+}
+
+type createFolderRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parentId"`
+}
+
+type renameFolderRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// moveFolderRequest の ParentID が空の場合は最上位に移動する
+type moveFolderRequest struct {
+	ParentID string `json:"parentId"`
+}
+
+// noteFolderRequest の FolderID が空の場合はフォルダから外す
+type noteFolderRequest struct {
+	FolderID string `json:"folderId"`
+}
+
+// folderTreeItem はサイドバーのフォルダツリー表示用のビューモデル
+type folderTreeItem struct {
+	ID     string
+	Name   string
+	Depth  int
+	Indent string
+	Active bool
+}
+
+func NewFolderController(router *gin.Engine) IFolderController {
+	instance := &folderController{
+		folderUsecase: usecase.NewFolderUsecase(),
+		noteUsecase:   usecase.NewNoteUsecase(),
+	}
+	setupFolderRoute(instance, router)
+	return instance
+}
+
+func setupFolderRoute(api IFolderController, router *gin.Engine) {
+	folderGroup := router.Group("/folder")
+	folderGroup.Use(secure.AuthMiddleware())
+	{
+		folderGroup.GET("", api.getFolders)
+		folderGroup.POST("", api.postCreateFolder)
+		folderGroup.PUT("/:id", api.putRenameFolder)
+		folderGroup.PUT("/:id/move", api.putMoveFolder)
+		folderGroup.DELETE("/:id", api.deleteFolder)
+	}
+
+	noteFolderGroup := router.Group("/note/:id/folder")
+	noteFolderGroup.Use(secure.AuthMiddleware())
+	{
+		noteFolderGroup.PUT("", api.putNoteFolder)
+	}
+}
+
+// newFolderTree はフォルダを深さ優先の順に並べ、階層の深さを付与する
+func newFolderTree(folders []*domain.Folder, activeFolderID string) []folderTreeItem {
+	known := make(map[string]bool, len(folders))
+	for _, folder := range folders {
+		known[folder.ID] = true
+	}
+	children := make(map[string][]*domain.Folder)
+	for _, folder := range folders {
+		parentID := ""
+		if folder.ParentID != nil && known[*folder.ParentID] {
+			parentID = *folder.ParentID
+		}
+		children[parentID] = append(children[parentID], folder)
+	}
+
+	items := make([]folderTreeItem, 0, len(folders))
+	var walk func(parentID string, depth int)
+	walk = func(parentID string, depth int) {
+		for _, folder := range children[parentID] {
+			items = append(items, folderTreeItem{
+				ID:     folder.ID,
+				Name:   folder.Name,
+				Depth:  depth,
+				Indent: strings.Repeat("　", depth),
+				Active: folder.ID == activeFolderID,
+			})
+			walk(folder.ID, depth+1)
+		}
+	}
+	walk("", 0)
+	return items
+}
+
+// findOwnedFolder はログインユーザーが所有するフォルダを取得する
+func (f *folderController) findOwnedFolder(c *gin.Context, folderId string) (*domain.Folder, bool) {
+	user := secure.GetSessionUser(c)
+	folder, err := f.folderUsecase.Find(folderId)
+	if err != nil || folder.UserID != user.ID {
+		return nil, false
+	}
+	return folder, true
+}
+
+// findOptionalFolder は空の ID を最上位（nil）として扱う
+func (f *folderController) findOptionalFolder(c *gin.Context, folderId string) (*domain.Folder, bool) {
+	if folderId == "" {
+		return nil, true
+	}
+	return f.findOwnedFolder(c, folderId)
+}
+
+func folderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidFolderName):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrFolderCycle):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (f *folderController) getFolders(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	folders, err := f.folderUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get folders", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get folders"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"folders": folders})
+}
+
+func (f *folderController) postCreateFolder(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	var req createFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	name, valid := secure.ValidateTextInput(req.Name, 200)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder name"})
+		return
+	}
+	parent, ok := f.findOptionalFolder(c, req.ParentID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	folder, err := f.folderUsecase.Create(user, parent, name)
+	if err != nil {
+		slog.Error("failed to create folder", "error", err)
+		c.JSON(folderErrorStatus(err), gin.H{"error": "failed to create folder"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "folder": folder})
+}
+
+func (f *folderController) putRenameFolder(c *gin.Context) {
+	folder, ok := f.findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	var req renameFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	name, valid := secure.ValidateTextInput(req.Name, 200)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder name"})
+		return
+	}
+
+	renamed, err := f.folderUsecase.Rename(folder, name)
+	if err != nil {
+		slog.Error("failed to rename folder", "folderId", folder.ID, "error", err)
+		c.JSON(folderErrorStatus(err), gin.H{"error": "failed to rename folder"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "folder": renamed})
+}
+
+func (f *folderController) putMoveFolder(c *gin.Context) {
+	folder, ok := f.findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	var req moveFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	parent, ok := f.findOptionalFolder(c, req.ParentID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	if err := f.folderUsecase.Move(folder, parent); err != nil {
+		slog.Error("failed to move folder", "folderId", folder.ID, "parentId", req.ParentID, "error", err)
+		c.JSON(folderErrorStatus(err), gin.H{"error": "failed to move folder"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "folder": folder})
+}
+
+func (f *folderController) deleteFolder(c *gin.Context) {
+	folder, ok := f.findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	// mode=cascade なら中身ごと削除、省略時は親フォルダに移動する
+	var mode usecase.FolderDeleteMode
+	switch c.DefaultQuery("mode", "move") {
+	case "move":
+		mode = usecase.FolderDeleteMoveToParent
+	case "cascade":
+		mode = usecase.FolderDeleteCascade
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mode"})
+		return
+	}
+
+	if err := f.folderUsecase.Delete(folder, mode); err != nil {
+		slog.Error("failed to delete folder", "folderId", folder.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete folder"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (f *folderController) putNoteFolder(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	note, err := f.noteUsecase.Find(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	folder, ok := f.findOptionalFolder(c, req.FolderID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "folder not found"})
+		return
+	}
+
+	if err := f.noteUsecase.MoveToFolder(note, folder); err != nil {
+		slog.Error("failed to move note", "noteId", note.ID, "folderId", req.FolderID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move note"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -0,0 +1,122 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	errImportNoFile             = errors.New("file is required")
+	errImportUnsupportedArchive = errors.New("file must be a .zip or .enex archive")
+	errImportInvalidTitle       = errors.New("title is invalid or too long")
+	errImportInvalidContent     = errors.New("content is invalid or too long")
+)
+
+// importResult はインポートしたファイルごとの結果。status は imported か failed
+type importResult struct {
+	File   string `json:"file"`
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	NoteID string `json:"noteId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// importSummary はインポート全体の結果
+type importSummary struct {
+	Imported int            `json:"imported"`
+	Failed   int            `json:"failed"`
+	Results  []importResult `json:"results"`
+}
+
+// readImportUpload はアップロードされた ZIP または ENEX からメモを読み込み、
+// postSaveNote と同じ上限でタイトルと本文を検証する
+func readImportUpload(c *gin.Context, importUsecase usecase.IImportUsecase) ([]*usecase.ImportFile, error) {
+	maxBytes := env.Get().ImportMaxBytes
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes+multipartOverhead)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, usecase.ErrImportTooLarge
+		}
+		return nil, errImportNoFile
+	}
+	if fileHeader.Size > maxBytes {
+		return nil, usecase.ErrImportTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var files []*usecase.ImportFile
+	switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+	case ".zip":
+		files, err = importUsecase.ReadZip(file, fileHeader.Size)
+	case ".enex":
+		files, err = importUsecase.ReadENEX(file)
+	default:
+		return nil, errImportUnsupportedArchive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range files {
+		if f.Err != nil {
+			continue
+		}
+		title, valid := secure.ValidateTextInput(f.Title, maxNoteTitleLength)
+		if !valid {
+			f.Err = errImportInvalidTitle
+			continue
+		}
+		content, valid := secure.ValidateTextInput(f.Content, maxNoteContentLength)
+		if !valid {
+			f.Err = errImportInvalidContent
+			continue
+		}
+		f.Title = title
+		f.Content = content
+	}
+	return files, nil
+}
+
+// importUploadErrorStatus は readImportUpload のエラーに対応するステータスを返す
+func importUploadErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrImportTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, errImportUnsupportedArchive):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, errImportNoFile), errors.Is(err, usecase.ErrInvalidImportArchive):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func newImportSummary(files []*usecase.ImportFile) importSummary {
+	summary := importSummary{Results: make([]importResult, 0, len(files))}
+	for _, f := range files {
+		result := importResult{File: f.Name, Title: f.Title, NoteID: f.NoteID}
+		if f.Err != nil {
+			result.Status = "failed"
+			result.Error = f.Err.Error()
+			summary.Failed++
+		} else {
+			result.Status = "imported"
+			summary.Imported++
+		}
+		summary.Results = append(summary.Results, result)
+	}
+	return summary
+}
@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type IMemberController interface {
+	getNoteMembers(c *gin.Context)
+	postNoteMember(c *gin.Context)
+	putNoteMember(c *gin.Context)
+	deleteNoteMember(c *gin.Context)
+}
+
+type memberController struct {
+	memberUsecase usecase.INoteMemberUsecase
+	noteUsecase   usecase.INoteUsecase
+}
+
+// noteMemberRequest はメモをメールアドレスのユーザーと共有する。共有済みのユーザーなら権限を変更する
+type noteMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type noteRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// noteRoleLabels は権限の表示名
+var noteRoleLabels = map[string]string{
+	usecase.NoteRoleOwner:     "オーナー",
+	usecase.NoteRoleViewer:    "閲覧者",
+	usecase.NoteRoleCommenter: "コメント可",
+	usecase.NoteRoleEditor:    "編集者",
+}
+
+// sharedNoteItem はサイドバーの「共有されたメモ」表示用のビューモデル
+type sharedNoteItem struct {
+	*usecase.SharedNoteSummary
+	RoleLabel string
+	Active    bool
+}
+
+func newSharedNoteItems(notes []*usecase.SharedNoteSummary, activeNoteID string) []sharedNoteItem {
+	items := make([]sharedNoteItem, 0, len(notes))
+	for _, note := range notes {
+		items = append(items, sharedNoteItem{
+			SharedNoteSummary: note,
+			RoleLabel:         noteRoleLabels[note.Role],
+			Active:            note.ID == activeNoteID,
+		})
+	}
+	return items
+}
+
+// sharedNoteOwner は共有されたメモのオーナーのメールアドレスを返す。自分のメモなら空
+func sharedNoteOwner(notes []*usecase.SharedNoteSummary, noteID string) string {
+	for _, note := range notes {
+		if note.ID == noteID {
+			return note.OwnerEmail
+		}
+	}
+	return ""
+}
+
+func NewMemberController(router *gin.Engine) IMemberController {
+	instance := &memberController{
+		memberUsecase: usecase.NewNoteMemberUsecase(),
+		noteUsecase:   usecase.NewNoteUsecase(),
+	}
+	setupMemberRoute(instance, router)
+	return instance
+}
+
+func setupMemberRoute(api IMemberController, router *gin.Engine) {
+	memberGroup := router.Group("/note/:id/members")
+	memberGroup.Use(secure.AuthMiddleware())
+	{
+		memberGroup.GET("", api.getNoteMembers)
+		memberGroup.POST("", api.postNoteMember)
+		memberGroup.PUT("/:userId", api.putNoteMember)
+		memberGroup.DELETE("/:userId", api.deleteNoteMember)
+	}
+}
+
+// findOwnedNote はログインユーザーが所有するメモを取得する。共有を管理できるのはオーナーのみ
+func (m *memberController) findOwnedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := m.noteUsecase.Find(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+func memberErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidNoteRole), errors.Is(err, usecase.ErrInvalidNoteMember):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrMemberUserNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (m *memberController) getNoteMembers(c *gin.Context) {
+	note, ok := m.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	members, err := m.memberUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get note members", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get members"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (m *memberController) postNoteMember(c *gin.Context) {
+	note, ok := m.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	member, err := m.memberUsecase.Share(note, req.Email, req.Role)
+	if err != nil {
+		status := memberErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.Error("failed to share note with user", "noteId", note.ID, "error", err)
+			c.JSON(status, gin.H{"error": "failed to share note"})
+			return
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "member": member})
+}
+
+func (m *memberController) putNoteMember(c *gin.Context) {
+	note, ok := m.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := m.memberUsecase.SetRole(note, c.Param("userId"), req.Role); err != nil {
+		status := memberErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.Error("failed to change member role", "noteId", note.ID, "error", err)
+		}
+		c.JSON(status, gin.H{"error": "failed to change role"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "role": req.Role})
+}
+
+func (m *memberController) deleteNoteMember(c *gin.Context) {
+	note, ok := m.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	if err := m.memberUsecase.Remove(note, c.Param("userId")); err != nil {
+		status := memberErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.Error("failed to remove member", "noteId", note.ID, "error", err)
+		}
+		c.JSON(status, gin.H{"error": "failed to remove member"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
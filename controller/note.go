@@ -1,13 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/ToshihiroOgino/elib/domain"
 	"github.com/ToshihiroOgino/elib/secure"
 	"github.com/ToshihiroOgino/elib/usecase"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type INoteController interface {
@@ -16,23 +20,93 @@ type INoteController interface {
 	getCreateNewNote(c *gin.Context)
 	postSaveNote(c *gin.Context)
 	deleteNote(c *gin.Context)
+	getSearchNotes(c *gin.Context)
+	getListNotes(c *gin.Context)
+	getExportNotes(c *gin.Context)
+	postImportNotes(c *gin.Context)
+	putNoteFormat(c *gin.Context)
+	putNotePin(c *gin.Context)
+	putNoteFavorite(c *gin.Context)
+	putNoteOrder(c *gin.Context)
+	getNoteLinks(c *gin.Context)
+	getBrokenLinks(c *gin.Context)
 }
 
 type noteController struct {
-	noteUsecase  usecase.INoteUsecase
-	shareUsecase usecase.IShareUsecase
+	noteUsecase       usecase.INoteUsecase
+	shareUsecase      usecase.IShareUsecase
+	tagUsecase        usecase.ITagUsecase
+	folderUsecase     usecase.IFolderUsecase
+	attachmentUsecase usecase.IAttachmentUsecase
+	exportUsecase     usecase.IExportUsecase
+	importUsecase     usecase.IImportUsecase
+	templateUsecase   usecase.ITemplateUsecase
+	memberUsecase     usecase.INoteMemberUsecase
+	accessUsecase     usecase.IShareAccessUsecase
+	revisionUsecase   usecase.IRevisionUsecase
 }
 
+// saveNoteRequest は本文を content の全文か、version の本文に対する差分 patch で送る。
+// patch がある場合 content は使わない
 type saveNoteRequest struct {
-	ID      string `json:"id"`
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	ID      string          `json:"id"`
+	Title   string          `json:"title"`
+	Content string          `json:"content"`
+	Patch   *usecase.TextOp `json:"patch"`
+	Version int32           `json:"version" binding:"required"`
+}
+
+// メモのタイトルと本文の上限（バイト数）
+const (
+	maxNoteTitleLength   = 500
+	maxNoteContentLength = 1000000
+)
+
+type noteFormatRequest struct {
+	Format string `json:"format" binding:"required"`
+}
+
+type notePinRequest struct {
+	Pinned *bool `json:"pinned" binding:"required"`
+}
+
+type noteFavoriteRequest struct {
+	Favorite *bool `json:"favorite" binding:"required"`
+}
+
+// noteOrderRequest はメモを afterId（上）と beforeId（下）のメモの間に移動する。
+// 一覧の先頭や末尾ではどちらかを省略する
+type noteOrderRequest struct {
+	AfterID  string `json:"afterId"`
+	BeforeID string `json:"beforeId"`
+}
+
+// noteConflictResponse は保存が競合した際に返すサーバー側の最新内容
+func noteConflictResponse(note *domain.Note) gin.H {
+	return gin.H{
+		"error": "conflict",
+		"note": gin.H{
+			"title":      note.Title,
+			"content":    note.Content,
+			"version":    note.Version,
+			"updated_at": note.UpdatedAt,
+		},
+	}
 }
 
 func NewNoteController(router *gin.Engine) INoteController {
 	instance := &noteController{
-		noteUsecase:  usecase.NewNoteUsecase(),
-		shareUsecase: usecase.NewShareUsecase(),
+		noteUsecase:       usecase.NewNoteUsecase(),
+		shareUsecase:      usecase.NewShareUsecase(),
+		tagUsecase:        usecase.NewTagUsecase(),
+		folderUsecase:     usecase.NewFolderUsecase(),
+		attachmentUsecase: usecase.NewAttachmentUsecase(),
+		exportUsecase:     usecase.NewExportUsecase(),
+		importUsecase:     usecase.NewImportUsecase(),
+		templateUsecase:   usecase.NewTemplateUsecase(),
+		memberUsecase:     usecase.NewNoteMemberUsecase(),
+		accessUsecase:     usecase.NewShareAccessUsecase(),
+		revisionUsecase:   usecase.NewRevisionUsecase(),
 	}
 	setupNoteRoute(instance, router)
 	return instance
@@ -45,49 +119,213 @@ func setupNoteRoute(api INoteController, router *gin.Engine) {
 		noteGroup.GET("", api.getNote)
 		noteGroup.GET("/:id", api.getNoteById)
 		noteGroup.GET("/new", api.getCreateNewNote)
+		noteGroup.GET("/search", api.getSearchNotes)
+		noteGroup.GET("/list", api.getListNotes)
+		noteGroup.GET("/export", api.getExportNotes)
+		noteGroup.POST("/import", api.postImportNotes)
 		noteGroup.POST("/save", api.postSaveNote)
 		noteGroup.DELETE("/delete/:id", api.deleteNote)
+		noteGroup.PUT("/:id/format", api.putNoteFormat)
+		noteGroup.PUT("/:id/pin", api.putNotePin)
+		noteGroup.PUT("/:id/favorite", api.putNoteFavorite)
+		noteGroup.PUT("/:id/order", api.putNoteOrder)
+		noteGroup.GET("/:id/links", api.getNoteLinks)
+		noteGroup.GET("/links/broken", api.getBrokenLinks)
 	}
 }
 
-func (n *noteController) getNote(c *gin.Context) {
-	user := secure.GetSessionUser(c)
+// noteListOptions はクエリからメモ一覧の条件を作る
+// （?tag= でタグ、?folder= でフォルダ直下、?favorite=1 でお気に入りのメモに絞り込み、?sort= で並び順を指定する）
+func noteListOptions(c *gin.Context) usecase.NoteListOptions {
+	return usecase.NoteListOptions{
+		TagID:    c.Query("tag"),
+		FolderID: c.Query("folder"),
+		Favorite: c.Query("favorite") == "1",
+		Sort:     c.Query("sort"),
+		Cursor:   c.Query("cursor"),
+	}
+}
 
-	// ユーザーの全メモを取得
-	notes, err := n.noteUsecase.FindNotesByUserID(user.ID)
+// findListNotes はサイドバーに表示するメモ一覧の最初のページを取得する
+func (n *noteController) findListNotes(c *gin.Context, user *domain.User) *usecase.NoteListPage {
+	opts := noteListOptions(c)
+	opts.Cursor = ""
+	page, err := n.noteUsecase.ListNotes(user.ID, opts)
+	if errors.Is(err, usecase.ErrInvalidNoteSort) {
+		// 不明な並び順は更新日時順として扱う
+		opts.Sort = ""
+		page, err = n.noteUsecase.ListNotes(user.ID, opts)
+	}
 	if err != nil {
 		slog.Error("failed to get notes", "error", err)
-		notes = []*domain.Note{}
+		page = &usecase.NoteListPage{Notes: []*usecase.NoteSummary{}}
+	}
+	return page
+}
+
+// findLatestNote は最近更新したメモを取得する
+func (n *noteController) findLatestNote(user *domain.User, opts usecase.NoteListOptions) (*domain.Note, error) {
+	opts.Sort = usecase.NoteSortUpdated
+	opts.Cursor = ""
+	opts.Limit = 1
+	page, err := n.noteUsecase.ListNotes(user.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(page.Notes) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return n.noteUsecase.Find(page.Notes[0].ID)
+}
+
+// renderEditor はメモエディター画面を描画する。role はログインユーザーのメモに対する権限で、
+// 共有されたメモではオーナー向けの操作（共有・タグ・リンクなど）を表示しない
+func (n *noteController) renderEditor(c *gin.Context, user *domain.User, note *domain.Note, notes *usecase.NoteListPage, role string) {
+	isOwner := role == usecase.NoteRoleOwner
+	shares := []*domain.SharingInfo{}
+	accessStats := map[string]*usecase.ShareAccessStats{}
+	members := []*usecase.NoteMemberItem{}
+	noteTags := []*domain.Tag{}
+	links := []*usecase.NoteLink{}
+	backlinks := []*usecase.Backlink{}
+	lastEditor := ""
+	var err error
+	if isOwner {
+		if shares, err = n.shareUsecase.FindByNote(note); err != nil {
+			shares = []*domain.SharingInfo{}
+			slog.Error("failed to get share info for note", "noteId", note.ID, "error", err)
+		}
+		if accessStats, err = n.accessUsecase.StatsByShares(shares); err != nil {
+			accessStats = map[string]*usecase.ShareAccessStats{}
+			slog.Error("failed to get share access stats", "noteId", note.ID, "error", err)
+		}
+		// 共有リンクや共有したユーザーが最後に変更した場合は、誰がどのリンクから変更したかを表示する
+		if latest, err := n.revisionUsecase.FindLatest(note); err != nil {
+			slog.Error("failed to get latest revision", "noteId", note.ID, "error", err)
+		} else {
+			lastEditor = lastEditorLabel(note, latest, shares)
+		}
+		if members, err = n.memberUsecase.FindByNote(note); err != nil {
+			members = []*usecase.NoteMemberItem{}
+			slog.Error("failed to get note members", "noteId", note.ID, "error", err)
+		}
+		if noteTags, err = n.tagUsecase.FindByNote(note); err != nil {
+			noteTags = []*domain.Tag{}
+			slog.Error("failed to get tags for note", "noteId", note.ID, "error", err)
+		}
+		// リンクはオーナーのメモの間で解決されるため、オーナーにだけ表示する
+		if links, err = n.noteUsecase.FindLinks(note); err != nil {
+			links = []*usecase.NoteLink{}
+			slog.Error("failed to get links", "noteId", note.ID, "error", err)
+		}
+		if backlinks, err = n.noteUsecase.FindBacklinks(note); err != nil {
+			backlinks = []*usecase.Backlink{}
+			slog.Error("failed to get backlinks", "noteId", note.ID, "error", err)
+		}
+	}
+	sharedNotes, err := n.memberUsecase.FindSharedWithUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get shared notes", "error", err)
+		sharedNotes = []*usecase.SharedNoteSummary{}
 	}
 
-	// 最初のメモを選択、なければ新規作成
+	tags, err := n.tagUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get tags", "error", err)
+		tags = []*usecase.TagSummary{}
+	}
+	folders, err := n.folderUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get folders", "error", err)
+		folders = []*domain.Folder{}
+	}
+	attachments, err := n.attachmentUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get attachments", "noteId", note.ID, "error", err)
+		attachments = []*domain.Attachment{}
+	}
+	templates, err := n.templateUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get templates", "error", err)
+		templates = []*domain.NoteTemplate{}
+	}
+	noteFolder := ""
+	if note.FolderID != nil && isOwner {
+		noteFolder = *note.FolderID
+	}
+
+	c.HTML(http.StatusOK, "editor.html", gin.H{
+		"title":        "メモエディター",
+		"note":         note,
+		"notes":        notes.Notes,
+		"nextCursor":   notes.NextCursor,
+		"sort":         c.DefaultQuery("sort", usecase.NoteSortUpdated),
+		"shares":       newShareItems(shares, accessStats),
+		"tags":         newTagCloud(tags, c.Query("tag")),
+		"noteTags":     noteTags,
+		"activeTag":    c.Query("tag"),
+		"folders":      newFolderTree(folders, c.Query("folder")),
+		"activeFolder": c.Query("folder"),
+		"favoriteOnly": c.Query("favorite") == "1",
+		"noteFolder":   noteFolder,
+		"attachments":  newAttachmentItems(attachments),
+		"templates":    templates,
+		"links":        links,
+		"backlinks":    backlinks,
+		"members":      members,
+		"lastEditor":   lastEditor,
+		"sharedNotes":  newSharedNoteItems(sharedNotes, note.ID),
+		"isOwner":      isOwner,
+		"canEdit":      usecase.CanEditNote(role),
+		"roleLabel":    noteRoleLabels[role],
+		"noteOwner":    sharedNoteOwner(sharedNotes, note.ID),
+	})
+}
+
+func (n *noteController) getNote(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	// ユーザーのメモを取得
+	notes := n.findListNotes(c, user)
+
+	// 一覧の先頭のメモを選択、なければ新規作成
 	var currentNote *domain.Note
-	if len(notes) > 0 {
-		currentNote = notes[0]
+	if len(notes.Notes) > 0 {
+		note, err := n.noteUsecase.Find(notes.Notes[0].ID)
+		if err != nil {
+			slog.Error("failed to get note", "noteId", notes.Notes[0].ID, "error", err)
+			c.Redirect(http.StatusSeeOther, "/note/new")
+			return
+		}
+		currentNote = note
+	} else if c.Query("tag") != "" {
+		// 絞り込み結果が空の場合は絞り込みを解除する
+		c.Redirect(http.StatusSeeOther, "/note")
+		return
+	} else if c.Query("folder") != "" {
+		// 空のフォルダでは一覧を空のまま、最近更新したメモを開く
+		if latest, err := n.findLatestNote(user, usecase.NoteListOptions{}); err == nil {
+			currentNote = latest
+		} else {
+			c.Redirect(http.StatusSeeOther, "/note")
+			return
+		}
 	} else {
-		newNote, err := n.noteUsecase.CreateNote(user)
+		newNote, err := n.noteUsecase.CreateNote(user, nil)
 		if err != nil {
 			slog.Error("failed to save new note", "error", err)
 		} else {
 			currentNote = newNote
-			notes = []*domain.Note{currentNote}
+			notes.Notes = []*usecase.NoteSummary{{
+				ID:        newNote.ID,
+				Title:     newNote.Title,
+				CreatedAt: newNote.CreatedAt,
+				UpdatedAt: newNote.UpdatedAt,
+			}}
 		}
 	}
 
-	var shares []*domain.SharingInfo
-	if shareInfoArr, err := n.shareUsecase.FindByNote(currentNote); err == nil {
-		shares = shareInfoArr
-	} else {
-		shares = []*domain.SharingInfo{}
-		slog.Error("failed to get share info for note", "noteId", currentNote.ID, "error", err)
-	}
-
-	c.HTML(http.StatusOK, "editor.html", gin.H{
-		"title":  "メモエディター",
-		"note":   currentNote,
-		"notes":  notes,
-		"shares": shares,
-	})
+	n.renderEditor(c, user, currentNote, notes, usecase.NoteRoleOwner)
 }
 
 func (n *noteController) getNoteById(c *gin.Context) {
@@ -101,45 +339,61 @@ func (n *noteController) getNoteById(c *gin.Context) {
 		return
 	}
 
-	if note.AuthorID != user.ID {
+	// オーナーのほか、メモを共有されたユーザーも開ける
+	role, err := n.memberUsecase.NoteRole(note, user)
+	if err != nil {
+		slog.Error("failed to get note role", "noteId", noteId, "error", err)
+	}
+	if role == "" {
 		c.Redirect(http.StatusSeeOther, "/note")
 		return
 	}
 
-	// ユーザーの全メモを取得
-	notes, err := n.noteUsecase.FindNotesByUserID(user.ID)
-	if err != nil {
-		slog.Error("failed to get notes", "error", err)
-		notes = []*domain.Note{}
-	}
+	// ユーザーのメモを取得
+	notes := n.findListNotes(c, user)
 
-	var shares []*domain.SharingInfo
-	if shareInfoArr, err := n.shareUsecase.FindByNote(note); err == nil {
-		shares = shareInfoArr
-	} else {
-		shares = []*domain.SharingInfo{}
-		slog.Error("failed to get share info for note", "noteId", note.ID, "error", err)
-	}
-
-	c.HTML(http.StatusOK, "editor.html", gin.H{
-		"title":  "メモエディター",
-		"note":   note,
-		"notes":  notes,
-		"shares": shares,
-	})
+	n.renderEditor(c, user, note, notes, role)
 }
 
 func (n *noteController) getCreateNewNote(c *gin.Context) {
 	user := secure.GetSessionUser(c)
 
-	// 新規メモを作成
-	newNote, err := n.noteUsecase.CreateNote(user)
+	// ?folder= が指定されていればそのフォルダに作成する
+	var folder *domain.Folder
+	if folderId := c.Query("folder"); folderId != "" {
+		if found, err := n.folderUsecase.Find(folderId); err == nil && found.UserID == user.ID {
+			folder = found
+		}
+	}
+
+	// 新規メモを作成（?template= が指定されていればテンプレートから作成する）
+	var newNote *domain.Note
+	var err error
+	if templateId := c.Query("template"); templateId != "" {
+		template, ok := findOwnedTemplate(n.templateUsecase, user, templateId)
+		if !ok {
+			showNotFoundPage(c)
+			return
+		}
+		newNote, err = n.noteUsecase.CreateNoteFromTemplate(user, template, folder, maxNoteContentLength)
+	} else {
+		newNote, err = n.noteUsecase.CreateNote(user, folder)
+	}
+	if errors.Is(err, usecase.ErrTemplateTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "template is too large"})
+		return
+	}
 	if err != nil {
 		slog.Error("failed to create new note", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create note"})
 		return
 	}
 
+	if folder != nil {
+		c.Redirect(http.StatusSeeOther, "/note/"+newNote.ID+"?folder="+url.QueryEscape(folder.ID))
+		return
+	}
+
 	c.Redirect(http.StatusSeeOther, "/note/"+newNote.ID)
 }
 
@@ -153,16 +407,20 @@ func (n *noteController) postSaveNote(c *gin.Context) {
 		return
 	}
 
-	title, titleValid := secure.ValidateTextInput(req.Title, 500) // Max 500 characters for title
+	title, titleValid := secure.ValidateTextInput(req.Title, maxNoteTitleLength)
 	if !titleValid {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid title"})
 		return
 	}
 
-	content, contentValid := secure.ValidateTextInput(req.Content, 1000000) // Max 1MB for content
-	if !contentValid {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid content"})
-		return
+	content := ""
+	if req.Patch == nil {
+		var contentValid bool
+		content, contentValid = secure.ValidateTextInput(req.Content, maxNoteContentLength)
+		if !contentValid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid content"})
+			return
+		}
 	}
 
 	note, err := n.noteUsecase.Find(req.ID)
@@ -172,22 +430,57 @@ func (n *noteController) postSaveNote(c *gin.Context) {
 		return
 	}
 
-	if note.AuthorID != user.ID {
+	// オーナーと編集者として共有されたユーザーが保存できる
+	role, err := n.memberUsecase.NoteRole(note, user)
+	if err != nil {
+		slog.Error("failed to get note role", "noteId", req.ID, "error", err)
+	}
+	if !usecase.CanEditNote(role) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
 		return
 	}
 
 	note.Title = title
 	note.Content = content
+	note.Version = req.Version
 
-	_, err = n.noteUsecase.UpdateNote(note)
+	updated, err := saveNoteContent(n.noteUsecase, note, req.Patch, userEditor(user))
+	if errors.Is(err, usecase.ErrNoteConflict) {
+		if current, findErr := n.noteUsecase.Find(req.ID); findErr == nil {
+			c.JSON(http.StatusConflict, noteConflictResponse(current))
+			return
+		}
+	}
+	if isInvalidNotePatch(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid patch"})
+		return
+	}
 	if err != nil {
 		slog.Error("failed to update note", "noteId", req.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save note"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"status": "success"})
+	c.JSON(http.StatusOK, gin.H{"status": "success", "version": updated.Version})
+}
+
+// userEditor はログイン中のユーザーを、メールアドレスを表示名として変更履歴に記録する編集者にする
+func userEditor(user *domain.User) usecase.NoteEditor {
+	return usecase.NoteEditor{UserID: user.ID, DisplayName: user.Email}
+}
+
+// saveNoteContent は note.Content の全文か、patch があれば note.Version の本文に
+// patch を適用した内容でメモを保存する
+func saveNoteContent(noteUsecase usecase.INoteUsecase, note *domain.Note, patch *usecase.TextOp, editor usecase.NoteEditor) (*domain.Note, error) {
+	if patch != nil {
+		return noteUsecase.PatchNote(note, *patch, maxNoteContentLength, editor)
+	}
+	return noteUsecase.UpdateNote(note, editor)
+}
+
+// isInvalidNotePatch は保存されている本文に適用できない patch のエラーかを返す
+func isInvalidNotePatch(err error) bool {
+	return errors.Is(err, usecase.ErrTextOpMismatch) || errors.Is(err, usecase.ErrInvalidNotePatch)
 }
 
 func (n *noteController) deleteNote(c *gin.Context) {
@@ -215,3 +508,237 @@ func (n *noteController) deleteNote(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "success"})
 }
+
+func (n *noteController) getSearchNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	query, valid := secure.ValidateTextInput(c.Query("q"), 200)
+	if !valid || query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query"})
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	results, err := n.noteUsecase.Search(user.ID, query, limit, offset)
+	if err != nil {
+		slog.Error("failed to search notes", "query", query, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to search notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"results": results})
+}
+
+// getListNotes はサイドバーの無限スクロールで続きのメモを返す
+func (n *noteController) getListNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	opts := noteListOptions(c)
+	opts.Limit, _ = strconv.Atoi(c.Query("limit"))
+	page, err := n.noteUsecase.ListNotes(user.ID, opts)
+	if errors.Is(err, usecase.ErrInvalidNoteSort) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidNoteCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to list notes", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notes": page.Notes, "next_cursor": page.NextCursor})
+}
+
+// getExportNotes はすべてのメモを Markdown の ZIP としてダウンロードさせる
+func (n *noteController) getExportNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	archive, err := n.exportUsecase.Prepare(user, usecase.ExportOptions{ShareBaseURL: requestOrigin(c), IncludeShares: true})
+	if err != nil {
+		slog.Error("failed to prepare export", "userId", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export notes"})
+		return
+	}
+	if err := writeNoteArchive(c, archive); err != nil {
+		slog.Error("failed to write export", "userId", user.ID, "error", err)
+	}
+}
+
+// postImportNotes は ZIP（.md / .txt）または Evernote の ENEX からメモを取り込む。
+// 取り込めなかったファイルがあっても、他のファイルは取り込んで結果を返す
+func (n *noteController) postImportNotes(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	files, err := readImportUpload(c, n.importUsecase)
+	if err != nil {
+		status := importUploadErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			slog.Error("failed to read import", "userId", user.ID, "error", err)
+			c.JSON(status, gin.H{"error": "failed to import notes"})
+			return
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+	if err := n.importUsecase.Import(user, files); err != nil {
+		slog.Error("failed to import notes", "userId", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import notes"})
+		return
+	}
+
+	summary := newImportSummary(files)
+	slog.Info("imported notes", "userId", user.ID, "imported", summary.Imported, "failed", summary.Failed)
+	c.JSON(http.StatusOK, gin.H{"status": "success", "imported": summary.Imported, "failed": summary.Failed, "results": summary.Results})
+}
+
+func (n *noteController) putNoteFormat(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	noteId := c.Param("id")
+
+	note, err := n.noteUsecase.Find(noteId)
+	if err != nil || note.AuthorID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteFormatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := n.noteUsecase.SetFormat(note, req.Format); err != nil {
+		if errors.Is(err, usecase.ErrInvalidNoteFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid format"})
+			return
+		}
+		slog.Error("failed to set note format", "noteId", noteId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set format"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "format": note.Format})
+}
+
+// findOwnedNote はログインユーザーが所有するメモを取得する
+func (n *noteController) findOwnedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := n.noteUsecase.Find(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+// putNotePin はメモのピン留めを切り替える。ピン留めしたメモは一覧の先頭に並ぶ
+func (n *noteController) putNotePin(c *gin.Context) {
+	note, ok := n.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req notePinRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := n.noteUsecase.SetPinned(note, *req.Pinned); err != nil {
+		slog.Error("failed to pin note", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to pin note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "pinned": note.Pinned})
+}
+
+func (n *noteController) putNoteFavorite(c *gin.Context) {
+	note, ok := n.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteFavoriteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := n.noteUsecase.SetFavorite(note, *req.Favorite); err != nil {
+		slog.Error("failed to set favorite", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set favorite"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success", "favorite": note.Favorite})
+}
+
+// putNoteOrder はドラッグ&ドロップで並べ替えたメモの位置を保存する
+func (n *noteController) putNoteOrder(c *gin.Context) {
+	note, ok := n.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req noteOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	if err := n.noteUsecase.MoveNote(note, req.AfterID, req.BeforeID); err != nil {
+		if errors.Is(err, usecase.ErrInvalidNoteOrder) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order"})
+			return
+		}
+		slog.Error("failed to move note", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// getNoteLinks はメモからのリンクと、メモを参照しているバックリンクを返す
+func (n *noteController) getNoteLinks(c *gin.Context) {
+	note, ok := n.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	links, err := n.noteUsecase.FindLinks(note)
+	if err != nil {
+		slog.Error("failed to get links", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get links"})
+		return
+	}
+	backlinks, err := n.noteUsecase.FindBacklinks(note)
+	if err != nil {
+		slog.Error("failed to get backlinks", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get links"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"links": links, "backlinks": backlinks})
+}
+
+// getBrokenLinks はログインユーザーのメモにある、存在しないタイトルへのリンクを返す
+func (n *noteController) getBrokenLinks(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	broken, err := n.noteUsecase.FindBrokenLinks(user.ID)
+	if err != nil {
+		slog.Error("failed to get broken links", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get broken links"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"broken": broken})
+}
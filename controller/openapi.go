@@ -0,0 +1,516 @@
+package controller
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 仕様書に含めないルート（静的ファイル）
+const openAPIStaticPrefix = "/static/"
+
+var ginPathParamPattern = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+type IOpenAPIController interface {
+	getOpenAPISpec(c *gin.Context)
+	getAPIDocs(c *gin.Context)
+}
+
+type openAPIController struct {
+	router *gin.Engine
+	once   sync.Once
+	spec   *openAPIDocument
+}
+
+// openAPIAuth は操作に必要な認証
+type openAPIAuth int
+
+const (
+	openAPIAuthNone openAPIAuth = iota
+	// ブラウザのログインセッション（auth_token クッキー）
+	openAPIAuthSession
+	// ログインセッションか個人用アクセストークン
+	openAPIAuthToken
+)
+
+// 本文の形式
+const (
+	openAPIJSON      = "application/json"
+	openAPIHTML      = "text/html"
+	openAPIMultipart = "multipart/form-data"
+)
+
+// openAPIRoute は1つのルートの概要とリクエスト・レスポンスの型。パスパラメーター・操作 ID・分類・
+// エラーの形はルートから決め、リクエストとレスポンスの形は Go の型から生成する
+type openAPIRoute struct {
+	Summary string
+	Auth    openAPIAuth
+	// Auth が openAPIAuthToken の場合にアクセストークンに必要なスコープ
+	Scope string
+	Query []openAPIParameter
+	// Request は本文の型を表す値。form タグを持つ構造体はフォームとして扱う
+	Request     any
+	RequestType string
+	// Response は成功時の本文の型を表す値か *openAPISchema、openAPIObject。nil の場合は本文の形を示さない
+	Response any
+	// Status は成功時のステータス。既定値は 200
+	Status int
+	// ContentType は成功時の本文の形式。既定値は application/json
+	ContentType string
+}
+
+// openAPIObject は gin.H で組み立てるレスポンスの形を表す。値には Go の値か *openAPISchema を指定する
+type openAPIObject map[string]any
+
+type openAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       openAPIInfo                `json:"info"`
+	Paths      map[string]openAPIPathItem `json:"paths"`
+	Components openAPIComponents          `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIPathItem map[string]*openAPIOperationObject
+
+type openAPIOperationObject struct {
+	Summary     string                        `json:"summary,omitempty"`
+	Description string                        `json:"description,omitempty"`
+	OperationID string                        `json:"operationId"`
+	Tags        []string                      `json:"tags,omitempty"`
+	Parameters  []openAPIParameter            `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponseObj `json:"responses"`
+	Security    []map[string][]string         `json:"security,omitempty"`
+	Scope       string                        `json:"x-required-scope,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponseObj struct {
+	Description string                      `json:"description"`
+	Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description"`
+	Schema      *openAPISchema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema,omitempty"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*openAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme,omitempty"`
+	In          string `json:"in,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*openAPISchema          `json:"oneOf,omitempty"`
+}
+
+func NewOpenAPIController(router *gin.Engine) IOpenAPIController {
+	instance := &openAPIController{router: router}
+	setupOpenAPIRoute(instance, router)
+	return instance
+}
+
+func setupOpenAPIRoute(api IOpenAPIController, router *gin.Engine) {
+	router.GET("/api/openapi.json", api.getOpenAPISpec)
+	router.GET("/api/docs", api.getAPIDocs)
+}
+
+// getOpenAPISpec はすべてのルートが登録された後の初回のリクエストで仕様書を生成する
+func (o *openAPIController) getOpenAPISpec(c *gin.Context) {
+	o.once.Do(func() {
+		o.spec = buildOpenAPIDocument(o.router.Routes())
+	})
+	c.JSON(http.StatusOK, o.spec)
+}
+
+func (o *openAPIController) getAPIDocs(c *gin.Context) {
+	c.Redirect(http.StatusMovedPermanently, "/static/api-docs.html")
+}
+
+func openAPIRouteKey(method string, path string) string {
+	return method + " " + path
+}
+
+// documentedRoutes は仕様書に含めるルート（静的ファイル以外）を返す
+func documentedRoutes(routes gin.RoutesInfo) gin.RoutesInfo {
+	var documented gin.RoutesInfo
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, openAPIStaticPrefix) {
+			documented = append(documented, route)
+		}
+	}
+	return documented
+}
+
+// buildOpenAPIDocument は登録されたすべてのルートから OpenAPI 3 の仕様書を組み立てる。
+// openAPIRoutes に概要のないルートも、パスとパラメーターだけで含める
+func buildOpenAPIDocument(routes gin.RoutesInfo) *openAPIDocument {
+	gen := newOpenAPISchemaGenerator()
+	doc := &openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "ELib",
+			Version:     "1.0.0",
+			Description: "登録されている gin のルートとリクエスト・レスポンスの Go の型から生成した仕様書",
+		},
+		Paths: map[string]openAPIPathItem{},
+		Components: openAPIComponents{
+			Schemas: gen.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"cookieAuth": {
+					Type:        "apiKey",
+					In:          "cookie",
+					Name:        "auth_token",
+					Description: "ログイン時に発行されるセッションのクッキー",
+				},
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "設定画面で発行した個人用アクセストークン",
+				},
+			},
+		},
+	}
+
+	sorted := documentedRoutes(routes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].Method < sorted[j].Method
+	})
+	for _, route := range sorted {
+		path := ginPathParamPattern.ReplaceAllString(route.Path, "{$1}")
+		item, exists := doc.Paths[path]
+		if !exists {
+			item = openAPIPathItem{}
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = gen.operation(route, openAPIRoutes[openAPIRouteKey(route.Method, route.Path)])
+	}
+	return doc
+}
+
+func openAPIOperationID(route gin.RouteInfo) string {
+	// ハンドラー名の末尾（例: controller.INoteController.postSaveNote-fm）から ID を作る
+	name := route.Handler
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.TrimSuffix(name, "-fm")
+	prefix := ""
+	if strings.HasPrefix(route.Path, "/api/v1/") {
+		prefix = "apiV1"
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	return prefix + name
+}
+
+// handlerControllerPattern はハンドラー名（例: controller.INoteController.postSaveNote-fm）から
+// コントローラーの名前を取り出す
+var handlerControllerPattern = regexp.MustCompile(`\.I([A-Za-z0-9]+)Controller\.`)
+
+// openAPITag はルートを、ハンドラーを持つコントローラーで分類する
+func openAPITag(route gin.RouteInfo) string {
+	match := handlerControllerPattern.FindStringSubmatch(route.Handler)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// openAPIErrorSchema はルートが返すエラーの形。/api/v1 は {"error": {"code", "message"}}、
+// 画面用のエンドポイントは {"error": "..."} を返す
+func openAPIErrorSchema(route gin.RouteInfo) any {
+	if strings.HasPrefix(route.Path, "/api/v1/") {
+		return openAPIObject{"error": apiError{}}
+	}
+	return openAPIObject{"error": openAPIString}
+}
+
+type openAPISchemaGenerator struct {
+	schemas map[string]*openAPISchema
+	names   map[reflect.Type]string
+	// request はリクエスト本文のスキーマを生成中かどうか。リクエストでは binding:"required" の項目を、
+	// レスポンスでは omitempty でない項目を必須とする
+	request bool
+}
+
+func newOpenAPISchemaGenerator() *openAPISchemaGenerator {
+	return &openAPISchemaGenerator{
+		schemas: map[string]*openAPISchema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+func (g *openAPISchemaGenerator) operation(route gin.RouteInfo, op openAPIRoute) *openAPIOperationObject {
+	obj := &openAPIOperationObject{
+		Summary:     op.Summary,
+		OperationID: openAPIOperationID(route),
+		Responses:   map[string]openAPIResponseObj{},
+	}
+	if tag := openAPITag(route); tag != "" {
+		obj.Tags = []string{tag}
+	}
+
+	for _, match := range ginPathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		obj.Parameters = append(obj.Parameters, openAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &openAPISchema{Type: "string"},
+		})
+	}
+	obj.Parameters = append(obj.Parameters, op.Query...)
+
+	switch op.Auth {
+	case openAPIAuthSession:
+		obj.Security = []map[string][]string{{"cookieAuth": {}}}
+	case openAPIAuthToken:
+		obj.Security = []map[string][]string{{"cookieAuth": {}}, {"bearerAuth": {}}}
+		obj.Scope = op.Scope
+	}
+
+	if op.Request != nil {
+		contentType := op.RequestType
+		if contentType == "" {
+			contentType = openAPIJSON
+			if isFormStruct(reflect.TypeOf(op.Request)) {
+				contentType = "application/x-www-form-urlencoded"
+			}
+		}
+		g.request = true
+		obj.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  map[string]openAPIMediaType{contentType: {Schema: g.schemaFor(op.Request)}},
+		}
+		g.request = false
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := openAPIResponseObj{Description: http.StatusText(status)}
+	if op.Response != nil || op.ContentType != "" {
+		contentType := op.ContentType
+		if contentType == "" {
+			contentType = openAPIJSON
+		}
+		media := openAPIMediaType{}
+		if op.Response != nil {
+			media.Schema = g.schemaFor(op.Response)
+		}
+		success.Content = map[string]openAPIMediaType{contentType: media}
+	}
+	if status >= 300 && status < 400 {
+		success.Headers = map[string]openAPIHeader{"Location": {Description: "リダイレクト先", Schema: &openAPISchema{Type: "string"}}}
+	}
+	obj.Responses[strconv.Itoa(status)] = success
+	obj.Responses["default"] = openAPIResponseObj{
+		Description: "エラー",
+		Content:     map[string]openAPIMediaType{openAPIJSON: {Schema: g.schemaFor(openAPIErrorSchema(route))}},
+	}
+	return obj
+}
+
+func isFormStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if _, ok := t.Field(i).Tag.Lookup("form"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaFor は値の種類に応じてスキーマを返す
+func (g *openAPISchemaGenerator) schemaFor(v any) *openAPISchema {
+	switch v := v.(type) {
+	case *openAPISchema:
+		return v
+	case openAPIObject:
+		schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+		for name, value := range v {
+			schema.Properties[name] = g.schemaFor(value)
+			schema.Required = append(schema.Required, name)
+		}
+		sort.Strings(schema.Required)
+		return schema
+	}
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	textOpType    = reflect.TypeOf(usecase.TextOp{})
+)
+
+// schemaOf は encoding/json と同じ規則で Go の型をスキーマに変換する。
+// 名前のある構造体は components に登録して参照する
+func (g *openAPISchemaGenerator) schemaOf(t reflect.Type) *openAPISchema {
+	switch t {
+	case timeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &openAPISchema{Type: "string", Format: "date-time", Nullable: true}
+	case textOpType:
+		return &openAPISchema{
+			Type:        "array",
+			Description: "ot.js 形式の操作。正の数は保持、負の数は削除、文字列は挿入",
+			Items: &openAPISchema{OneOf: []*openAPISchema{
+				{Type: "integer"},
+				{Type: "string"},
+			}},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schemaOf(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		nullable := *schema
+		nullable.Nullable = true
+		return &nullable
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Interface:
+		return &openAPISchema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := g.schemaName(t)
+		if _, exists := g.schemas[name]; !exists {
+			// 再帰的な型のために先に登録してから中身を埋める
+			g.schemas[name] = &openAPISchema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &openAPISchema{Ref: "#/components/schemas/" + name}
+	}
+	return &openAPISchema{}
+}
+
+// schemaName は型名をスキーマ名にする。別のパッケージに同じ名前の型がある場合はパッケージ名を付ける
+func (g *openAPISchemaGenerator) schemaName(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	for other, used := range g.names {
+		if used == name && other != t {
+			pkg := t.PkgPath()
+			name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+			break
+		}
+	}
+	g.names[t] = name
+	return name
+}
+
+func (g *openAPISchemaGenerator) structSchema(t reflect.Type) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+	g.addStructFields(schema, t)
+	return schema
+}
+
+func (g *openAPISchemaGenerator) addStructFields(schema *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "" {
+			tag = field.Tag.Get("form")
+		}
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			g.addStructFields(schema, field.Type)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schemaOf(field.Type)
+		required := !strings.Contains(options, "omitempty")
+		if g.request {
+			required = strings.Contains(field.Tag.Get("binding"), "required")
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
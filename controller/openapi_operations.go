@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/usecase"
+)
+
+var (
+	openAPIString = &openAPISchema{Type: "string"}
+	openAPIInt32  = &openAPISchema{Type: "integer", Format: "int32"}
+	openAPIInt64  = &openAPISchema{Type: "integer", Format: "int64"}
+	openAPIBinary = &openAPISchema{Type: "string", Format: "binary"}
+	openAPIBool   = &openAPISchema{Type: "boolean"}
+
+	// 画面用のエンドポイントが成功時に返す本文
+	openAPISuccess       = &openAPISchema{Type: "string", Enum: []string{"success"}}
+	openAPIStatusSuccess = openAPIObject{"status": openAPISuccess}
+
+	// アップロードするファイル
+	openAPIFileUpload = openAPIObject{"file": openAPIBinary}
+)
+
+func queryParameter(name string, description string, schema *openAPISchema) openAPIParameter {
+	return openAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+var (
+	openAPISearchParameters = []openAPIParameter{
+		{Name: "q", In: "query", Description: "検索語（200 文字まで）", Required: true, Schema: openAPIString},
+		queryParameter("limit", "取得する件数（1〜100、既定 20）", openAPIInt32),
+		queryParameter("offset", "読み飛ばす件数（既定 0）", openAPIInt32),
+	}
+
+	// メモ一覧の絞り込みと並び順
+	openAPINoteListParameters = []openAPIParameter{
+		queryParameter("tag", "タグ ID で一覧を絞り込む", openAPIString),
+		queryParameter("folder", "フォルダ ID で一覧を絞り込む", openAPIString),
+		queryParameter("favorite", "1 でお気に入りのメモに絞り込む", openAPIString),
+		queryParameter("sort", "一覧の並び順（既定 updated。どの並び順でもピン留めしたメモが先頭）",
+			&openAPISchema{Type: "string", Enum: []string{usecase.NoteSortUpdated, usecase.NoteSortCreated, usecase.NoteSortTitle, usecase.NoteSortManual}}),
+	}
+	openAPINotePageParameters = append(append([]openAPIParameter{}, openAPINoteListParameters...),
+		queryParameter("cursor", "前のページの次のカーソル", openAPIString),
+		queryParameter("limit", "取得する件数（1〜100）", openAPIInt32),
+	)
+
+	openAPICollabParameters = []openAPIParameter{
+		{Name: "client", In: "query", Description: "クライアント ID（英数字とハイフン、8〜64 文字）", Required: true, Schema: openAPIString},
+		queryParameter("epoch", "再接続時に前回の init で受け取った epoch", openAPIString),
+		queryParameter("rev", "再接続時に最後に受け取ったリビジョン番号", openAPIInt32),
+	}
+)
+
+// openAPIRoutes は各ルートの概要とリクエスト・レスポンスの型。キーは "メソッド gin のパス"。
+// 仕様書のパスは登録されたルートから作るため、ここにないルートも概要なしで仕様書に含まれる
+var openAPIRoutes = map[string]openAPIRoute{
+	// ユーザー
+	"GET /user/login":         {Summary: "ログイン画面", ContentType: openAPIHTML},
+	"POST /user/login":        {Summary: "ログイン", Request: loginForm{}, Status: http.StatusSeeOther},
+	"GET /user/register":      {Summary: "ユーザー登録画面", ContentType: openAPIHTML},
+	"POST /user/register":     {Summary: "ユーザー登録", Request: registerForm{}, Status: http.StatusSeeOther},
+	"POST /user/logout":       {Summary: "ログアウト", Status: http.StatusSeeOther},
+	"GET /user/settings":      {Summary: "設定画面", Auth: openAPIAuthSession, ContentType: openAPIHTML},
+	"PUT /user/settings":      {Summary: "新規メモのタイトル形式の保存", Auth: openAPIAuthSession, Request: userSettingsRequest{}, Response: openAPIStatusSuccess},
+	"POST /user/tokens":       {Summary: "個人用アクセストークンの発行", Auth: openAPIAuthSession, Request: accessTokenRequest{}, Status: http.StatusCreated, Response: openAPIObject{"status": openAPISuccess, "id": openAPIString, "token": openAPIString}},
+	"DELETE /user/tokens/:id": {Summary: "個人用アクセストークンの取り消し", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// メモ
+	"GET /note":                    {Summary: "メモ一覧と最新のメモのエディター", Auth: openAPIAuthSession, Query: openAPINoteListParameters, ContentType: openAPIHTML},
+	"GET /note/:id":                {Summary: "メモのエディター", Auth: openAPIAuthSession, Query: openAPINoteListParameters, ContentType: openAPIHTML},
+	"GET /note/new":                {Summary: "メモを作成してエディターを開く", Auth: openAPIAuthSession, Query: []openAPIParameter{queryParameter("folder", "作成するメモのフォルダ ID", openAPIString), queryParameter("template", "作成元のテンプレート ID", openAPIString)}, Status: http.StatusSeeOther},
+	"POST /note/save":              {Summary: "メモの保存", Auth: openAPIAuthSession, Request: saveNoteRequest{}, Response: openAPIObject{"status": openAPISuccess, "version": openAPIInt32}},
+	"DELETE /note/delete/:id":      {Summary: "メモをゴミ箱に移動", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+	"GET /note/search":             {Summary: "メモの全文検索", Auth: openAPIAuthSession, Query: openAPISearchParameters, Response: openAPIObject{"results": []*usecase.NoteSearchResult{}}},
+	"GET /note/export":             {Summary: "すべてのメモを ZIP でダウンロード", Auth: openAPIAuthSession, ContentType: "application/zip", Response: openAPIBinary},
+	"POST /note/import":            {Summary: "ZIP または ENEX からメモを取り込む", Auth: openAPIAuthSession, Request: openAPIFileUpload, RequestType: openAPIMultipart, Response: openAPIObject{"status": openAPISuccess, "imported": openAPIInt32, "failed": openAPIInt32, "results": []importResult{}}},
+	"GET /note/list":               {Summary: "サイドバーのメモ一覧の続き", Auth: openAPIAuthSession, Query: openAPINotePageParameters, Response: openAPIObject{"notes": []*usecase.NoteSummary{}, "next_cursor": openAPIString}},
+	"PUT /note/:id/pin":            {Summary: "メモのピン留め", Auth: openAPIAuthSession, Request: notePinRequest{}, Response: openAPIObject{"status": openAPISuccess, "pinned": openAPIBool}},
+	"PUT /note/:id/favorite":       {Summary: "メモのお気に入り登録・解除", Auth: openAPIAuthSession, Request: noteFavoriteRequest{}, Response: openAPIObject{"status": openAPISuccess, "favorite": openAPIBool}},
+	"PUT /note/:id/order":          {Summary: "手動の並び順でのメモの移動", Auth: openAPIAuthSession, Request: noteOrderRequest{}, Response: openAPIStatusSuccess},
+	"PUT /note/:id/format":         {Summary: "メモの表示形式の変更", Auth: openAPIAuthSession, Request: noteFormatRequest{}, Response: openAPIObject{"status": openAPISuccess, "format": openAPIString}},
+	"GET /note/:id/links":          {Summary: "メモのリンクとバックリンク", Auth: openAPIAuthSession, Response: openAPIObject{"links": []*usecase.NoteLink{}, "backlinks": []*usecase.Backlink{}}},
+	"GET /note/links/broken":       {Summary: "リンク切れの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"broken": []*usecase.BrokenLink{}}},
+	"PUT /note/:id/folder":         {Summary: "メモのフォルダの変更", Auth: openAPIAuthSession, Request: noteFolderRequest{}, Response: openAPIStatusSuccess},
+	"POST /note/:id/tags":          {Summary: "メモにタグを付ける", Auth: openAPIAuthSession, Request: tagNameRequest{}, Response: openAPIObject{"status": openAPISuccess, "tag": domain.Tag{}}},
+	"DELETE /note/:id/tags/:tagId": {Summary: "メモからタグを外す", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// 共有リンク
+	"GET /share/:id":                           {Summary: "共有メモの閲覧・編集画面", ContentType: openAPIHTML},
+	"POST /share":                              {Summary: "共有リンクの作成", Auth: openAPIAuthSession, Request: shareRequest{}, Response: openAPIObject{"shareId": openAPIString, "share": apiShare{}}},
+	"PUT /share/:id":                           {Summary: "共有リンクからメモを保存", Request: noteEditRequest{}, Response: openAPIObject{"message": openAPIString, "version": openAPIInt32}},
+	"DELETE /share/:id":                        {Summary: "共有リンクの削除", Auth: openAPIAuthSession, Response: openAPIObject{"message": openAPIString}},
+	"PUT /share/:id/password":                  {Summary: "共有リンクのパスワードの設定・解除", Auth: openAPIAuthSession, Request: sharePasswordRequest{}, Response: openAPIObject{"message": openAPIString, "passwordProtected": openAPIBool}},
+	"PUT /share/:id/name":                      {Summary: "共有リンクの編集者の表示名の設定", Request: shareEditorNameRequest{}, Response: openAPIObject{"message": openAPIString, "name": openAPIString}},
+	"GET /share/:id/access":                    {Summary: "共有リンクのアクセス記録", Auth: openAPIAuthSession, Response: openAPIObject{"stats": usecase.ShareAccessStats{}, "accesses": []*usecase.ShareAccessItem{}}},
+	"POST /share/:id/unlock":                   {Summary: "パスワード付きの共有リンクの解除", Request: shareUnlockForm{}, Status: http.StatusSeeOther},
+	"GET /share/:id/events":                    {Summary: "共有メモの更新通知（Server-Sent Events）", ContentType: "text/event-stream", Response: sharedNoteUpdate{}},
+	"GET /share/:id/attachments/:attachmentId": {Summary: "共有リンクからの添付ファイルのダウンロード", ContentType: "application/octet-stream", Response: openAPIBinary},
+	"GET /share/:id/ws":                        {Summary: "共有リンクからの共同編集（WebSocket）", Query: openAPICollabParameters, Status: http.StatusSwitchingProtocols},
+
+	// ユーザーとの共有
+	"GET /note/:id/members":            {Summary: "メモを共有しているユーザーの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"members": []*usecase.NoteMemberItem{}}},
+	"POST /note/:id/members":           {Summary: "メモをユーザーと共有", Auth: openAPIAuthSession, Request: noteMemberRequest{}, Response: openAPIObject{"status": openAPISuccess, "member": usecase.NoteMemberItem{}}},
+	"PUT /note/:id/members/:userId":    {Summary: "共有したユーザーの権限の変更", Auth: openAPIAuthSession, Request: noteRoleRequest{}, Response: openAPIObject{"status": openAPISuccess, "role": openAPIString}},
+	"DELETE /note/:id/members/:userId": {Summary: "ユーザーとの共有の解除", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// テンプレート
+	"GET /template":        {Summary: "テンプレートの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"templates": []*domain.NoteTemplate{}}},
+	"POST /template":       {Summary: "メモをテンプレートとして保存", Auth: openAPIAuthSession, Request: saveTemplateRequest{}, Response: openAPIObject{"status": openAPISuccess, "template": domain.NoteTemplate{}}},
+	"PUT /template/:id":    {Summary: "テンプレート名の変更", Auth: openAPIAuthSession, Request: templateNameRequest{}, Response: openAPIObject{"status": openAPISuccess, "template": domain.NoteTemplate{}}},
+	"DELETE /template/:id": {Summary: "テンプレートの削除", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// タグ
+	"GET /tag":        {Summary: "タグの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"tags": []*usecase.TagSummary{}}},
+	"POST /tag/merge": {Summary: "タグの統合", Auth: openAPIAuthSession, Request: mergeTagsRequest{}, Response: openAPIObject{"status": openAPISuccess, "tag": domain.Tag{}}},
+	"PUT /tag/:id":    {Summary: "タグ名の変更", Auth: openAPIAuthSession, Request: tagNameRequest{}, Response: openAPIObject{"status": openAPISuccess, "tag": domain.Tag{}}},
+	"DELETE /tag/:id": {Summary: "タグの削除", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// フォルダ
+	"GET /folder":          {Summary: "フォルダの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"folders": []*domain.Folder{}}},
+	"POST /folder":         {Summary: "フォルダの作成", Auth: openAPIAuthSession, Request: createFolderRequest{}, Response: openAPIObject{"status": openAPISuccess, "folder": domain.Folder{}}},
+	"PUT /folder/:id":      {Summary: "フォルダ名の変更", Auth: openAPIAuthSession, Request: renameFolderRequest{}, Response: openAPIObject{"status": openAPISuccess, "folder": domain.Folder{}}},
+	"PUT /folder/:id/move": {Summary: "フォルダの移動", Auth: openAPIAuthSession, Request: moveFolderRequest{}, Response: openAPIObject{"status": openAPISuccess, "folder": domain.Folder{}}},
+	"DELETE /folder/:id":   {Summary: "フォルダの削除", Auth: openAPIAuthSession, Query: []openAPIParameter{queryParameter("mode", "move なら中身を親フォルダに移動、cascade なら中身ごと削除", &openAPISchema{Type: "string", Enum: []string{"move", "cascade"}})}, Response: openAPIStatusSuccess},
+
+	// リビジョン
+	"GET /note/:id/revisions":                      {Summary: "リビジョン一覧画面", Auth: openAPIAuthSession, ContentType: openAPIHTML},
+	"GET /note/:id/revisions/:revisionId":          {Summary: "リビジョンの差分画面", Auth: openAPIAuthSession, Query: []openAPIParameter{queryParameter("against", "比較対象（current か リビジョン ID。省略時は直前のリビジョン）", openAPIString)}, ContentType: openAPIHTML},
+	"POST /note/:id/revisions/:revisionId/restore": {Summary: "リビジョンの復元", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// ゴミ箱
+	"GET /note/trash":              {Summary: "ゴミ箱画面", Auth: openAPIAuthSession, ContentType: openAPIHTML},
+	"DELETE /note/trash":           {Summary: "ゴミ箱を空にする", Auth: openAPIAuthSession, Response: openAPIObject{"status": openAPISuccess, "deleted": openAPIInt64}},
+	"POST /note/trash/:id/restore": {Summary: "ゴミ箱のメモの復元", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+	"DELETE /note/trash/:id":       {Summary: "ゴミ箱のメモの完全削除", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// 添付ファイル
+	"GET /note/:id/attachments":                  {Summary: "添付ファイルの一覧", Auth: openAPIAuthSession, Response: openAPIObject{"attachments": []*domain.Attachment{}}},
+	"POST /note/:id/attachments":                 {Summary: "添付ファイルのアップロード", Auth: openAPIAuthSession, Request: openAPIFileUpload, RequestType: openAPIMultipart, Response: openAPIObject{"status": openAPISuccess, "attachment": domain.Attachment{}}},
+	"GET /note/:id/attachments/:attachmentId":    {Summary: "添付ファイルのダウンロード", Auth: openAPIAuthSession, ContentType: "application/octet-stream", Response: openAPIBinary},
+	"DELETE /note/:id/attachments/:attachmentId": {Summary: "添付ファイルの削除", Auth: openAPIAuthSession, Response: openAPIStatusSuccess},
+
+	// 共同編集
+	"GET /note/:id/ws": {Summary: "メモの共同編集（WebSocket）", Auth: openAPIAuthSession, Query: openAPICollabParameters, Status: http.StatusSwitchingProtocols},
+
+	// REST API
+	"GET /api/v1/me":                {Summary: "ログイン中のユーザー", Auth: openAPIAuthToken, Response: openAPIObject{"data": apiUser{}}},
+	"GET /api/v1/notes":             {Summary: "メモの一覧（本文は含まない）", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesRead, Query: openAPINotePageParameters, Response: openAPIObject{"data": []apiNote{}, "pagination": apiCursorPagination{}}},
+	"POST /api/v1/notes":            {Summary: "メモの作成", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesWrite, Request: apiCreateNoteRequest{}, Status: http.StatusCreated, Response: openAPIObject{"data": apiNote{}}},
+	"GET /api/v1/notes/search":      {Summary: "メモの全文検索", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesRead, Query: openAPISearchParameters, Response: openAPIObject{"data": []apiNoteSearchResult{}, "pagination": apiPagination{}}},
+	"GET /api/v1/notes/:id":         {Summary: "メモの取得", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesRead, Response: openAPIObject{"data": apiNote{}}},
+	"PATCH /api/v1/notes/:id":       {Summary: "メモの更新", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesWrite, Request: apiUpdateNoteRequest{}, Response: openAPIObject{"data": apiNote{}}},
+	"DELETE /api/v1/notes/:id":      {Summary: "メモをゴミ箱に移動", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesWrite, Status: http.StatusNoContent},
+	"GET /api/v1/notes/:id/shares":  {Summary: "メモの共有リンクの一覧", Auth: openAPIAuthToken, Scope: usecase.ScopeSharesManage, Response: openAPIObject{"data": []apiShare{}}},
+	"POST /api/v1/notes/:id/shares": {Summary: "共有リンクの作成", Auth: openAPIAuthToken, Scope: usecase.ScopeSharesManage, Request: apiCreateShareRequest{}, Status: http.StatusCreated, Response: openAPIObject{"data": apiShare{}}},
+	"GET /api/v1/shares/:id":        {Summary: "共有リンクの取得", Auth: openAPIAuthToken, Scope: usecase.ScopeSharesManage, Response: openAPIObject{"data": apiShare{}}},
+	"DELETE /api/v1/shares/:id":     {Summary: "共有リンクの削除", Auth: openAPIAuthToken, Scope: usecase.ScopeSharesManage, Status: http.StatusNoContent},
+	"GET /api/v1/export":            {Summary: "すべてのメモを ZIP で取得", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesRead, ContentType: "application/zip", Response: openAPIBinary},
+	"POST /api/v1/import":           {Summary: "ZIP または ENEX からメモを取り込む", Auth: openAPIAuthToken, Scope: usecase.ScopeNotesWrite, Request: openAPIFileUpload, RequestType: openAPIMultipart, Response: openAPIObject{"data": importSummary{}}},
+
+	// 仕様書
+	"GET /api/openapi.json": {Summary: "この仕様書", Response: &openAPISchema{Type: "object"}},
+	"GET /api/docs":         {Summary: "仕様書の閲覧画面", Status: http.StatusMovedPermanently},
+}
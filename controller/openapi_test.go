@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newOpenAPITestRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewController(router)
+	return router
+}
+
+// TestBuildOpenAPIDocumentIncludesAllRoutes は登録されたすべてのルートが仕様書に含まれることを確認する
+func TestBuildOpenAPIDocumentIncludesAllRoutes(t *testing.T) {
+	router := newOpenAPITestRouter()
+
+	doc := buildOpenAPIDocument(router.Routes())
+	routes := documentedRoutes(router.Routes())
+	operations := 0
+	for _, item := range doc.Paths {
+		operations += len(item)
+	}
+	if operations != len(routes) {
+		t.Errorf("document has %d operations, want %d", operations, len(routes))
+	}
+	for _, route := range routes {
+		path := ginPathParamPattern.ReplaceAllString(route.Path, "{$1}")
+		op, ok := doc.Paths[path][strings.ToLower(route.Method)]
+		if !ok {
+			t.Errorf("route %q is missing from the document", openAPIRouteKey(route.Method, route.Path))
+			continue
+		}
+		if len(op.Tags) == 0 {
+			t.Errorf("route %q has no tag", openAPIRouteKey(route.Method, route.Path))
+		}
+	}
+}
+
+// TestOpenAPIRoutesMatchRegisteredRoutes は登録されたすべてのルートに概要があり、
+// どのルートにも一致しない概要が残っていないことを確認する
+func TestOpenAPIRoutesMatchRegisteredRoutes(t *testing.T) {
+	router := newOpenAPITestRouter()
+
+	registered := map[string]bool{}
+	for _, route := range documentedRoutes(router.Routes()) {
+		key := openAPIRouteKey(route.Method, route.Path)
+		registered[key] = true
+		if openAPIRoutes[key].Summary == "" {
+			t.Errorf("route %q has no summary in openAPIRoutes", key)
+		}
+	}
+	for key := range openAPIRoutes {
+		if !registered[key] {
+			t.Errorf("openAPIRoutes describes %q, which is not a registered route", key)
+		}
+	}
+}
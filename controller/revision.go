@@ -0,0 +1,238 @@
+package controller
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type IRevisionController interface {
+	getRevisions(c *gin.Context)
+	getRevision(c *gin.Context)
+	postRestoreRevision(c *gin.Context)
+}
+
+type revisionController struct {
+	noteUsecase     usecase.INoteUsecase
+	revisionUsecase usecase.IRevisionUsecase
+}
+
+// revisionItem is the view model of a revision in templates.
+type revisionItem struct {
+	ID        string
+	Editor    string
+	Restored  bool
+	UpdatedAt *time.Time
+}
+
+func NewRevisionController(router *gin.Engine) IRevisionController {
+	instance := &revisionController{
+		noteUsecase:     usecase.NewNoteUsecase(),
+		revisionUsecase: usecase.NewRevisionUsecase(),
+	}
+	setupRevisionRoute(instance, router)
+	return instance
+}
+
+func setupRevisionRoute(api IRevisionController, router *gin.Engine) {
+	revisionGroup := router.Group("/note/:id/revisions")
+	revisionGroup.Use(secure.AuthMiddleware())
+	{
+		revisionGroup.GET("", api.getRevisions)
+		revisionGroup.GET("/:revisionId", api.getRevision)
+		revisionGroup.POST("/:revisionId/restore", api.postRestoreRevision)
+	}
+}
+
+func editorLabel(note *domain.Note, revision *domain.NoteRevision) string {
+	switch {
+	case revision.ShareID != nil && revision.EditorName != nil:
+		return *revision.EditorName + "（共有リンク " + *revision.ShareID + " 経由）"
+	case revision.ShareID != nil:
+		return "共有リンク " + *revision.ShareID
+	case revision.AuthorID != nil && *revision.AuthorID == note.AuthorID:
+		return "オーナー"
+	case revision.AuthorID != nil && revision.EditorName != nil:
+		return *revision.EditorName
+	case revision.AuthorID != nil:
+		return "ユーザー " + *revision.AuthorID
+	default:
+		return "記録開始時点"
+	}
+}
+
+// lastEditorLabel はメモを最後に変更した人を「名前（共有リンク 2 経由）」の形で返す。
+// 共有リンクの番号はサイドバーの一覧の順番。オーナー自身の変更なら空を返す
+func lastEditorLabel(note *domain.Note, latest *domain.NoteRevision, shares []*domain.SharingInfo) string {
+	switch {
+	case latest == nil:
+		return ""
+	case latest.ShareID == nil && (latest.AuthorID == nil || *latest.AuthorID == note.AuthorID):
+		return ""
+	case latest.ShareID == nil:
+		return editorLabel(note, latest)
+	}
+	name := "名前のない編集者"
+	if latest.EditorName != nil {
+		name = *latest.EditorName
+	}
+	link := "削除された共有リンク"
+	for index, share := range shares {
+		if share.ID == *latest.ShareID {
+			link = fmt.Sprintf("共有リンク %d", index+1)
+			break
+		}
+	}
+	return name + "（" + link + " 経由）"
+}
+
+// newRevisionItem は coEditors があれば最後の編集者に続けて「、」区切りで表示する
+func newRevisionItem(note *domain.Note, revision *domain.NoteRevision, coEditors []*domain.NoteRevisionEditor) revisionItem {
+	editor := editorLabel(note, revision)
+	for _, coEditor := range coEditors {
+		editor += "、" + editorLabel(note, &domain.NoteRevision{
+			AuthorID:   coEditor.AuthorID,
+			ShareID:    coEditor.ShareID,
+			EditorName: coEditor.EditorName,
+		})
+	}
+	return revisionItem{
+		ID:        revision.ID,
+		Editor:    editor,
+		Restored:  revision.RestoredFrom != nil,
+		UpdatedAt: revision.UpdatedAt,
+	}
+}
+
+// findOwnedNote はログインユーザーが所有するメモを取得する
+func (r *revisionController) findOwnedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	noteId := c.Param("id")
+
+	note, err := r.noteUsecase.Find(noteId)
+	if err != nil {
+		slog.Error("failed to get note", "noteId", noteId, "error", err)
+		return nil, false
+	}
+	if note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+func (r *revisionController) getRevisions(c *gin.Context) {
+	note, ok := r.findOwnedNote(c)
+	if !ok {
+		c.Redirect(http.StatusSeeOther, "/note")
+		return
+	}
+
+	revisions, err := r.revisionUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get revisions", "noteId", note.ID, "error", err)
+		revisions = []*domain.NoteRevision{}
+	}
+
+	coEditors, err := r.revisionUsecase.FindCoEditors(revisions)
+	if err != nil {
+		slog.Error("failed to get revision co-editors", "noteId", note.ID, "error", err)
+		coEditors = map[string][]*domain.NoteRevisionEditor{}
+	}
+
+	items := make([]revisionItem, 0, len(revisions))
+	for _, revision := range revisions {
+		items = append(items, newRevisionItem(note, revision, coEditors[revision.ID]))
+	}
+
+	c.HTML(http.StatusOK, "revisions.html", gin.H{
+		"title":     "変更履歴",
+		"note":      note,
+		"revisions": items,
+	})
+}
+
+func (r *revisionController) getRevision(c *gin.Context) {
+	note, ok := r.findOwnedNote(c)
+	if !ok {
+		c.Redirect(http.StatusSeeOther, "/note")
+		return
+	}
+
+	revisionId := c.Param("revisionId")
+	revision, err := r.revisionUsecase.Find(note, revisionId)
+	if err != nil {
+		slog.Error("failed to get revision", "noteId", note.ID, "revisionId", revisionId, "error", err)
+		showNotFoundPage(c)
+		return
+	}
+
+	// 比較対象: against=current なら現在のメモ、リビジョンIDならそのリビジョン、省略時は直前のリビジョン
+	var baseTitle, baseContent, baseLabel string
+	switch against := c.Query("against"); against {
+	case "current":
+		baseTitle, baseContent, baseLabel = note.Title, note.Content, "現在のメモ"
+	case "":
+		prev, err := r.revisionUsecase.FindPrevious(revision)
+		if err != nil {
+			slog.Error("failed to get previous revision", "revisionId", revision.ID, "error", err)
+		}
+		if prev != nil {
+			baseTitle, baseContent, baseLabel = prev.Title, prev.Content, "直前のリビジョン"
+		} else {
+			baseLabel = "空のメモ"
+		}
+	default:
+		other, err := r.revisionUsecase.Find(note, against)
+		if err != nil {
+			showNotFoundPage(c)
+			return
+		}
+		baseTitle, baseContent, baseLabel = other.Title, other.Content, "選択したリビジョン"
+	}
+
+	coEditors, err := r.revisionUsecase.FindCoEditors([]*domain.NoteRevision{revision})
+	if err != nil {
+		slog.Error("failed to get revision co-editors", "revisionId", revision.ID, "error", err)
+	}
+
+	c.HTML(http.StatusOK, "revision.html", gin.H{
+		"title":        "リビジョン",
+		"note":         note,
+		"revision":     revision,
+		"item":         newRevisionItem(note, revision, coEditors[revision.ID]),
+		"titleChanged": baseTitle != revision.Title,
+		"baseTitle":    baseTitle,
+		"baseLabel":    baseLabel,
+		"diff":         usecase.DiffLines(baseContent, revision.Content),
+	})
+}
+
+func (r *revisionController) postRestoreRevision(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	note, ok := r.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	revisionId := c.Param("revisionId")
+	revision, err := r.revisionUsecase.Find(note, revisionId)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+		return
+	}
+
+	if _, err := r.revisionUsecase.Restore(note, revision, userEditor(user)); err != nil {
+		slog.Error("failed to restore revision", "noteId", note.ID, "revisionId", revisionId, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore revision"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -5,9 +5,19 @@ import (
 )
 
 type controller struct {
-	user  IUserController
-	note  INoteController
-	share IShareController
+	user       IUserController
+	note       INoteController
+	share      IShareController
+	revision   IRevisionController
+	tag        ITagController
+	template   ITemplateController
+	folder     IFolderController
+	trash      ITrashController
+	attachment IAttachmentController
+	member     IMemberController
+	collab     ICollabController
+	api        IAPIController
+	openAPI    IOpenAPIController
 }
 
 func showNotFoundPage(c *gin.Context) {
@@ -16,15 +26,40 @@ func showNotFoundPage(c *gin.Context) {
 	})
 }
 
+// showGonePage は削除済み（ゴミ箱内）のメモへの共有リンクに対して表示する
+func showGonePage(c *gin.Context) {
+	c.HTML(410, "gone.html", gin.H{
+		"title": "Gone",
+	})
+}
+
+// showExpiredPage は期限切れか閲覧回数の上限に達した共有リンクに対して表示する
+func showExpiredPage(c *gin.Context) {
+	c.HTML(410, "expired.html", gin.H{
+		"title": "Link Expired",
+	})
+}
+
 func setNoRoute(router *gin.Engine) {
 	router.NoRoute(showNotFoundPage)
 }
 
 func NewController(router *gin.Engine) *controller {
 	setNoRoute(router)
-	return &controller{
-		user:  NewUserController(router),
-		note:  NewNoteController(router),
-		share: NewShareController(router),
+	c := &controller{
+		user:       NewUserController(router),
+		note:       NewNoteController(router),
+		share:      NewShareController(router),
+		revision:   NewRevisionController(router),
+		tag:        NewTagController(router),
+		template:   NewTemplateController(router),
+		folder:     NewFolderController(router),
+		trash:      NewTrashController(router),
+		attachment: NewAttachmentController(router),
+		member:     NewMemberController(router),
+		collab:     NewCollabController(router),
+		api:        NewAPIController(router),
+		openAPI:    NewOpenAPIController(router),
 	}
+	return c
 }
@@ -1,42 +1,163 @@
 package controller
 
 import (
+	"errors"
+	"html/template"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/ToshihiroOgino/elib/domain"
 	"github.com/ToshihiroOgino/elib/secure"
 	"github.com/ToshihiroOgino/elib/usecase"
 	"github.com/gin-gonic/gin"
 )
 
+// 閲覧者への更新通知の接続を維持するための送信間隔
+const sharedNoteHeartbeatInterval = 30 * time.Second
+
 type IShareController interface {
 	getSharedNote(c *gin.Context)
 	postShareNote(c *gin.Context)
 	deleteShare(c *gin.Context)
 	putEditSharedNote(c *gin.Context)
+	getSharedNoteEvents(c *gin.Context)
+	postUnlockShare(c *gin.Context)
+	putSharePassword(c *gin.Context)
+	getShareAccess(c *gin.Context)
+	putShareEditorName(c *gin.Context)
 }
 
 type shareController struct {
-	shareUsecase usecase.IShareUsecase
-	noteUsecase  usecase.INoteUsecase
-	userUsecase  usecase.IUserUsecase
+	shareUsecase      usecase.IShareUsecase
+	noteUsecase       usecase.INoteUsecase
+	userUsecase       usecase.IUserUsecase
+	attachmentUsecase usecase.IAttachmentUsecase
+	accessUsecase     usecase.IShareAccessUsecase
 }
 
 type shareRequest struct {
 	NoteID   string `json:"noteId"`
 	Editable bool   `json:"editable"`
+	// Password は空ならパスワードなし
+	Password string `json:"password"`
+	shareLimits
+}
+
+// sharePasswordRequest は共有リンクのパスワードの変更。空ならパスワードを外す
+type sharePasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// shareEditorNameRequest は共有リンクの編集者の表示名
+type shareEditorNameRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// shareUnlockForm はパスワード付きの共有リンクのパスワード入力
+type shareUnlockForm struct {
+	Password string `form:"password" binding:"required"`
 }
 
+// maxShareEditorNameBytes は共有リンクの編集者の表示名の上限（バイト数）。文字数は usecase で確認する
+const maxShareEditorNameBytes = 200
+
+// shareUnlockTTL はパスワードを入力してから再入力が必要になるまでの時間
+const shareUnlockTTL = time.Hour
+
+// shareViewTTL は閲覧回数を数えたページから、添付ファイルや更新通知を取得できる時間
+const shareViewTTL = 30 * time.Minute
+
+// shareLimits は共有リンクの有効期限・閲覧回数・編集できる期間。0 は制限なし
+type shareLimits struct {
+	ExpiresInHours   int `json:"expiresInHours"`
+	MaxViews         int `json:"maxViews"`
+	EditableForHours int `json:"editableForHours"`
+}
+
+// shareLimitMaxHours は有効期限と編集できる期間の上限（1年）
+const shareLimitMaxHours = 365 * 24
+
+// options は制限を作成日時からの日時に変換する
+func (l shareLimits) options(editable bool) (usecase.ShareOptions, error) {
+	if l.ExpiresInHours < 0 || l.ExpiresInHours > shareLimitMaxHours ||
+		l.EditableForHours < 0 || l.EditableForHours > shareLimitMaxHours ||
+		l.MaxViews < 0 || l.MaxViews > math.MaxInt32 {
+		return usecase.ShareOptions{}, usecase.ErrInvalidShareOptions
+	}
+	now := time.Now()
+	options := usecase.ShareOptions{Editable: editable}
+	if l.ExpiresInHours > 0 {
+		expiresAt := now.Add(time.Duration(l.ExpiresInHours) * time.Hour)
+		options.ExpiresAt = &expiresAt
+	}
+	if l.MaxViews > 0 {
+		maxViews := int32(l.MaxViews)
+		options.MaxViews = &maxViews
+	}
+	if l.EditableForHours > 0 {
+		editableUntil := now.Add(time.Duration(l.EditableForHours) * time.Hour)
+		options.EditableUntil = &editableUntil
+	}
+	return options, nil
+}
+
+// shareItem は共有リンク一覧表示用のビューモデル
+type shareItem struct {
+	*domain.SharingInfo
+	// Expired は期限切れか閲覧回数の上限に達したか
+	Expired bool
+	// ReadOnlyNow は編集可能な期間を過ぎて閲覧のみになったか
+	ReadOnlyNow bool
+	// PasswordProtected はパスワードが設定されているか
+	PasswordProtected bool
+	// Access はアクセス記録の集計
+	Access *usecase.ShareAccessStats
+}
+
+func newShareItems(shares []*domain.SharingInfo, accessStats map[string]*usecase.ShareAccessStats) []shareItem {
+	now := time.Now()
+	items := make([]shareItem, 0, len(shares))
+	for _, share := range shares {
+		items = append(items, shareItem{
+			SharingInfo:       share,
+			Expired:           usecase.ShareExpired(share, now),
+			ReadOnlyNow:       share.Editable && !usecase.ShareEditable(share, now),
+			PasswordProtected: usecase.SharePasswordProtected(share),
+			Access:            accessStats[share.ID],
+		})
+	}
+	return items
+}
+
+// sharedNoteUpdate は共有メモの閲覧者に送る更新イベント
+type sharedNoteUpdate struct {
+	Title     string `json:"title"`
+	Content   string `json:"content"`
+	HTML      string `json:"html,omitempty"`
+	Format    string `json:"format"`
+	Version   int32  `json:"version"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// noteEditRequest は saveNoteRequest と同じく、本文を全文か差分 patch で送る
 type noteEditRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title   string          `json:"title"`
+	Content string          `json:"content"`
+	Patch   *usecase.TextOp `json:"patch"`
+	Version int32           `json:"version" binding:"required"`
 }
 
 func NewShareController(router *gin.Engine) IShareController {
 	instance := &shareController{
-		shareUsecase: usecase.NewShareUsecase(),
-		noteUsecase:  usecase.NewNoteUsecase(),
-		userUsecase:  usecase.NewUserUsecase(),
+		shareUsecase:      usecase.NewShareUsecase(),
+		noteUsecase:       usecase.NewNoteUsecase(),
+		userUsecase:       usecase.NewUserUsecase(),
+		attachmentUsecase: usecase.NewAttachmentUsecase(),
+		accessUsecase:     usecase.NewShareAccessUsecase(),
 	}
 	setupShareRoute(instance, router)
 	return instance
@@ -46,31 +167,180 @@ func setupShareRoute(i IShareController, router *gin.Engine) {
 	shareGroup := router.Group("/share")
 	shareGroup.GET("/:id", i.getSharedNote)
 	shareGroup.PUT("/:id", i.putEditSharedNote)
+	shareGroup.GET("/:id/events", i.getSharedNoteEvents)
+	shareGroup.POST("/:id/unlock", i.postUnlockShare)
+	shareGroup.PUT("/:id/name", i.putShareEditorName)
 	shareGroup.Use(secure.AuthMiddleware())
 	{
 		shareGroup.POST("", i.postShareNote)
 		shareGroup.DELETE("/:id", i.deleteShare)
+		shareGroup.PUT("/:id/password", i.putSharePassword)
+		shareGroup.GET("/:id/access", i.getShareAccess)
+	}
+}
+
+// validShareEditorName は制御文字を除き、空白を詰めた表示名を返す
+func validShareEditorName(value string) (string, bool) {
+	value, valid := secure.ValidateTextInput(value, maxShareEditorNameBytes)
+	if !valid {
+		return "", false
+	}
+	name, err := usecase.NormalizeEditorName(value)
+	return name, err == nil
+}
+
+// shareEditorName は共有リンクの編集者が Cookie に記憶した表示名を返す。未入力か不正なら空
+func shareEditorName(c *gin.Context) string {
+	value, err := secure.GetCookieSecure(c, secure.ShareEditorNameCookieKey)
+	if err != nil {
+		return ""
+	}
+	name, _ := validShareEditorName(value)
+	return name
+}
+
+// shareEditor は共有リンクから編集した人を返す。ログイン中ならそのアカウントを、
+// そうでなければ Cookie に記憶した表示名を記録する
+func shareEditor(c *gin.Context, share *domain.SharingInfo) usecase.NoteEditor {
+	if user, err := secure.GetLoggedInUser(c); err == nil {
+		editor := userEditor(user)
+		editor.ShareID = share.ID
+		return editor
+	}
+	return usecase.NoteEditor{ShareID: share.ID, DisplayName: shareEditorName(c)}
+}
+
+// recordShareAccess は共有リンクの閲覧・編集を記録する。書き込みは非同期で、
+// ログイン中のユーザーが開いた場合はそのユーザーも記録する
+func (i *shareController) recordShareAccess(c *gin.Context, share *domain.SharingInfo, action string) {
+	// 未ログインなら user は nil
+	user, _ := secure.GetLoggedInUser(c)
+	i.accessUsecase.Record(usecase.ShareAccess{
+		Share:     share,
+		Action:    action,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		User:      user,
+	})
+}
+
+// shareUnlocked はパスワード付きの共有リンクが、このリクエストの Cookie で解除済みかを返す
+func shareUnlocked(c *gin.Context, share *domain.SharingInfo) bool {
+	if !usecase.SharePasswordProtected(share) {
+		return true
+	}
+	token, err := secure.GetCookieSecure(c, secure.ShareUnlockCookieKey)
+	if err != nil {
+		return false
+	}
+	return secure.ValidateShareToken(token, share.ID, usecase.ShareUnlockKey(share)) == nil
+}
+
+// sharePageFinder は共有リンクのページから送られるリクエストで共有リンクを取得する関数を返す。
+// 閲覧回数を数えたページからなら、閲覧回数を使い切った後も共有リンクを返す
+func sharePageFinder(c *gin.Context, shares usecase.IShareUsecase) func(shareId string) (*domain.SharingInfo, error) {
+	token, err := secure.GetCookieSecure(c, secure.ShareViewCookieKey)
+	if err == nil && secure.ValidateShareViewToken(token, c.Param("id")) == nil {
+		return shares.FindViewed
 	}
+	return shares.Find
+}
+
+// setShareViewCookie は閲覧回数を数えたページに、そのページからのリクエストを通す Cookie を発行する
+func setShareViewCookie(c *gin.Context, share *domain.SharingInfo) {
+	// 閲覧回数に上限がなければ、閲覧回数で使えなくなることはない
+	if share.MaxViews == nil {
+		return
+	}
+	token, err := secure.GenerateShareViewToken(share.ID, shareViewTTL)
+	if err != nil {
+		// 閲覧は数えてあるので、ページは表示する
+		slog.Error("failed to generate share view token", "shareId", share.ID, "error", err)
+		return
+	}
+	secure.SetShareViewCookie(c, share.ID, token, shareViewTTL)
+}
+
+// showShareUnlockPage はパスワード付きの共有リンクのパスワード入力画面を表示する
+func showShareUnlockPage(c *gin.Context, status int, share *domain.SharingInfo, message string) {
+	c.HTML(status, "share_unlock.html", gin.H{
+		"title":   "Shared Note",
+		"shareId": share.ID,
+		"Error":   message,
+	})
 }
 
 func (i *shareController) getSharedNote(c *gin.Context) {
 	shareId := c.Param("id")
 	share, err := i.shareUsecase.Find(shareId)
+	if errors.Is(err, usecase.ErrShareExpired) {
+		showExpiredPage(c)
+		return
+	}
 	if err != nil || share == nil {
 		showNotFoundPage(c)
 		return
 	}
+	if !shareUnlocked(c, share) {
+		showShareUnlockPage(c, http.StatusUnauthorized, share, "")
+		return
+	}
 
 	note, err := i.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		showGonePage(c)
+		return
+	}
 	if err != nil || note == nil {
 		showNotFoundPage(c)
 		return
 	}
 
+	// 閲覧回数はページを開いたときだけ数える
+	if err := i.shareUsecase.RecordView(share); err != nil {
+		if errors.Is(err, usecase.ErrShareExpired) {
+			showExpiredPage(c)
+			return
+		}
+		slog.Error("failed to record share view", "shareId", share.ID, "error", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	setShareViewCookie(c, share)
+	i.recordShareAccess(c, share, usecase.ShareAccessView)
+
+	// 閲覧のみの共有では Markdown 形式のメモをサーバー側で HTML に変換する
+	var rendered template.HTML
+	if !share.Editable && note.Format == usecase.NoteFormatMarkdown {
+		rendered = secure.RenderMarkdown(note.Content)
+	}
+
+	attachments, err := i.attachmentUsecase.FindByNote(note)
+	if err != nil {
+		slog.Error("failed to get attachments", "noteId", note.ID, "error", err)
+		attachments = []*domain.Attachment{}
+	}
+
+	remainingViews := int32(0)
+	if share.MaxViews != nil {
+		remainingViews = *share.MaxViews - share.ViewCount
+	}
+
+	// 編集可能なリンクでは、ログインしていない編集者に表示名を入力してもらう
+	editor := usecase.NoteEditor{}
+	if share.Editable {
+		editor = shareEditor(c, share)
+	}
+
 	c.HTML(http.StatusOK, "shared_note.html", gin.H{
-		"title": "Shared Note",
-		"note":  note,
-		"share": share,
+		"title":          "Shared Note",
+		"note":           note,
+		"share":          share,
+		"rendered":       rendered,
+		"attachments":    newAttachmentItems(attachments),
+		"remainingViews": remainingViews,
+		"editorName":     editor.DisplayName,
+		"editorLoggedIn": editor.UserID != "",
 	})
 }
 
@@ -93,37 +363,56 @@ func (i *shareController) postShareNote(c *gin.Context) {
 		return
 	}
 
-	sharingInfo, err := i.shareUsecase.ShareNote(note, req.Editable)
+	options, err := req.options(req.Editable)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share limits."})
+		return
+	}
+	options.Password = req.Password
+	sharingInfo, err := i.shareUsecase.ShareNote(note, options)
+	if errors.Is(err, usecase.ErrInvalidShareOptions) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share limits."})
+		return
+	}
 	if err != nil {
 		slog.Error("failed to share note", "noteId", note.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share note."})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"shareId": sharingInfo.ID})
+	// パスワードのハッシュを返さないよう API と同じ表現で返す
+	c.JSON(http.StatusOK, gin.H{"shareId": sharingInfo.ID, "share": newAPIShare(sharingInfo)})
 }
 
-func (i *shareController) deleteShare(c *gin.Context) {
-	shareId := c.Param("id")
-	share, err := i.shareUsecase.Find(shareId)
+// findOwnShare はログイン中のユーザーが所有するメモの共有リンクを取得する。期限切れのリンクも対象
+func (i *shareController) findOwnShare(c *gin.Context) (*domain.SharingInfo, bool) {
+	share, err := i.shareUsecase.FindIncludingExpired(c.Param("id"))
 	if err != nil || share == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found."})
-		return
+		return nil, false
 	}
 
 	note, err := i.noteUsecase.Find(share.NoteID)
 	if err != nil || note == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found."})
-		return
+		return nil, false
 	}
 
 	user := secure.GetSessionUser(c)
 	if user == nil || user.ID != note.AuthorID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to delete this share."})
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to manage this share."})
+		return nil, false
+	}
+	return share, true
+}
+
+func (i *shareController) deleteShare(c *gin.Context) {
+	share, ok := i.findOwnShare(c)
+	if !ok {
 		return
 	}
 
 	if err := i.shareUsecase.Delete(share); err != nil {
-		slog.Error("failed to delete share", "shareId", shareId, "error", err)
+		slog.Error("failed to delete share", "shareId", share.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete share."})
 		return
 	}
@@ -131,21 +420,161 @@ func (i *shareController) deleteShare(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Share deleted successfully."})
 }
 
+// putSharePassword は共有リンクのパスワードを設定・変更・解除する。
+// 変更前のパスワードで解除していた閲覧者は再入力が必要になる
+func (i *shareController) putSharePassword(c *gin.Context) {
+	var req sharePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data."})
+		return
+	}
+	share, ok := i.findOwnShare(c)
+	if !ok {
+		return
+	}
+
+	err := i.shareUsecase.SetPassword(share, req.Password)
+	if errors.Is(err, usecase.ErrInvalidShareOptions) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid password."})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to set share password", "shareId", share.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set password."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message":           "Share password updated successfully.",
+		"passwordProtected": usecase.SharePasswordProtected(share),
+	})
+}
+
+// putShareEditorName は共有リンクの編集者の表示名を Cookie に記憶する。
+// 表示名はすべての共有リンクで使われ、保存した変更とともに記録される
+func (i *shareController) putShareEditorName(c *gin.Context) {
+	share, err := sharePageFinder(c, i.shareUsecase)(c.Param("id"))
+	if errors.Is(err, usecase.ErrShareExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": "Share link has expired."})
+		return
+	}
+	if err != nil || share == nil || !share.Editable {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found."})
+		return
+	}
+	if !shareUnlocked(c, share) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password required."})
+		return
+	}
+
+	var req shareEditorNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data."})
+		return
+	}
+	name, valid := validShareEditorName(req.Name)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid name."})
+		return
+	}
+	secure.SetShareEditorNameCookie(c, name)
+	c.JSON(http.StatusOK, gin.H{"message": "Editor name saved.", "name": name})
+}
+
+// getShareAccess は共有リンクのアクセスの集計と最近のアクセスを返す
+func (i *shareController) getShareAccess(c *gin.Context) {
+	share, ok := i.findOwnShare(c)
+	if !ok {
+		return
+	}
+
+	stats, err := i.accessUsecase.StatsByShares([]*domain.SharingInfo{share})
+	if err != nil {
+		slog.Error("failed to get share access stats", "shareId", share.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get access log."})
+		return
+	}
+	accesses, err := i.accessUsecase.Recent(share, usecase.ShareAccessRecentLimit)
+	if err != nil {
+		slog.Error("failed to get share accesses", "shareId", share.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get access log."})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"stats": stats[share.ID], "accesses": accesses})
+}
+
+// postUnlockShare はパスワードを確認し、その共有リンクのページでだけ有効な Cookie を発行する
+func (i *shareController) postUnlockShare(c *gin.Context) {
+	share, err := i.shareUsecase.Find(c.Param("id"))
+	if errors.Is(err, usecase.ErrShareExpired) {
+		showExpiredPage(c)
+		return
+	}
+	if err != nil || share == nil {
+		showNotFoundPage(c)
+		return
+	}
+
+	var form shareUnlockForm
+	if err := c.ShouldBind(&form); err != nil {
+		showShareUnlockPage(c, http.StatusBadRequest, share, "パスワードを入力してください。")
+		return
+	}
+	err = i.shareUsecase.Unlock(share, form.Password)
+	if errors.Is(err, usecase.ErrTooManyUnlockAttempts) {
+		c.Header("Retry-After", strconv.Itoa(int(usecase.ShareUnlockLockout.Seconds())))
+		showShareUnlockPage(c, http.StatusTooManyRequests, share,
+			"パスワードの誤りが続いたため、しばらく入力できません。時間をおいてからお試しください。")
+		return
+	}
+	if errors.Is(err, usecase.ErrInvalidSharePassword) {
+		showShareUnlockPage(c, http.StatusUnauthorized, share, "パスワードが違います。")
+		return
+	}
+	if err != nil {
+		slog.Error("failed to unlock share", "shareId", share.ID, "error", err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if usecase.SharePasswordProtected(share) {
+		token, err := secure.GenerateShareToken(share.ID, usecase.ShareUnlockKey(share), shareUnlockTTL)
+		if err != nil {
+			slog.Error("failed to generate share token", "shareId", share.ID, "error", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		secure.SetShareUnlockCookie(c, share.ID, token, shareUnlockTTL)
+	}
+	c.Redirect(http.StatusSeeOther, "/share/"+share.ID)
+}
+
 func (i *shareController) putEditSharedNote(c *gin.Context) {
 	shareId := c.Param("id")
-	share, err := i.shareUsecase.Find(shareId)
+	share, err := sharePageFinder(c, i.shareUsecase)(shareId)
+	if errors.Is(err, usecase.ErrShareExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": "Share link has expired."})
+		return
+	}
 	if err != nil || share == nil {
-		showNotFoundPage(c)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found."})
+		return
+	}
+	if !shareUnlocked(c, share) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password required."})
 		return
 	}
 	if !share.Editable {
-		showNotFoundPage(c)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share not found."})
 		return
 	}
 
 	note, err := i.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		c.JSON(http.StatusGone, gin.H{"error": "Note is in the trash."})
+		return
+	}
 	if err != nil || note == nil {
-		showNotFoundPage(c)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found."})
 		return
 	}
 
@@ -154,14 +583,118 @@ func (i *shareController) putEditSharedNote(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data."})
 		return
 	}
-	note.Title = req.Title
-	note.Content = req.Content
+	title, titleValid := secure.ValidateTextInput(req.Title, maxNoteTitleLength)
+	content, contentValid := "", true
+	if req.Patch == nil {
+		content, contentValid = secure.ValidateTextInput(req.Content, maxNoteContentLength)
+	}
+	if !titleValid || !contentValid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request data."})
+		return
+	}
+	note.Title = title
+	note.Content = content
+	note.Version = req.Version
 
-	note, err = i.noteUsecase.UpdateNote(note)
+	updated, err := saveNoteContent(i.noteUsecase, note, req.Patch, shareEditor(c, share))
+	if errors.Is(err, usecase.ErrNoteConflict) {
+		if current, findErr := i.noteUsecase.Find(share.NoteID); findErr == nil {
+			c.JSON(http.StatusConflict, noteConflictResponse(current))
+			return
+		}
+	}
+	if isInvalidNotePatch(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patch."})
+		return
+	}
 	if err != nil {
 		slog.Error("failed to update note", "noteId", note.ID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note."})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Note updated successfully."})
+	i.recordShareAccess(c, share, usecase.ShareAccessEdit)
+	c.JSON(http.StatusOK, gin.H{"message": "Note updated successfully.", "version": updated.Version})
+}
+
+// getSharedNoteEvents はメモが保存されるたびに最新の内容を Server-Sent Events で送る
+func (i *shareController) getSharedNoteEvents(c *gin.Context) {
+	findShare := sharePageFinder(c, i.shareUsecase)
+	share, err := findShare(c.Param("id"))
+	if errors.Is(err, usecase.ErrShareExpired) {
+		c.JSON(http.StatusGone, gin.H{"error": "share link has expired"})
+		return
+	}
+	if err != nil || share == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "share not found"})
+		return
+	}
+	if !shareUnlocked(c, share) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "password required"})
+		return
+	}
+	note, err := i.noteUsecase.Find(share.NoteID)
+	if errors.Is(err, usecase.ErrNoteInTrash) {
+		c.JSON(http.StatusGone, gin.H{"error": "note is in the trash"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	unlockKey := usecase.ShareUnlockKey(share)
+	events, unsubscribe := i.noteUsecase.Subscribe(note.ID)
+	defer unsubscribe()
+	heartbeat := time.NewTicker(sharedNoteHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	// リバースプロキシでバッファリングされないようにする
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			// 接続を維持するためのコメント行
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			if event.Type == usecase.NoteEventDeleted {
+				c.SSEvent("deleted", gin.H{})
+				return false
+			}
+			// 共有リンクが削除されたか期限切れになった後や、パスワードが変わった後は送らない
+			current, err := findShare(share.ID)
+			if err != nil || current == nil || usecase.ShareUnlockKey(current) != unlockKey {
+				return false
+			}
+			if event.Type != usecase.NoteEventUpdated {
+				return true
+			}
+			// 編集可能から閲覧のみに変わっていれば、閲覧のみの内容を送る
+			c.SSEvent("update", newSharedNoteUpdate(current, event.Note))
+			return true
+		}
+	})
+}
+
+func newSharedNoteUpdate(share *domain.SharingInfo, note *domain.Note) sharedNoteUpdate {
+	update := sharedNoteUpdate{
+		Title:   note.Title,
+		Content: note.Content,
+		Format:  note.Format,
+		Version: note.Version,
+	}
+	if note.UpdatedAt != nil {
+		update.UpdatedAt = note.UpdatedAt.UTC().Format("2006-01-02T15:04:05")
+	}
+	// 閲覧ページと同じく、閲覧のみの共有では Markdown を HTML に変換して送る
+	if !share.Editable && note.Format == usecase.NoteFormatMarkdown {
+		update.HTML = string(secure.RenderMarkdown(note.Content))
+	}
+	return update
 }
@@ -0,0 +1,243 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ITagController interface {
+	getTags(c *gin.Context)
+	putRenameTag(c *gin.Context)
+	postMergeTags(c *gin.Context)
+	deleteTag(c *gin.Context)
+	postAddNoteTag(c *gin.Context)
+	deleteNoteTag(c *gin.Context)
+}
+
+type tagController struct {
+	tagUsecase  usecase.ITagUsecase
+	noteUsecase usecase.INoteUsecase
+}
+
+type tagNameRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type mergeTagsRequest struct {
+	TargetID  string   `json:"targetId" binding:"required"`
+	SourceIDs []string `json:"sourceIds" binding:"required"`
+}
+
+// tagCloudItem はタグクラウド表示用のビューモデル
+type tagCloudItem struct {
+	ID        string
+	Name      string
+	NoteCount int64
+	Size      int
+	Active    bool
+}
+
+func NewTagController(router *gin.Engine) ITagController {
+	instance := &tagController{
+		tagUsecase:  usecase.NewTagUsecase(),
+		noteUsecase: usecase.NewNoteUsecase(),
+	}
+	setupTagRoute(instance, router)
+	return instance
+}
+
+func setupTagRoute(api ITagController, router *gin.Engine) {
+	tagGroup := router.Group("/tag")
+	tagGroup.Use(secure.AuthMiddleware())
+	{
+		tagGroup.GET("", api.getTags)
+		tagGroup.POST("/merge", api.postMergeTags)
+		tagGroup.PUT("/:id", api.putRenameTag)
+		tagGroup.DELETE("/:id", api.deleteTag)
+	}
+
+	noteTagGroup := router.Group("/note/:id/tags")
+	noteTagGroup.Use(secure.AuthMiddleware())
+	{
+		noteTagGroup.POST("", api.postAddNoteTag)
+		noteTagGroup.DELETE("/:tagId", api.deleteNoteTag)
+	}
+}
+
+// newTagCloud はメモ数に応じて1〜5段階の大きさを割り当てる
+func newTagCloud(tags []*usecase.TagSummary, activeTagID string) []tagCloudItem {
+	var max int64 = 1
+	for _, tag := range tags {
+		if tag.NoteCount > max {
+			max = tag.NoteCount
+		}
+	}
+	items := make([]tagCloudItem, 0, len(tags))
+	for _, tag := range tags {
+		items = append(items, tagCloudItem{
+			ID:        tag.ID,
+			Name:      tag.Name,
+			NoteCount: tag.NoteCount,
+			Size:      1 + int(tag.NoteCount*4/max),
+			Active:    tag.ID == activeTagID,
+		})
+	}
+	return items
+}
+
+// findOwnedTag はログインユーザーが所有するタグを取得する
+func (t *tagController) findOwnedTag(c *gin.Context, tagId string) (*domain.Tag, bool) {
+	user := secure.GetSessionUser(c)
+	tag, err := t.tagUsecase.Find(tagId)
+	if err != nil || tag.UserID != user.ID {
+		return nil, false
+	}
+	return tag, true
+}
+
+// findOwnedNote はログインユーザーが所有するメモを取得する
+func (t *tagController) findOwnedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := t.noteUsecase.Find(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+func tagErrorStatus(err error) int {
+	if errors.Is(err, usecase.ErrInvalidTagName) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+func (t *tagController) getTags(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	tags, err := t.tagUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+func (t *tagController) putRenameTag(c *gin.Context) {
+	tag, ok := t.findOwnedTag(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+
+	var req tagNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	renamed, err := t.tagUsecase.Rename(tag, req.Name)
+	if err != nil {
+		slog.Error("failed to rename tag", "tagId", tag.ID, "error", err)
+		c.JSON(tagErrorStatus(err), gin.H{"error": "failed to rename tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "tag": renamed})
+}
+
+func (t *tagController) postMergeTags(c *gin.Context) {
+	var req mergeTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	target, ok := t.findOwnedTag(c, req.TargetID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+	sources := make([]*domain.Tag, 0, len(req.SourceIDs))
+	for _, sourceId := range req.SourceIDs {
+		source, ok := t.findOwnedTag(c, sourceId)
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+			return
+		}
+		sources = append(sources, source)
+	}
+
+	if err := t.tagUsecase.Merge(target, sources); err != nil {
+		slog.Error("failed to merge tags", "targetId", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to merge tags"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "tag": target})
+}
+
+func (t *tagController) deleteTag(c *gin.Context) {
+	tag, ok := t.findOwnedTag(c, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+	if err := t.tagUsecase.Delete(tag); err != nil {
+		slog.Error("failed to delete tag", "tagId", tag.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (t *tagController) postAddNoteTag(c *gin.Context) {
+	note, ok := t.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	var req tagNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	name, valid := secure.ValidateTextInput(req.Name, 200)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tag name"})
+		return
+	}
+
+	tag, err := t.tagUsecase.AddToNote(note, name)
+	if err != nil {
+		slog.Error("failed to add tag", "noteId", note.ID, "error", err)
+		c.JSON(tagErrorStatus(err), gin.H{"error": "failed to add tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "tag": tag})
+}
+
+func (t *tagController) deleteNoteTag(c *gin.Context) {
+	note, ok := t.findOwnedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+	tag, ok := t.findOwnedTag(c, c.Param("tagId"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "tag not found"})
+		return
+	}
+
+	if err := t.tagUsecase.RemoveFromNote(note, tag); err != nil {
+		slog.Error("failed to remove tag", "noteId", note.ID, "tagId", tag.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
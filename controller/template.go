@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ITemplateController interface {
+	getTemplates(c *gin.Context)
+	postSaveTemplate(c *gin.Context)
+	putRenameTemplate(c *gin.Context)
+	deleteTemplate(c *gin.Context)
+}
+
+type templateController struct {
+	templateUsecase usecase.ITemplateUsecase
+	noteUsecase     usecase.INoteUsecase
+}
+
+type saveTemplateRequest struct {
+	NoteID string `json:"noteId" binding:"required"`
+	Name   string `json:"name" binding:"required"`
+}
+
+type templateNameRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func NewTemplateController(router *gin.Engine) ITemplateController {
+	instance := &templateController{
+		templateUsecase: usecase.NewTemplateUsecase(),
+		noteUsecase:     usecase.NewNoteUsecase(),
+	}
+	setupTemplateRoute(instance, router)
+	return instance
+}
+
+func setupTemplateRoute(api ITemplateController, router *gin.Engine) {
+	templateGroup := router.Group("/template")
+	templateGroup.Use(secure.AuthMiddleware())
+	{
+		templateGroup.GET("", api.getTemplates)
+		templateGroup.POST("", api.postSaveTemplate)
+		templateGroup.PUT("/:id", api.putRenameTemplate)
+		templateGroup.DELETE("/:id", api.deleteTemplate)
+	}
+}
+
+// findOwnedTemplate はログインユーザーが所有するテンプレートを取得する
+func findOwnedTemplate(templateUsecase usecase.ITemplateUsecase, user *domain.User, templateId string) (*domain.NoteTemplate, bool) {
+	template, err := templateUsecase.Find(templateId)
+	if err != nil || template.UserID != user.ID {
+		return nil, false
+	}
+	return template, true
+}
+
+func templateErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidTemplateName):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrTemplateNameTaken):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func (t *templateController) getTemplates(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	templates, err := t.templateUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get templates", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get templates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// postSaveTemplate はメモの現在の内容を同名のテンプレートとして保存する
+func (t *templateController) postSaveTemplate(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	var req saveTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	note, err := t.noteUsecase.Find(req.NoteID)
+	if err != nil || note.AuthorID != user.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	template, err := t.templateUsecase.SaveFromNote(note, req.Name)
+	if err != nil {
+		slog.Error("failed to save template", "noteId", note.ID, "error", err)
+		c.JSON(templateErrorStatus(err), gin.H{"error": "failed to save template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "template": template})
+}
+
+func (t *templateController) putRenameTemplate(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	template, ok := findOwnedTemplate(t.templateUsecase, user, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+
+	var req templateNameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+
+	renamed, err := t.templateUsecase.Rename(template, req.Name)
+	if errors.Is(err, usecase.ErrTemplateNameTaken) {
+		c.JSON(http.StatusConflict, gin.H{"error": "template name is already used"})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to rename template", "templateId", template.ID, "error", err)
+		c.JSON(templateErrorStatus(err), gin.H{"error": "failed to rename template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "template": renamed})
+}
+
+func (t *templateController) deleteTemplate(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	template, ok := findOwnedTemplate(t.templateUsecase, user, c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	}
+	if err := t.templateUsecase.Delete(template); err != nil {
+		slog.Error("failed to delete template", "templateId", template.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete template"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
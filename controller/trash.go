@@ -0,0 +1,138 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+type ITrashController interface {
+	getTrash(c *gin.Context)
+	postRestoreNote(c *gin.Context)
+	deleteTrashedNote(c *gin.Context)
+	deleteEmptyTrash(c *gin.Context)
+}
+
+type trashController struct {
+	noteUsecase usecase.INoteUsecase
+}
+
+// trashItem はゴミ箱画面のビューモデル
+type trashItem struct {
+	ID        string
+	Title     string
+	DeletedAt time.Time
+	PurgeAt   *time.Time
+}
+
+func NewTrashController(router *gin.Engine) ITrashController {
+	instance := &trashController{
+		noteUsecase: usecase.NewNoteUsecase(),
+	}
+	setupTrashRoute(instance, router)
+	return instance
+}
+
+func setupTrashRoute(api ITrashController, router *gin.Engine) {
+	trashGroup := router.Group("/note/trash")
+	trashGroup.Use(secure.AuthMiddleware())
+	{
+		trashGroup.GET("", api.getTrash)
+		trashGroup.DELETE("", api.deleteEmptyTrash)
+		trashGroup.POST("/:id/restore", api.postRestoreNote)
+		trashGroup.DELETE("/:id", api.deleteTrashedNote)
+	}
+}
+
+func newTrashItem(note *domain.Note, retention time.Duration) trashItem {
+	item := trashItem{
+		ID:        note.ID,
+		Title:     note.Title,
+		DeletedAt: note.DeletedAt.Time,
+	}
+	if retention > 0 {
+		purgeAt := note.DeletedAt.Time.Add(retention)
+		item.PurgeAt = &purgeAt
+	}
+	return item
+}
+
+// findOwnedTrashedNote はログインユーザーのゴミ箱にあるメモを取得する
+func (t *trashController) findOwnedTrashedNote(c *gin.Context) (*domain.Note, bool) {
+	user := secure.GetSessionUser(c)
+	note, err := t.noteUsecase.FindTrashed(c.Param("id"))
+	if err != nil || note.AuthorID != user.ID {
+		return nil, false
+	}
+	return note, true
+}
+
+func (t *trashController) getTrash(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	notes, err := t.noteUsecase.FindTrashByUserID(user.ID)
+	if err != nil {
+		slog.Error("failed to get trashed notes", "error", err)
+		notes = []*domain.Note{}
+	}
+
+	retention := env.Get().TrashRetention
+	items := make([]trashItem, 0, len(notes))
+	for _, note := range notes {
+		items = append(items, newTrashItem(note, retention))
+	}
+
+	c.HTML(http.StatusOK, "trash.html", gin.H{
+		"title":         "ゴミ箱",
+		"notes":         items,
+		"retentionDays": int(retention.Hours() / 24),
+	})
+}
+
+func (t *trashController) postRestoreNote(c *gin.Context) {
+	note, ok := t.findOwnedTrashedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	if err := t.noteUsecase.Restore(note); err != nil {
+		slog.Error("failed to restore note", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore note"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (t *trashController) deleteTrashedNote(c *gin.Context) {
+	note, ok := t.findOwnedTrashedNote(c)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "note not found"})
+		return
+	}
+
+	if err := t.noteUsecase.DeletePermanently(note); err != nil {
+		slog.Error("failed to delete note permanently", "noteId", note.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete note"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+func (t *trashController) deleteEmptyTrash(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	deleted, err := t.noteUsecase.EmptyTrash(user.ID)
+	if err != nil {
+		slog.Error("failed to empty trash", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to empty trash"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "success", "deleted": deleted})
+}
@@ -1,12 +1,17 @@
 package controller
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
+	"time"
 
+	"github.com/ToshihiroOgino/elib/domain"
 	"github.com/ToshihiroOgino/elib/secure"
 	"github.com/ToshihiroOgino/elib/usecase"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type registerForm struct {
@@ -19,21 +24,55 @@ type loginForm struct {
 	Password string `form:"password" binding:"required"`
 }
 
+type accessTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expiresInDays"`
+}
+
+type userSettingsRequest struct {
+	DefaultTitleFormat string `json:"defaultTitleFormat"`
+}
+
+// アクセストークンの有効期限の上限（日）
+const accessTokenMaxExpiresInDays = 365
+
+// accessTokenItem は設定画面のビューモデル
+type accessTokenItem struct {
+	ID         string
+	Name       string
+	Scopes     []string
+	CreatedAt  *time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	Expired    bool
+}
+
 type IUserController interface {
 	getLogin(c *gin.Context)
 	getRegister(c *gin.Context)
 	postRegister(c *gin.Context)
 	postLogin(c *gin.Context)
 	postLogout(c *gin.Context)
+	getSettings(c *gin.Context)
+	putSettings(c *gin.Context)
+	postAccessToken(c *gin.Context)
+	deleteAccessToken(c *gin.Context)
 }
 
 type userController struct {
-	usecase usecase.IUserUsecase
+	usecase         usecase.IUserUsecase
+	tokenUsecase    usecase.IAccessTokenUsecase
+	settingsUsecase usecase.IUserSettingsUsecase
+	templateUsecase usecase.ITemplateUsecase
 }
 
 func NewUserController(router *gin.Engine) IUserController {
 	instance := &userController{
-		usecase: usecase.NewUserUsecase(),
+		usecase:         usecase.NewUserUsecase(),
+		tokenUsecase:    usecase.NewAccessTokenUsecase(),
+		settingsUsecase: usecase.NewUserSettingsUsecase(),
+		templateUsecase: usecase.NewTemplateUsecase(),
 	}
 	setupUserRoute(instance, router)
 	return instance
@@ -47,6 +86,15 @@ func setupUserRoute(api IUserController, router *gin.Engine) {
 	userGroup.POST("/login", api.postLogin)
 	userGroup.POST("/register", api.postRegister)
 	userGroup.POST("/logout", api.postLogout)
+
+	settingsGroup := userGroup.Group("")
+	settingsGroup.Use(secure.AuthMiddleware())
+	{
+		settingsGroup.GET("/settings", api.getSettings)
+		settingsGroup.PUT("/settings", api.putSettings)
+		settingsGroup.POST("/tokens", api.postAccessToken)
+		settingsGroup.DELETE("/tokens/:id", api.deleteAccessToken)
+	}
 }
 
 func (u *userController) getLogin(c *gin.Context) {
@@ -130,3 +178,138 @@ func (u *userController) postLogout(c *gin.Context) {
 	slog.Info("user logged out")
 	c.Redirect(http.StatusSeeOther, "/user/login")
 }
+
+func (u *userController) getSettings(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	tokens, err := u.tokenUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get access tokens", "userId", user.ID, "error", err)
+		tokens = []*domain.PersonalAccessToken{}
+	}
+
+	now := time.Now()
+	items := make([]accessTokenItem, 0, len(tokens))
+	for _, token := range tokens {
+		items = append(items, accessTokenItem{
+			ID:         token.ID,
+			Name:       token.Name,
+			Scopes:     usecase.AccessTokenScopeList(token),
+			CreatedAt:  token.CreatedAt,
+			ExpiresAt:  token.ExpiresAt,
+			LastUsedAt: token.LastUsedAt,
+			Expired:    token.ExpiresAt != nil && !now.Before(*token.ExpiresAt),
+		})
+	}
+
+	titleFormat, err := u.settingsUsecase.TitleFormat(user.ID)
+	if err != nil {
+		slog.Error("failed to get title format", "userId", user.ID, "error", err)
+		titleFormat = usecase.DefaultTitleFormat
+	}
+	templates, err := u.templateUsecase.FindByUser(user.ID)
+	if err != nil {
+		slog.Error("failed to get templates", "userId", user.ID, "error", err)
+		templates = []*domain.NoteTemplate{}
+	}
+
+	c.HTML(http.StatusOK, "settings.html", gin.H{
+		"title":              "Settings",
+		"user":               user,
+		"tokens":             items,
+		"scopes":             usecase.AccessTokenScopes,
+		"titleFormat":        titleFormat,
+		"defaultTitleFormat": usecase.DefaultTitleFormat,
+		"placeholders":       usecase.TemplatePlaceholders,
+		"templates":          templates,
+	})
+}
+
+// putSettings は新規メモのタイトル形式を保存する。空文字は既定の形式に戻す
+func (u *userController) putSettings(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	var req userSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	format, valid := secure.ValidateTextInput(req.DefaultTitleFormat, maxNoteTitleLength)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid title format"})
+		return
+	}
+
+	err := u.settingsUsecase.SetTitleFormat(user.ID, format)
+	if errors.Is(err, usecase.ErrInvalidTitleFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid title format"})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to save settings", "userId", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
+
+// postAccessToken はトークンを発行する。平文のトークンはこのレスポンスでのみ返す
+func (u *userController) postAccessToken(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+
+	var req accessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request"})
+		return
+	}
+	name, valid := secure.ValidateTextInput(req.Name, 100)
+	if !valid || name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid name"})
+		return
+	}
+	if len(req.Scopes) == 0 || slices.ContainsFunc(req.Scopes, func(scope string) bool {
+		return !slices.Contains(usecase.AccessTokenScopes, scope)
+	}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scopes"})
+		return
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > accessTokenMaxExpiresInDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid expiry"})
+		return
+	}
+
+	// 0 日は無期限
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, plain, err := u.tokenUsecase.Create(user, name, req.Scopes, expiresAt)
+	if err != nil {
+		slog.Error("failed to create access token", "userId", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create access token"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"status": "success", "id": token.ID, "token": plain})
+}
+
+func (u *userController) deleteAccessToken(c *gin.Context) {
+	user := secure.GetSessionUser(c)
+	tokenID := c.Param("id")
+
+	err := u.tokenUsecase.Revoke(user.ID, tokenID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "access token not found"})
+		return
+	}
+	if err != nil {
+		slog.Error("failed to revoke access token", "tokenId", tokenID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke access token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "success"})
+}
@@ -6,18 +6,28 @@ package domain
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 const TableNameNote = "notes"
 
 // Note mapped from table <notes>
 type Note struct {
-	ID        string     `gorm:"column:id;primaryKey" json:"id"`
-	AuthorID  string     `gorm:"column:author_id;not null" json:"author_id"`
-	Title     string     `gorm:"column:title;not null" json:"title"`
-	Content   string     `gorm:"column:content;not null" json:"content"`
-	CreatedAt *time.Time `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt *time.Time `gorm:"column:updated_at;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID        string         `gorm:"column:id;primaryKey" json:"id"`
+	AuthorID  string         `gorm:"column:author_id;not null" json:"author_id"`
+	Title     string         `gorm:"column:title;not null" json:"title"`
+	Content   string         `gorm:"column:content;not null" json:"content"`
+	CreatedAt *time.Time     `gorm:"column:created_at;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt *time.Time     `gorm:"column:updated_at;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	Version   int32          `gorm:"column:version;not null;default:1" json:"version"`
+	SearchID  *int32         `gorm:"column:search_id" json:"search_id"`
+	FolderID  *string        `gorm:"column:folder_id" json:"folder_id"`
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at" json:"deleted_at"`
+	Format    string         `gorm:"column:format;not null;default:'plain'" json:"format"`
+	Pinned    bool           `gorm:"column:pinned;not null" json:"pinned"`
+	Favorite  bool           `gorm:"column:favorite;not null" json:"favorite"`
+	SortKey   *string        `gorm:"column:sort_key" json:"sort_key"`
 }
 
 // TableName Note's table name
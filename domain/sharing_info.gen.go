@@ -4,13 +4,22 @@
 
 package domain
 
+import (
+	"time"
+)
+
 const TableNameSharingInfo = "sharing_info"
 
 // SharingInfo mapped from table <sharing_info>
 type SharingInfo struct {
-	ID       string `gorm:"column:id;primaryKey" json:"id"`
-	NoteID   string `gorm:"column:note_id;not null" json:"note_id"`
-	Editable bool   `gorm:"column:editable;not null" json:"editable"`
+	ID            string     `gorm:"column:id;primaryKey" json:"id"`
+	NoteID        string     `gorm:"column:note_id;not null" json:"note_id"`
+	Editable      bool       `gorm:"column:editable;not null" json:"editable"`
+	ExpiresAt     *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	MaxViews      *int32     `gorm:"column:max_views" json:"max_views"`
+	ViewCount     int32      `gorm:"column:view_count;not null" json:"view_count"`
+	EditableUntil *time.Time `gorm:"column:editable_until" json:"editable_until"`
+	PasswordHash  *[]byte    `gorm:"column:password_hash" json:"password_hash"`
 }
 
 // TableName SharingInfo's table name
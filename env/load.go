@@ -7,16 +7,34 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 type Env struct {
 	Port      int
 	DBFile    string
 	JWTSecret string
+	// RevisionLimit is the maximum number of revisions kept per note.
+	RevisionLimit int
+	// RevisionMergeWindow is the period in which consecutive saves by the
+	// same editor are merged into a single revision.
+	RevisionMergeWindow time.Duration
+	// TrashRetention is how long deleted notes stay in the trash before they
+	// are purged. Zero disables purging.
+	TrashRetention time.Duration
+	// AttachmentDir is the directory where attachment files are stored.
+	AttachmentDir string
+	// AttachmentMaxBytes is the maximum size of a single attachment.
+	AttachmentMaxBytes int64
+	// ImportMaxBytes is the maximum size of an uploaded import archive.
+	ImportMaxBytes int64
+	// ShareAccessLogRetention is how long accesses to share links are kept
+	// in the access log. Zero keeps them forever.
+	ShareAccessLogRetention time.Duration
 }
 
 func (e Env) Keys() []string {
-	keys := make([]string, 0, 3)
+	keys := make([]string, 0, 10)
 	if e.Port != 0 {
 		keys = append(keys, "PORT")
 	}
@@ -26,9 +44,40 @@ func (e Env) Keys() []string {
 	if e.JWTSecret != "" {
 		keys = append(keys, "JWT_SECRET")
 	}
+	if e.RevisionLimit != 0 {
+		keys = append(keys, "REVISION_LIMIT")
+	}
+	if e.RevisionMergeWindow != 0 {
+		keys = append(keys, "REVISION_MERGE_SECONDS")
+	}
+	if e.TrashRetention != 0 {
+		keys = append(keys, "TRASH_RETENTION_DAYS")
+	}
+	if e.AttachmentDir != "" {
+		keys = append(keys, "ATTACHMENT_DIR")
+	}
+	if e.AttachmentMaxBytes != 0 {
+		keys = append(keys, "ATTACHMENT_MAX_MB")
+	}
+	if e.ImportMaxBytes != 0 {
+		keys = append(keys, "IMPORT_MAX_MB")
+	}
+	if e.ShareAccessLogRetention != 0 {
+		keys = append(keys, "SHARE_ACCESS_LOG_RETENTION_DAYS")
+	}
 	return keys
 }
 
+const (
+	defaultRevisionLimit               = 100
+	defaultRevisionMergeSeconds        = 60
+	defaultTrashRetentionDays          = 30
+	defaultAttachmentDir               = "./attachments"
+	defaultAttachmentMaxMB             = 10
+	defaultImportMaxMB                 = 50
+	defaultShareAccessLogRetentionDays = 90
+)
+
 var (
 	once sync.Once
 	env  Env
@@ -64,24 +113,40 @@ func parseLine(line string) (string, string) {
 	return key, value
 }
 
+// parseIntOrDefault returns the integer value of key, or def if the key is
+// missing or malformed.
+func parseIntOrDefault(envMap map[string]string, key string, def int) int {
+	value, ok := envMap[key]
+	if !ok {
+		return def
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Error("failed to parse value from .env file", "key", key, "error", err)
+		return def
+	}
+	return parsed
+}
+
 func load() {
 	envPath := ".env"
+	envMap := make(map[string]string)
+
 	file, err := os.Open(envPath)
 	if err != nil {
 		slog.Error("failed to open .env file", "error", err)
-		return
-	}
-	defer file.Close()
+	} else {
+		defer file.Close()
 
-	envMap := make(map[string]string)
-	reader := bufio.NewScanner(file)
-	for reader.Scan() {
-		line := reader.Text()
-		key, value := parseLine(line)
-		if key == "" || value == "" {
-			continue // Skip invalid lines
+		reader := bufio.NewScanner(file)
+		for reader.Scan() {
+			line := reader.Text()
+			key, value := parseLine(line)
+			if key == "" || value == "" {
+				continue // Skip invalid lines
+			}
+			envMap[key] = value
 		}
-		envMap[key] = value
 	}
 
 	port, err := strconv.Atoi(envMap["PORT"])
@@ -90,9 +155,19 @@ func load() {
 	}
 
 	env = Env{
-		Port:      port,
-		DBFile:    envMap["DB_FILE"],
-		JWTSecret: envMap["JWT_SECRET"],
+		Port:                    port,
+		DBFile:                  envMap["DB_FILE"],
+		JWTSecret:               envMap["JWT_SECRET"],
+		RevisionLimit:           parseIntOrDefault(envMap, "REVISION_LIMIT", defaultRevisionLimit),
+		RevisionMergeWindow:     time.Duration(parseIntOrDefault(envMap, "REVISION_MERGE_SECONDS", defaultRevisionMergeSeconds)) * time.Second,
+		TrashRetention:          time.Duration(parseIntOrDefault(envMap, "TRASH_RETENTION_DAYS", defaultTrashRetentionDays)) * 24 * time.Hour,
+		AttachmentDir:           defaultAttachmentDir,
+		AttachmentMaxBytes:      int64(parseIntOrDefault(envMap, "ATTACHMENT_MAX_MB", defaultAttachmentMaxMB)) << 20,
+		ImportMaxBytes:          int64(parseIntOrDefault(envMap, "IMPORT_MAX_MB", defaultImportMaxMB)) << 20,
+		ShareAccessLogRetention: time.Duration(parseIntOrDefault(envMap, "SHARE_ACCESS_LOG_RETENTION_DAYS", defaultShareAccessLogRetentionDays)) * 24 * time.Hour,
+	}
+	if dir, ok := envMap["ATTACHMENT_DIR"]; ok {
+		env.AttachmentDir = dir
 	}
 	slog.Debug("loaded environment variables", "EnvKeys", env.Keys())
 }
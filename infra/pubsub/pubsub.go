@@ -0,0 +1,60 @@
+package pubsub
+
+import "sync"
+
+// subscriberBuffer is the number of events queued per subscriber. A
+// subscriber that falls further behind misses events instead of blocking
+// the publisher.
+const subscriberBuffer = 16
+
+// Hub delivers events published on a topic to every current subscriber of
+// that topic. It is in-process only; subscribers in other processes do not
+// receive events.
+type Hub[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[chan T]struct{}
+}
+
+func NewHub[T any]() *Hub[T] {
+	return &Hub[T]{
+		subscribers: map[string]map[chan T]struct{}{},
+	}
+}
+
+// Subscribe returns a channel receiving the events of topic and a function
+// that ends the subscription and closes the channel.
+func (h *Hub[T]) Subscribe(topic string) (<-chan T, func()) {
+	ch := make(chan T, subscriberBuffer)
+	h.mu.Lock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = map[chan T]struct{}{}
+	}
+	h.subscribers[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			delete(h.subscribers[topic], ch)
+			if len(h.subscribers[topic]) == 0 {
+				delete(h.subscribers, topic)
+			}
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// Publish sends event to the subscribers of topic without waiting for them.
+func (h *Hub[T]) Publish(topic string, event T) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers[topic] {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"errors"
 	"log"
 	"sync"
 
@@ -50,3 +51,16 @@ func CloseDB() error {
 	}
 	return nil
 }
+
+// CheckFeatures verifies that the linked SQLite library supports the features
+// the schema relies on. Full-text search needs the sqlite_fts5 build tag.
+func CheckFeatures() error {
+	var fts5 bool
+	if err := GetDB().Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5).Error; err != nil {
+		return err
+	}
+	if !fts5 {
+		return errors.New("SQLite is built without FTS5; build with `-tags sqlite_fts5`")
+	}
+	return nil
+}
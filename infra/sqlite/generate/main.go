@@ -2,12 +2,35 @@ package main
 
 import (
 	"log/slog"
+	"strings"
 
 	"github.com/ToshihiroOgino/elib/infra/sqlite"
 	"github.com/ToshihiroOgino/elib/log"
 	"gorm.io/gen"
+	"gorm.io/gorm"
 )
 
+// virtualTables returns the names of virtual tables (e.g. FTS5 indexes).
+// They and their shadow tables are maintained by SQLite and have no model.
+func virtualTables(db *gorm.DB) []string {
+	var names []string
+	err := db.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE%'").
+		Scan(&names).Error
+	if err != nil {
+		panic(err)
+	}
+	return names
+}
+
+func isVirtualOrShadow(table string, virtuals []string) bool {
+	for _, v := range virtuals {
+		if table == v || strings.HasPrefix(table, v+"_") {
+			return true
+		}
+	}
+	return false
+}
+
 func main() {
 	log.Init()
 	slog.Info("Starting code generation")
@@ -20,7 +43,20 @@ func main() {
 	})
 	db := sqlite.GetDB()
 	g.UseDB(db)
-	all := g.GenerateAllTable()
-	g.ApplyBasic(all...)
+
+	tables, err := db.Migrator().GetTables()
+	if err != nil {
+		panic(err)
+	}
+	virtuals := virtualTables(db)
+	models := make([]interface{}, 0, len(tables))
+	for _, table := range tables {
+		if isVirtualOrShadow(table, virtuals) {
+			slog.Info("skip virtual table", "table", table)
+			continue
+		}
+		models = append(models, g.GenerateModel(table))
+	}
+	g.ApplyBasic(models...)
 	g.Execute()
 }
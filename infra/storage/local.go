@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+var blobKeyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// localStorage keeps blobs on the local disk as <dir>/<key[:2]>/<key>.
+type localStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) (IBlobStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &localStorage{dir: dir}, nil
+}
+
+func (l *localStorage) path(key string) (string, error) {
+	if !blobKeyPattern.MatchString(key) {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(l.dir, key[:2], key), nil
+}
+
+func (l *localStorage) Put(r io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(l.dir, "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	key := hex.EncodeToString(hash.Sum(nil))
+	dest, err := l.path(key)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := os.Stat(dest); err == nil {
+		// 同じ内容のファイルは既に保存されている
+		return key, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0o750); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+func (l *localStorage) Open(key string) (io.ReadCloser, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return file, err
+}
+
+func (l *localStorage) Delete(key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
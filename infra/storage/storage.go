@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+
+	"github.com/ToshihiroOgino/elib/env"
+)
+
+// ErrBlobNotFound is returned when no blob is stored under the given key.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// IBlobStorage stores immutable blobs addressed by the SHA-256 of their
+// content, so identical files are stored only once.
+type IBlobStorage interface {
+	// Put stores the content of r and returns its key and size.
+	Put(r io.Reader) (key string, size int64, err error)
+	Open(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var (
+	blobStorage IBlobStorage
+	storageOnce sync.Once
+)
+
+func GetStorage() IBlobStorage {
+	storageOnce.Do(func() {
+		var err error
+		blobStorage, err = NewLocalStorage(env.Get().AttachmentDir)
+		if err != nil {
+			log.Fatalf("Failed to initialize attachment storage: %v", err)
+		}
+	})
+	return blobStorage
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ToshihiroOgino/elib/infra/sqlite"
 	"github.com/ToshihiroOgino/elib/log"
 	"github.com/ToshihiroOgino/elib/secure"
+	"github.com/ToshihiroOgino/elib/usecase"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,6 +17,13 @@ func main() {
 	log.Init()
 
 	defer sqlite.CloseDB()
+	if err := sqlite.CheckFeatures(); err != nil {
+		slog.Error("unsupported SQLite build", "error", err)
+		return
+	}
+
+	usecase.StartTrashPurger(env.Get().TrashRetention)
+	usecase.StartShareAccessLogger(env.Get().ShareAccessLogRetention)
 
 	router := gin.Default()
 	router.SetTrustedProxies(nil)
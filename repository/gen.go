@@ -16,44 +16,104 @@ import (
 )
 
 var (
-	Q           = new(Query)
-	Note        *note
-	SharingInfo *sharingInfo
-	User        *user
+	Q                   = new(Query)
+	Attachment          *attachment
+	Folder              *folder
+	Note                *note
+	NoteLink            *noteLink
+	NoteMember          *noteMember
+	NoteRevision        *noteRevision
+	NoteRevisionEditor  *noteRevisionEditor
+	NoteTag             *noteTag
+	NoteTemplate        *noteTemplate
+	PersonalAccessToken *personalAccessToken
+	ShareAccessLog      *shareAccessLog
+	SharingInfo         *sharingInfo
+	Tag                 *tag
+	User                *user
+	UserSetting         *userSetting
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
+	Attachment = &Q.Attachment
+	Folder = &Q.Folder
 	Note = &Q.Note
+	NoteLink = &Q.NoteLink
+	NoteMember = &Q.NoteMember
+	NoteRevision = &Q.NoteRevision
+	NoteRevisionEditor = &Q.NoteRevisionEditor
+	NoteTag = &Q.NoteTag
+	NoteTemplate = &Q.NoteTemplate
+	PersonalAccessToken = &Q.PersonalAccessToken
+	ShareAccessLog = &Q.ShareAccessLog
 	SharingInfo = &Q.SharingInfo
+	Tag = &Q.Tag
 	User = &Q.User
+	UserSetting = &Q.UserSetting
 }
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:          db,
-		Note:        newNote(db, opts...),
-		SharingInfo: newSharingInfo(db, opts...),
-		User:        newUser(db, opts...),
+		db:                  db,
+		Attachment:          newAttachment(db, opts...),
+		Folder:              newFolder(db, opts...),
+		Note:                newNote(db, opts...),
+		NoteLink:            newNoteLink(db, opts...),
+		NoteMember:          newNoteMember(db, opts...),
+		NoteRevision:        newNoteRevision(db, opts...),
+		NoteRevisionEditor:  newNoteRevisionEditor(db, opts...),
+		NoteTag:             newNoteTag(db, opts...),
+		NoteTemplate:        newNoteTemplate(db, opts...),
+		PersonalAccessToken: newPersonalAccessToken(db, opts...),
+		ShareAccessLog:      newShareAccessLog(db, opts...),
+		SharingInfo:         newSharingInfo(db, opts...),
+		Tag:                 newTag(db, opts...),
+		User:                newUser(db, opts...),
+		UserSetting:         newUserSetting(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Note        note
-	SharingInfo sharingInfo
-	User        user
+	Attachment          attachment
+	Folder              folder
+	Note                note
+	NoteLink            noteLink
+	NoteMember          noteMember
+	NoteRevision        noteRevision
+	NoteRevisionEditor  noteRevisionEditor
+	NoteTag             noteTag
+	NoteTemplate        noteTemplate
+	PersonalAccessToken personalAccessToken
+	ShareAccessLog      shareAccessLog
+	SharingInfo         sharingInfo
+	Tag                 tag
+	User                user
+	UserSetting         userSetting
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:          db,
-		Note:        q.Note.clone(db),
-		SharingInfo: q.SharingInfo.clone(db),
-		User:        q.User.clone(db),
+		db:                  db,
+		Attachment:          q.Attachment.clone(db),
+		Folder:              q.Folder.clone(db),
+		Note:                q.Note.clone(db),
+		NoteLink:            q.NoteLink.clone(db),
+		NoteMember:          q.NoteMember.clone(db),
+		NoteRevision:        q.NoteRevision.clone(db),
+		NoteRevisionEditor:  q.NoteRevisionEditor.clone(db),
+		NoteTag:             q.NoteTag.clone(db),
+		NoteTemplate:        q.NoteTemplate.clone(db),
+		PersonalAccessToken: q.PersonalAccessToken.clone(db),
+		ShareAccessLog:      q.ShareAccessLog.clone(db),
+		SharingInfo:         q.SharingInfo.clone(db),
+		Tag:                 q.Tag.clone(db),
+		User:                q.User.clone(db),
+		UserSetting:         q.UserSetting.clone(db),
 	}
 }
 
@@ -67,24 +127,60 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:          db,
-		Note:        q.Note.replaceDB(db),
-		SharingInfo: q.SharingInfo.replaceDB(db),
-		User:        q.User.replaceDB(db),
+		db:                  db,
+		Attachment:          q.Attachment.replaceDB(db),
+		Folder:              q.Folder.replaceDB(db),
+		Note:                q.Note.replaceDB(db),
+		NoteLink:            q.NoteLink.replaceDB(db),
+		NoteMember:          q.NoteMember.replaceDB(db),
+		NoteRevision:        q.NoteRevision.replaceDB(db),
+		NoteRevisionEditor:  q.NoteRevisionEditor.replaceDB(db),
+		NoteTag:             q.NoteTag.replaceDB(db),
+		NoteTemplate:        q.NoteTemplate.replaceDB(db),
+		PersonalAccessToken: q.PersonalAccessToken.replaceDB(db),
+		ShareAccessLog:      q.ShareAccessLog.replaceDB(db),
+		SharingInfo:         q.SharingInfo.replaceDB(db),
+		Tag:                 q.Tag.replaceDB(db),
+		User:                q.User.replaceDB(db),
+		UserSetting:         q.UserSetting.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	Note        INoteDo
-	SharingInfo ISharingInfoDo
-	User        IUserDo
+	Attachment          IAttachmentDo
+	Folder              IFolderDo
+	Note                INoteDo
+	NoteLink            INoteLinkDo
+	NoteMember          INoteMemberDo
+	NoteRevision        INoteRevisionDo
+	NoteRevisionEditor  INoteRevisionEditorDo
+	NoteTag             INoteTagDo
+	NoteTemplate        INoteTemplateDo
+	PersonalAccessToken IPersonalAccessTokenDo
+	ShareAccessLog      IShareAccessLogDo
+	SharingInfo         ISharingInfoDo
+	Tag                 ITagDo
+	User                IUserDo
+	UserSetting         IUserSettingDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Note:        q.Note.WithContext(ctx),
-		SharingInfo: q.SharingInfo.WithContext(ctx),
-		User:        q.User.WithContext(ctx),
+		Attachment:          q.Attachment.WithContext(ctx),
+		Folder:              q.Folder.WithContext(ctx),
+		Note:                q.Note.WithContext(ctx),
+		NoteLink:            q.NoteLink.WithContext(ctx),
+		NoteMember:          q.NoteMember.WithContext(ctx),
+		NoteRevision:        q.NoteRevision.WithContext(ctx),
+		NoteRevisionEditor:  q.NoteRevisionEditor.WithContext(ctx),
+		NoteTag:             q.NoteTag.WithContext(ctx),
+		NoteTemplate:        q.NoteTemplate.WithContext(ctx),
+		PersonalAccessToken: q.PersonalAccessToken.WithContext(ctx),
+		ShareAccessLog:      q.ShareAccessLog.WithContext(ctx),
+		SharingInfo:         q.SharingInfo.WithContext(ctx),
+		Tag:                 q.Tag.WithContext(ctx),
+		User:                q.User.WithContext(ctx),
+		UserSetting:         q.UserSetting.WithContext(ctx),
 	}
 }
 
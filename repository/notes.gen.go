@@ -34,6 +34,14 @@ func newNote(db *gorm.DB, opts ...gen.DOOption) note {
 	_note.Content = field.NewString(tableName, "content")
 	_note.CreatedAt = field.NewTime(tableName, "created_at")
 	_note.UpdatedAt = field.NewTime(tableName, "updated_at")
+	_note.Version = field.NewInt32(tableName, "version")
+	_note.SearchID = field.NewInt32(tableName, "search_id")
+	_note.FolderID = field.NewString(tableName, "folder_id")
+	_note.DeletedAt = field.NewField(tableName, "deleted_at")
+	_note.Format = field.NewString(tableName, "format")
+	_note.Pinned = field.NewBool(tableName, "pinned")
+	_note.Favorite = field.NewBool(tableName, "favorite")
+	_note.SortKey = field.NewString(tableName, "sort_key")
 
 	_note.fillFieldMap()
 
@@ -50,6 +58,14 @@ type note struct {
 	Content   field.String
 	CreatedAt field.Time
 	UpdatedAt field.Time
+	Version   field.Int32
+	SearchID  field.Int32
+	FolderID  field.String
+	DeletedAt field.Field
+	Format    field.String
+	Pinned    field.Bool
+	Favorite  field.Bool
+	SortKey   field.String
 
 	fieldMap map[string]field.Expr
 }
@@ -72,6 +88,14 @@ func (n *note) updateTableName(table string) *note {
 	n.Content = field.NewString(table, "content")
 	n.CreatedAt = field.NewTime(table, "created_at")
 	n.UpdatedAt = field.NewTime(table, "updated_at")
+	n.Version = field.NewInt32(table, "version")
+	n.SearchID = field.NewInt32(table, "search_id")
+	n.FolderID = field.NewString(table, "folder_id")
+	n.DeletedAt = field.NewField(table, "deleted_at")
+	n.Format = field.NewString(table, "format")
+	n.Pinned = field.NewBool(table, "pinned")
+	n.Favorite = field.NewBool(table, "favorite")
+	n.SortKey = field.NewString(table, "sort_key")
 
 	n.fillFieldMap()
 
@@ -96,13 +120,21 @@ func (n *note) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (n *note) fillFieldMap() {
-	n.fieldMap = make(map[string]field.Expr, 6)
+	n.fieldMap = make(map[string]field.Expr, 14)
 	n.fieldMap["id"] = n.ID
 	n.fieldMap["author_id"] = n.AuthorID
 	n.fieldMap["title"] = n.Title
 	n.fieldMap["content"] = n.Content
 	n.fieldMap["created_at"] = n.CreatedAt
 	n.fieldMap["updated_at"] = n.UpdatedAt
+	n.fieldMap["version"] = n.Version
+	n.fieldMap["search_id"] = n.SearchID
+	n.fieldMap["folder_id"] = n.FolderID
+	n.fieldMap["deleted_at"] = n.DeletedAt
+	n.fieldMap["format"] = n.Format
+	n.fieldMap["pinned"] = n.Pinned
+	n.fieldMap["favorite"] = n.Favorite
+	n.fieldMap["sort_key"] = n.SortKey
 }
 
 func (n note) clone(db *gorm.DB) note {
@@ -31,6 +31,11 @@ func newSharingInfo(db *gorm.DB, opts ...gen.DOOption) sharingInfo {
 	_sharingInfo.ID = field.NewString(tableName, "id")
 	_sharingInfo.NoteID = field.NewString(tableName, "note_id")
 	_sharingInfo.Editable = field.NewBool(tableName, "editable")
+	_sharingInfo.ExpiresAt = field.NewTime(tableName, "expires_at")
+	_sharingInfo.MaxViews = field.NewInt32(tableName, "max_views")
+	_sharingInfo.ViewCount = field.NewInt32(tableName, "view_count")
+	_sharingInfo.EditableUntil = field.NewTime(tableName, "editable_until")
+	_sharingInfo.PasswordHash = field.NewBytes(tableName, "password_hash")
 
 	_sharingInfo.fillFieldMap()
 
@@ -40,10 +45,15 @@ func newSharingInfo(db *gorm.DB, opts ...gen.DOOption) sharingInfo {
 type sharingInfo struct {
 	sharingInfoDo sharingInfoDo
 
-	ALL      field.Asterisk
-	ID       field.String
-	NoteID   field.String
-	Editable field.Bool
+	ALL           field.Asterisk
+	ID            field.String
+	NoteID        field.String
+	Editable      field.Bool
+	ExpiresAt     field.Time
+	MaxViews      field.Int32
+	ViewCount     field.Int32
+	EditableUntil field.Time
+	PasswordHash  field.Bytes
 
 	fieldMap map[string]field.Expr
 }
@@ -63,6 +73,11 @@ func (s *sharingInfo) updateTableName(table string) *sharingInfo {
 	s.ID = field.NewString(table, "id")
 	s.NoteID = field.NewString(table, "note_id")
 	s.Editable = field.NewBool(table, "editable")
+	s.ExpiresAt = field.NewTime(table, "expires_at")
+	s.MaxViews = field.NewInt32(table, "max_views")
+	s.ViewCount = field.NewInt32(table, "view_count")
+	s.EditableUntil = field.NewTime(table, "editable_until")
+	s.PasswordHash = field.NewBytes(table, "password_hash")
 
 	s.fillFieldMap()
 
@@ -89,10 +104,15 @@ func (s *sharingInfo) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (s *sharingInfo) fillFieldMap() {
-	s.fieldMap = make(map[string]field.Expr, 3)
+	s.fieldMap = make(map[string]field.Expr, 8)
 	s.fieldMap["id"] = s.ID
 	s.fieldMap["note_id"] = s.NoteID
 	s.fieldMap["editable"] = s.Editable
+	s.fieldMap["expires_at"] = s.ExpiresAt
+	s.fieldMap["max_views"] = s.MaxViews
+	s.fieldMap["view_count"] = s.ViewCount
+	s.fieldMap["editable_until"] = s.EditableUntil
+	s.fieldMap["password_hash"] = s.PasswordHash
 }
 
 func (s sharingInfo) clone(db *gorm.DB) sharingInfo {
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/ToshihiroOgino/elib/domain"
@@ -20,6 +21,10 @@ func authFailed(c *gin.Context, err error) {
 
 const userKey = "user"
 
+// scopesKey holds the scopes of the access token that authenticated the
+// request. It is not set for requests authenticated by the browser session.
+const scopesKey = "token_scopes"
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user, err := ValidateUserSession(c)
@@ -32,6 +37,71 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// AccessTokenAuthenticator resolves a personal access token to its owner and
+// scopes.
+type AccessTokenAuthenticator interface {
+	Authenticate(token string) (*domain.User, []string, error)
+}
+
+// APIAuthMiddleware authenticates like AuthMiddleware, but leaves the response
+// to onFailure instead of redirecting to the login page. A personal access
+// token in the Authorization header is accepted as well, and limits the
+// request to the routes allowed by RequireScope. A request whose token is
+// rejected fails even if it also has a browser session, so that it never runs
+// without the scopes of the token.
+func APIAuthMiddleware(tokens AccessTokenAuthenticator, onFailure func(c *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if header := c.GetHeader("Authorization"); header != "" {
+			if token, err := parseBearerToken(header); err == nil {
+				user, scopes, err := tokens.Authenticate(token)
+				if err != nil {
+					slog.Warn("access token authentication failed", "reason", err.Error())
+					onFailure(c)
+					c.Abort()
+					return
+				}
+				c.Set(userKey, user)
+				c.Set(scopesKey, scopes)
+				c.Next()
+				return
+			}
+		}
+
+		user, err := ValidateUserSession(c)
+		if err != nil {
+			slog.Warn("api authentication failed", "reason", err.Error())
+			onFailure(c)
+			c.Abort()
+			return
+		}
+		c.Set(userKey, user)
+		c.Next()
+	}
+}
+
+// RequireScope lets the request through only if it was authenticated by the
+// browser session or by an access token that has scope.
+func RequireScope(scope string, onFailure func(c *gin.Context)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !HasScope(c, scope) {
+			onFailure(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// HasScope reports whether the request was authenticated by the browser
+// session or by an access token that has scope.
+func HasScope(c *gin.Context, scope string) bool {
+	value, exists := c.Get(scopesKey)
+	if !exists {
+		return true
+	}
+	return slices.Contains(value.([]string), scope)
+}
+
 func GetSessionUser(c *gin.Context) *domain.User {
 	user, exists := c.Get(userKey)
 	if !exists {
@@ -55,7 +125,6 @@ func GetLoggedInUser(c *gin.Context) (*domain.User, error) {
 		if bearerToken == "" {
 			return nil, errors.New("no auth token found")
 		}
-		return nil, err
 	}
 
 	tokenStr, err := parseBearerToken(bearerToken)
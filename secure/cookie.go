@@ -11,8 +11,19 @@ import (
 const (
 	AuthTokenCookieKey   = "auth_token"
 	SessionDataCookieKey = "session_data"
+	ShareUnlockCookieKey = "share_unlock"
+	// ShareViewCookieKey lets the page of a counted view of a share link load
+	// its attachments and updates.
+	ShareViewCookieKey = "share_view"
+	// ShareEditorNameCookieKey holds the display name of an anonymous editor
+	// of share links.
+	ShareEditorNameCookieKey = "share_editor_name"
 )
 
+// shareEditorNameMaxAge is how long the display name of a share link editor
+// is remembered.
+const shareEditorNameMaxAge = 365 * 24 * time.Hour
+
 // CookieConfig holds configuration for cookie settings
 type CookieConfig struct {
 	Name     string
@@ -78,6 +89,40 @@ func (cm *CookieManager) SetAuthCookie(c *gin.Context, token string) {
 	cm.SetCookie(c, config)
 }
 
+// SetShareUnlockCookie sets the cookie that unlocks a password-protected
+// share link. It is only sent to the pages of that share link
+func (cm *CookieManager) SetShareUnlockCookie(c *gin.Context, shareID string, token string, maxAge time.Duration) {
+	config := cm.defaultConfig
+	config.Name = ShareUnlockCookieKey
+	config.Value = token
+	config.MaxAge = int(maxAge.Seconds())
+	config.Path = "/share/" + shareID
+	cm.SetCookie(c, config)
+}
+
+// SetShareViewCookie sets the cookie issued on a counted view of a share
+// link. Like the unlock cookie it is only sent to the pages of that link
+func (cm *CookieManager) SetShareViewCookie(c *gin.Context, shareID string, token string, maxAge time.Duration) {
+	config := cm.defaultConfig
+	config.Name = ShareViewCookieKey
+	config.Value = token
+	config.MaxAge = int(maxAge.Seconds())
+	config.Path = "/share/" + shareID
+	cm.SetCookie(c, config)
+}
+
+// SetShareEditorNameCookie remembers the display name of an anonymous editor.
+// It is sent to the pages of every share link, so that the name is asked only
+// once.
+func (cm *CookieManager) SetShareEditorNameCookie(c *gin.Context, name string) {
+	config := cm.defaultConfig
+	config.Name = ShareEditorNameCookieKey
+	config.Value = name
+	config.MaxAge = int(shareEditorNameMaxAge.Seconds())
+	config.Path = "/share"
+	cm.SetCookie(c, config)
+}
+
 // GetCookie retrieves a cookie value
 func (cm *CookieManager) GetCookie(c *gin.Context, name string) (string, error) {
 	return c.Cookie(name)
@@ -107,6 +152,18 @@ func SetAuthCookieSecure(c *gin.Context, token string) {
 	globalCookieManager.SetAuthCookie(c, token)
 }
 
+func SetShareUnlockCookie(c *gin.Context, shareID string, token string, maxAge time.Duration) {
+	globalCookieManager.SetShareUnlockCookie(c, shareID, token, maxAge)
+}
+
+func SetShareViewCookie(c *gin.Context, shareID string, token string, maxAge time.Duration) {
+	globalCookieManager.SetShareViewCookie(c, shareID, token, maxAge)
+}
+
+func SetShareEditorNameCookie(c *gin.Context, name string) {
+	globalCookieManager.SetShareEditorNameCookie(c, name)
+}
+
 func GetCookieSecure(c *gin.Context, name string) (string, error) {
 	return globalCookieManager.GetCookie(c, name)
 }
@@ -18,6 +18,82 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// ShareClaims are the claims of a token that unlocks a password-protected
+// share link
+type ShareClaims struct {
+	ShareID string `json:"share_id"`
+	// Key is the usecase.ShareUnlockKey of the password that was entered
+	Key string `json:"key"`
+	jwt.RegisteredClaims
+}
+
+// shareTokenAudience keeps share tokens from being accepted as auth tokens
+const shareTokenAudience = "elib-share"
+
+// shareViewTokenAudience keeps view tokens from being accepted as unlock
+// tokens
+const shareViewTokenAudience = "elib-share-view"
+
+func GenerateShareToken(shareID string, key string, ttl time.Duration) (string, error) {
+	return generateShareClaims(shareID, key, ttl, shareTokenAudience)
+}
+
+// ValidateShareToken checks that tokenString unlocks shareID with the
+// password identified by key
+func ValidateShareToken(tokenString string, shareID string, key string) error {
+	return validateShareClaims(tokenString, shareID, key, shareTokenAudience)
+}
+
+// GenerateShareViewToken issues a token for the page of a view of shareID
+// that has been counted, so that the requests of that page are let through
+// after the last allowed view
+func GenerateShareViewToken(shareID string, ttl time.Duration) (string, error) {
+	return generateShareClaims(shareID, "", ttl, shareViewTokenAudience)
+}
+
+// ValidateShareViewToken checks that tokenString was issued for a counted
+// view of shareID
+func ValidateShareViewToken(tokenString string, shareID string) error {
+	return validateShareClaims(tokenString, shareID, "", shareViewTokenAudience)
+}
+
+func generateShareClaims(shareID string, key string, ttl time.Duration, audience string) (string, error) {
+	now := time.Now()
+	claims := &ShareClaims{
+		ShareID: shareID,
+		Key:     key,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "elib-api",
+			Audience:  jwt.ClaimStrings{audience},
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(env.Get().JWTSecret))
+}
+
+func validateShareClaims(tokenString string, shareID string, key string, audience string) error {
+	claims := &ShareClaims{}
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		claims,
+		func(token *jwt.Token) (interface{}, error) {
+			return []byte(env.Get().JWTSecret), nil
+		},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(audience),
+	)
+	if err != nil {
+		return err
+	}
+	if !token.Valid || claims.ShareID != shareID || claims.Key != key {
+		return errors.New("invalid share token")
+	}
+	return nil
+}
+
 func generateToken(userID string) (string, error) {
 	expirationTime := time.Now().Add(time.Hour * 24 * 7)
 
@@ -0,0 +1,43 @@
+package secure
+
+import (
+	"bytes"
+	"html/template"
+	"log/slog"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+var (
+	markdown = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+	)
+	markdownPolicy = newMarkdownPolicy()
+)
+
+// newMarkdownPolicy allows the HTML that GFM produces for user content and
+// strips everything else, such as scripts, event handlers and styles.
+func newMarkdownPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	// フェンスコードブロックの言語指定
+	policy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	// タスクリストのチェックボックス
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).OnElements("input")
+	policy.RequireNoReferrerOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	return policy
+}
+
+// RenderMarkdown converts CommonMark/GFM source into sanitized HTML.
+func RenderMarkdown(source string) template.HTML {
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(source), &buf); err != nil {
+		slog.Error("failed to render markdown", "error", err)
+		return escapeHTML(source)
+	}
+	return template.HTML(markdownPolicy.SanitizeBytes(buf.Bytes()))
+}
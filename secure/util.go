@@ -4,6 +4,7 @@ import (
 	"html"
 	"html/template"
 	"strings"
+	"unicode/utf8"
 )
 
 func escapeHTML(input string) template.HTML {
@@ -31,13 +32,25 @@ func ValidateTextInput(input string, maxLength int) (string, bool) {
 		return "", false
 	}
 
+	// Most input has nothing to remove and is returned as it is
+	if utf8.ValidString(input) && strings.IndexFunc(input, isRemovedTextRune) < 0 {
+		return input, true
+	}
+
 	// Remove null bytes and other control characters except newlines and tabs
-	cleanInput := ""
+	var cleanInput strings.Builder
+	cleanInput.Grow(len(input))
 	for _, r := range input {
-		if r == '\n' || r == '\r' || r == '\t' || (r >= 32 && r < 127) || r >= 160 {
-			cleanInput += string(r)
+		if !isRemovedTextRune(r) {
+			cleanInput.WriteRune(r)
 		}
 	}
 
-	return cleanInput, true
+	return cleanInput.String(), true
+}
+
+// isRemovedTextRune reports whether ValidateTextInput removes r. Invalid
+// UTF-8 is kept as U+FFFD.
+func isRemovedTextRune(r rune) bool {
+	return !(r == '\n' || r == '\r' || r == '\t' || (r >= 32 && r < 127) || r >= 160)
 }
@@ -0,0 +1,13 @@
+CREATE TABLE note_revisions (
+    id TEXT PRIMARY KEY NOT NULL,
+    note_id TEXT NOT NULL,
+    title TEXT NOT NULL,
+    content TEXT NOT NULL,
+    author_id TEXT,
+    share_id TEXT,
+    restored_from TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_note_revisions_note_id_created_at ON note_revisions(note_id, created_at);
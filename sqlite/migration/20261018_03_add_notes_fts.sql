@@ -0,0 +1,43 @@
+-- notes の主キーは TEXT のため、暗黙の rowid は VACUUM で振り直されることがある。
+-- 索引の行と対応付けるため、変わらない整数の列 search_id を持たせる
+ALTER TABLE notes ADD COLUMN search_id INTEGER;
+-- 既存のメモの更新日時を変えないよう、振り分けの間はトリガーを外す
+DROP TRIGGER IF EXISTS update_notes_updated_at;
+UPDATE notes SET search_id = rowid;
+CREATE TRIGGER update_notes_updated_at
+    AFTER UPDATE ON notes
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE notes SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+CREATE UNIQUE INDEX idx_notes_search_id ON notes(search_id);
+
+-- 日本語を扱えるよう trigram トークナイザーを使用する
+CREATE VIRTUAL TABLE notes_fts USING fts5(
+    title,
+    content,
+    content='notes',
+    content_rowid='search_id',
+    tokenize='trigram'
+);
+INSERT INTO notes_fts(notes_fts) VALUES ('rebuild');
+CREATE TRIGGER notes_fts_after_insert
+    AFTER INSERT ON notes
+BEGIN
+    UPDATE notes SET search_id = (SELECT coalesce(max(search_id), 0) + 1 FROM notes)
+        WHERE id = NEW.id AND search_id IS NULL;
+    INSERT INTO notes_fts(rowid, title, content)
+        SELECT search_id, title, content FROM notes WHERE id = NEW.id;
+END;
+CREATE TRIGGER notes_fts_after_delete
+    AFTER DELETE ON notes
+BEGIN
+    INSERT INTO notes_fts(notes_fts, rowid, title, content) VALUES ('delete', OLD.search_id, OLD.title, OLD.content);
+END;
+CREATE TRIGGER notes_fts_after_update
+    AFTER UPDATE OF title, content ON notes
+BEGIN
+    INSERT INTO notes_fts(notes_fts, rowid, title, content) VALUES ('delete', OLD.search_id, OLD.title, OLD.content);
+    INSERT INTO notes_fts(rowid, title, content) VALUES (NEW.search_id, NEW.title, NEW.content);
+END;
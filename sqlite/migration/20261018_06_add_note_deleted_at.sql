@@ -0,0 +1,2 @@
+ALTER TABLE notes ADD COLUMN deleted_at DATETIME;
+CREATE INDEX idx_notes_deleted_at ON notes(deleted_at);
@@ -0,0 +1 @@
+ALTER TABLE notes ADD COLUMN format TEXT NOT NULL DEFAULT 'plain';
@@ -0,0 +1,12 @@
+CREATE TABLE attachments (
+    id TEXT PRIMARY KEY NOT NULL,
+    note_id TEXT NOT NULL,
+    blob_key TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_attachments_note_id ON attachments(note_id);
+CREATE INDEX idx_attachments_blob_key ON attachments(blob_key);
@@ -0,0 +1,4 @@
+CREATE INDEX idx_notes_author_updated_at ON notes(author_id, updated_at);
+CREATE INDEX idx_notes_author_created_at ON notes(author_id, created_at);
+CREATE INDEX idx_notes_author_title ON notes(author_id, title);
+DROP INDEX IF EXISTS idx_notes_author_id;
@@ -0,0 +1,34 @@
+ALTER TABLE notes ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT 0;
+ALTER TABLE notes ADD COLUMN favorite BOOLEAN NOT NULL DEFAULT 0;
+-- 手動の並び順。fractional indexing のキーで、文字列として比較する
+ALTER TABLE notes ADD COLUMN sort_key TEXT;
+
+-- 既存のメモは更新日時の新しい順に並べる（"a0" の後ろに小数部を付けたキー）
+UPDATE notes SET sort_key = (
+    SELECT 'a0' || printf('%08d', r.rn) || '1'
+    FROM (
+        SELECT id, row_number() OVER (PARTITION BY author_id ORDER BY updated_at DESC, id) AS rn
+        FROM notes
+    ) r
+    WHERE r.id = notes.id
+);
+
+-- ピン留めしたメモを先に並べるため、一覧の並び順の索引に pinned を加える
+DROP INDEX IF EXISTS idx_notes_author_updated_at;
+DROP INDEX IF EXISTS idx_notes_author_created_at;
+DROP INDEX IF EXISTS idx_notes_author_title;
+CREATE INDEX idx_notes_author_updated_at ON notes(author_id, pinned, updated_at);
+CREATE INDEX idx_notes_author_created_at ON notes(author_id, pinned, created_at);
+CREATE INDEX idx_notes_author_title ON notes(author_id, pinned, title);
+CREATE INDEX idx_notes_author_sort_key ON notes(author_id, pinned, sort_key);
+
+-- 内容の変更でのみメモの更新日時を変える。ピン留め・お気に入り・並び順・
+-- フォルダの移動・表示形式の変更では変えない
+DROP TRIGGER IF EXISTS update_notes_updated_at;
+CREATE TRIGGER update_notes_updated_at
+    AFTER UPDATE OF author_id, title, content, version, deleted_at ON notes
+    FOR EACH ROW
+    WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE notes SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
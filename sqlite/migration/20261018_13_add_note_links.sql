@@ -0,0 +1,12 @@
+-- メモ本文の [[タイトル]] リンク。target_id はリンク先のメモで、見つからない（リンク切れの）場合は NULL
+CREATE TABLE note_links (
+    source_id TEXT NOT NULL,
+    target_title TEXT NOT NULL,
+    target_id TEXT,
+    PRIMARY KEY (source_id, target_title),
+    FOREIGN KEY (source_id) REFERENCES notes(id) ON DELETE CASCADE,
+    FOREIGN KEY (target_id) REFERENCES notes(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_note_links_target_id ON note_links(target_id);
+CREATE INDEX idx_note_links_target_title ON note_links(target_title);
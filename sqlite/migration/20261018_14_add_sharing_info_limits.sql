@@ -0,0 +1,7 @@
+-- 共有リンクの有効期限。NULL は無期限
+ALTER TABLE sharing_info ADD COLUMN expires_at DATETIME;
+-- 閲覧できる回数。NULL は無制限、1 は一度だけ閲覧できるリンク
+ALTER TABLE sharing_info ADD COLUMN max_views INTEGER;
+ALTER TABLE sharing_info ADD COLUMN view_count INTEGER NOT NULL DEFAULT 0;
+-- 編集可能なリンクを閲覧のみに切り替える日時。NULL は切り替えない
+ALTER TABLE sharing_info ADD COLUMN editable_until DATETIME;
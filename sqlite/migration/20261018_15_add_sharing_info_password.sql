@@ -0,0 +1,2 @@
+-- 共有リンクのパスワード（bcrypt ハッシュ）。NULL はパスワードなし
+ALTER TABLE sharing_info ADD COLUMN password_hash BLOB;
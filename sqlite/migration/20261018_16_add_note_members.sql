@@ -0,0 +1,13 @@
+-- メモを共有したユーザーと権限。role は viewer（閲覧者）/ commenter（コメント可）/ editor（編集者）
+CREATE TABLE note_members (
+    note_id TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (note_id, user_id),
+    FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_note_members_user_id ON note_members(user_id);
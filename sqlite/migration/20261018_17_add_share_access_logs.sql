@@ -0,0 +1,16 @@
+-- 共有リンクの閲覧・編集の記録。ip_hash は IP アドレスそのものではなくハッシュ値
+CREATE TABLE share_access_logs (
+    id TEXT PRIMARY KEY NOT NULL,
+    share_id TEXT NOT NULL,
+    note_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    ip_hash TEXT NOT NULL,
+    user_agent TEXT NOT NULL,
+    user_id TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (share_id) REFERENCES sharing_info(id) ON DELETE CASCADE,
+    FOREIGN KEY (note_id) REFERENCES notes(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_share_access_logs_share_id_created_at ON share_access_logs(share_id, created_at);
+CREATE INDEX idx_share_access_logs_note_id_created_at ON share_access_logs(note_id, created_at);
+CREATE INDEX idx_share_access_logs_created_at ON share_access_logs(created_at);
@@ -0,0 +1,2 @@
+-- 変更した人の表示名。共有リンクの編集者が入力した名前か、ログイン中のユーザーのメールアドレス
+ALTER TABLE note_revisions ADD COLUMN editor_name TEXT;
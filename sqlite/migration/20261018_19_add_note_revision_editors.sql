@@ -0,0 +1,12 @@
+-- 共同編集では1回の保存に複数の編集者の変更が含まれる。
+-- note_revisions には最後に変更した編集者を、ここにはそれ以外の編集者を記録する
+CREATE TABLE note_revision_editors (
+    revision_id TEXT NOT NULL,
+    note_id TEXT NOT NULL,
+    author_id TEXT,
+    share_id TEXT,
+    editor_name TEXT,
+    FOREIGN KEY (revision_id) REFERENCES note_revisions(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_note_revision_editors_revision_id ON note_revision_editors(revision_id);
+CREATE INDEX idx_note_revision_editors_note_id ON note_revision_editors(note_id);
@@ -0,0 +1,351 @@
+// API ドキュメント画面用JavaScript
+// /api/openapi.json を読み込んで操作の一覧を表示する
+
+const HTTP_METHODS = ["get", "post", "put", "patch", "delete"];
+
+let apiSpec = null;
+
+function el(tag, attrs, ...children) {
+  const element = document.createElement(tag);
+  Object.entries(attrs || {}).forEach(([key, value]) => {
+    if (key === "className") {
+      element.className = value;
+    } else {
+      element.setAttribute(key, value);
+    }
+  });
+  children.forEach((child) => {
+    if (child === null || child === undefined) {
+      return;
+    }
+    element.append(child instanceof Node ? child : String(child));
+  });
+  return element;
+}
+
+// $ref を components のスキーマに置き換える
+function resolveSchema(schema) {
+  if (schema && schema.$ref) {
+    const name = schema.$ref.replace("#/components/schemas/", "");
+    return { name: name, schema: apiSpec.components.schemas[name] || {} };
+  }
+  return { name: null, schema: schema || {} };
+}
+
+function schemaTypeLabel(schema) {
+  let label = schema.type || "any";
+  if (schema.format) {
+    label += "(" + schema.format + ")";
+  }
+  if (schema.enum) {
+    label += " " + schema.enum.map((value) => JSON.stringify(value)).join(" | ");
+  }
+  if (schema.nullable) {
+    label += " | null";
+  }
+  return label;
+}
+
+// スキーマを JSON に似た形の文字列にする。循環する参照は型名だけを表示する
+function schemaToText(schema, indent, seen) {
+  const resolved = resolveSchema(schema);
+  const target = resolved.schema;
+  if (resolved.name) {
+    if (seen.includes(resolved.name)) {
+      return resolved.name;
+    }
+    seen = seen.concat(resolved.name);
+  }
+
+  const pad = "  ".repeat(indent);
+  if (target.oneOf) {
+    return target.oneOf.map((item) => schemaToText(item, indent, seen)).join(" | ");
+  }
+  if (target.type === "array") {
+    return "[" + schemaToText(target.items, indent, seen) + "]";
+  }
+  if (target.properties) {
+    const required = target.required || [];
+    const lines = Object.keys(target.properties)
+      .sort()
+      .map((key) => {
+        const mark = required.includes(key) ? "" : "?";
+        return pad + "  " + JSON.stringify(key) + mark + ": " + schemaToText(target.properties[key], indent + 1, seen);
+      });
+    return "{\n" + lines.join(",\n") + "\n" + pad + "}";
+  }
+  if (target.additionalProperties) {
+    return "{ [key: string]: " + schemaToText(target.additionalProperties, indent, seen) + " }";
+  }
+  let text = schemaTypeLabel(target);
+  if (target.description) {
+    text += "  // " + target.description;
+  }
+  return text;
+}
+
+// 「試す」の本文の初期値をスキーマから作る
+function exampleFor(schema, seen) {
+  const resolved = resolveSchema(schema);
+  const target = resolved.schema;
+  if (resolved.name) {
+    if (seen.includes(resolved.name)) {
+      return null;
+    }
+    seen = seen.concat(resolved.name);
+  }
+  if (target.enum) {
+    return target.enum[0];
+  }
+  switch (target.type) {
+    case "object": {
+      const example = {};
+      Object.keys(target.properties || {}).forEach((key) => {
+        example[key] = exampleFor(target.properties[key], seen);
+      });
+      return example;
+    }
+    case "array":
+      return [];
+    case "integer":
+    case "number":
+      return 0;
+    case "boolean":
+      return false;
+    case "string":
+      return "";
+    default:
+      return null;
+  }
+}
+
+function renderParameters(op) {
+  const parameters = op.parameters || [];
+  if (parameters.length === 0) {
+    return null;
+  }
+  const rows = parameters.map((param) =>
+    el(
+      "tr",
+      {},
+      el("td", {}, el("code", {}, param.name), param.required ? " *" : ""),
+      el("td", {}, param.in),
+      el("td", {}, schemaTypeLabel(param.schema || {})),
+      el("td", {}, param.description || "")
+    )
+  );
+  return el(
+    "div",
+    {},
+    el("h4", {}, "パラメーター"),
+    el(
+      "table",
+      {},
+      el("thead", {}, el("tr", {}, el("th", {}, "名前"), el("th", {}, "場所"), el("th", {}, "型"), el("th", {}, "説明"))),
+      el("tbody", {}, ...rows)
+    )
+  );
+}
+
+function renderContent(content) {
+  return Object.entries(content || {}).map(([type, media]) =>
+    el(
+      "div",
+      {},
+      el("span", { className: "docs-badge" }, type),
+      media.schema ? el("pre", {}, schemaToText(media.schema, 0, [])) : null
+    )
+  );
+}
+
+function renderResponses(op) {
+  const rows = Object.keys(op.responses)
+    .sort()
+    .map((status) => {
+      const response = op.responses[status];
+      const headers = Object.entries(response.headers || {}).map(([name, header]) =>
+        el("div", { className: "docs-muted" }, name + ": " + header.description)
+      );
+      return el(
+        "tr",
+        {},
+        el("td", {}, el("strong", {}, status)),
+        el("td", {}, response.description, ...headers, ...renderContent(response.content))
+      );
+    });
+  return el("div", {}, el("h4", {}, "レスポンス"), el("table", {}, el("tbody", {}, ...rows)));
+}
+
+// JSON の本文を送る操作とパラメーターだけの操作を試せるようにする
+function isTryable(op) {
+  const requestTypes = Object.keys((op.requestBody && op.requestBody.content) || {});
+  if (requestTypes.some((type) => type !== "application/json")) {
+    return false;
+  }
+  return !Object.values(op.responses).some(
+    (response) => response.content && response.content["text/event-stream"]
+  ) && !op.responses["101"];
+}
+
+function renderTry(path, method, op) {
+  const inputs = {};
+  const fields = (op.parameters || []).map((param) => {
+    const input = el("input", { type: "text", placeholder: param.name });
+    inputs[param.name] = { param: param, input: input };
+    return el("label", {}, param.name + " (" + param.in + ")", input);
+  });
+
+  let bodyInput = null;
+  const jsonBody = op.requestBody && op.requestBody.content["application/json"];
+  if (jsonBody) {
+    bodyInput = el("textarea", { rows: "6" });
+    bodyInput.value = JSON.stringify(exampleFor(jsonBody.schema, []), null, 2);
+  }
+
+  const result = el("pre", {});
+  result.hidden = true;
+  const button = el("button", { type: "button" }, "送信");
+  button.addEventListener("click", () => {
+    let url = path;
+    const query = new URLSearchParams();
+    Object.values(inputs).forEach(({ param, input }) => {
+      if (param.in === "path") {
+        url = url.replace("{" + param.name + "}", encodeURIComponent(input.value));
+      } else if (input.value !== "") {
+        query.append(param.name, input.value);
+      }
+    });
+    if (query.toString()) {
+      url += "?" + query.toString();
+    }
+
+    const headers = {};
+    const token = document.getElementById("docs-token").value.trim();
+    if (token) {
+      headers["Authorization"] = "Bearer " + token;
+    }
+    const options = { method: method.toUpperCase(), headers: headers, redirect: "manual" };
+    if (bodyInput) {
+      headers["Content-Type"] = "application/json";
+      options.body = bodyInput.value;
+    }
+
+    result.hidden = false;
+    result.textContent = "送信中...";
+    fetch(url, options)
+      .then((response) =>
+        response.text().then((text) => {
+          let body = text;
+          try {
+            body = JSON.stringify(JSON.parse(text), null, 2);
+          } catch (e) {
+            // JSON 以外はそのまま表示する
+          }
+          result.textContent = response.status + " " + response.statusText + "\n\n" + body;
+        })
+      )
+      .catch((error) => {
+        result.textContent = "エラー: " + error;
+      });
+  });
+
+  return el(
+    "div",
+    { className: "docs-try" },
+    el("h4", {}, "試す"),
+    ...fields,
+    bodyInput ? el("label", {}, "本文", bodyInput) : null,
+    button,
+    result
+  );
+}
+
+function renderOperation(path, method, op) {
+  const body = el("div", { className: "docs-operation-body" });
+  if (op.description) {
+    body.append(el("p", {}, op.description));
+  }
+  if (op.security) {
+    const schemes = op.security.map((requirement) => Object.keys(requirement).join(" + ")).join(" または ");
+    body.append(el("p", { className: "docs-muted" }, "認証: " + schemes));
+  }
+  if (op["x-required-scope"]) {
+    body.append(el("p", { className: "docs-muted" }, "アクセストークンに必要なスコープ: ", el("code", {}, op["x-required-scope"])));
+  }
+  body.append(renderParameters(op) || "");
+  if (op.requestBody) {
+    body.append(el("h4", {}, "リクエスト本文"), ...renderContent(op.requestBody.content));
+  }
+  body.append(renderResponses(op));
+  if (isTryable(op)) {
+    body.append(renderTry(path, method, op));
+  }
+
+  return el(
+    "details",
+    { className: "docs-operation", id: op.operationId },
+    el(
+      "summary",
+      {},
+      el("span", { className: "docs-method docs-method-" + method }, method.toUpperCase()),
+      el("span", { className: "docs-path" }, path),
+      el("span", { className: "docs-summary" }, op.summary || "")
+    ),
+    body
+  );
+}
+
+function renderSpec() {
+  document.getElementById("docs-description").textContent = apiSpec.info.description || "";
+
+  // 分類ごとにまとめる
+  const groups = {};
+  Object.keys(apiSpec.paths)
+    .sort()
+    .forEach((path) => {
+      HTTP_METHODS.forEach((method) => {
+        const op = apiSpec.paths[path][method];
+        if (!op) {
+          return;
+        }
+        const tag = (op.tags && op.tags[0]) || "other";
+        (groups[tag] = groups[tag] || []).push({ path: path, method: method, op: op });
+      });
+    });
+
+  const nav = document.getElementById("docs-nav");
+  const container = document.getElementById("docs-operations");
+  Object.keys(groups)
+    .sort()
+    .forEach((tag) => {
+      nav.append(el("h2", {}, tag));
+      container.append(el("h3", { className: "docs-tag", id: "tag-" + tag }, tag));
+      groups[tag].forEach(({ path, method, op }) => {
+        nav.append(el("a", { href: "#" + op.operationId, title: path }, method.toUpperCase() + " " + path));
+        container.append(renderOperation(path, method, op));
+      });
+    });
+
+  // 目次から開いた操作を展開する
+  const openFromHash = () => {
+    const target = document.getElementById(decodeURIComponent(location.hash.slice(1)));
+    if (target && target.tagName === "DETAILS") {
+      target.open = true;
+    }
+  };
+  window.addEventListener("hashchange", openFromHash);
+  openFromHash();
+}
+
+document.addEventListener("DOMContentLoaded", () => {
+  fetch("/api/openapi.json")
+    .then((response) => response.json())
+    .then((spec) => {
+      apiSpec = spec;
+      renderSpec();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      document.getElementById("docs-operations").textContent = "仕様書の読み込みに失敗しました";
+    });
+});
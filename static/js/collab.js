@@ -0,0 +1,609 @@
+// 共同編集用JavaScript
+// WebSocket でサーバーと接続し、テキストエリアの編集を操作変換（OT）で同期する。
+// 操作の形式は ot.js と同じで、正の数が保持・負の数が削除・文字列が挿入を表す。
+// 位置と長さはコードポイント単位で数える（サーバー側の usecase.TextOp と同じ）
+
+const collabState = {
+  socket: null,
+  path: "",
+  handlers: {},
+  // 再接続しても同じ ID を使い、送信済みの操作が二重に適用されないようにする
+  clientId: "",
+  epoch: "",
+  // サーバーから受け取った最新のリビジョンと、その時点の本文
+  rev: 0,
+  baseText: "",
+  // テキストエリアに反映済みの本文（未確認の自分の操作を含む）
+  text: "",
+  seq: 0,
+  // 送信して確認待ちの操作と、その間に溜まった操作
+  awaiting: null,
+  awaitingSeq: 0,
+  buffer: null,
+  pendingTitle: null,
+  initialized: false,
+  composing: false,
+  queuedMessages: [],
+  reconnectDelay: 1000,
+  stopped: false,
+};
+
+const collabMaxReconnectDelay = 30000;
+
+// ---- テキスト操作 ----
+
+function isRetainComponent(c) {
+  return typeof c === "number" && c > 0;
+}
+
+function isDeleteComponent(c) {
+  return typeof c === "number" && c < 0;
+}
+
+function isInsertComponent(c) {
+  return typeof c === "string";
+}
+
+function codePointLength(text) {
+  let length = 0;
+  for (const _ of text) {
+    length++;
+  }
+  return length;
+}
+
+function codePointSlice(text, start, end) {
+  return Array.from(text).slice(start, end).join("");
+}
+
+function textOpRetain(op, n) {
+  if (n <= 0) return op;
+  const last = op.length - 1;
+  if (last >= 0 && isRetainComponent(op[last])) {
+    op[last] += n;
+  } else {
+    op.push(n);
+  }
+  return op;
+}
+
+// 削除の直後の挿入は削除の前に置き、同じ編集が常に同じ形になるようにする
+function textOpInsert(op, text) {
+  if (!text) return op;
+  const last = op.length - 1;
+  if (last >= 0 && isInsertComponent(op[last])) {
+    op[last] += text;
+  } else if (last >= 0 && isDeleteComponent(op[last])) {
+    if (last > 0 && isInsertComponent(op[last - 1])) {
+      op[last - 1] += text;
+    } else {
+      op.push(op[last]);
+      op[last] = text;
+    }
+  } else {
+    op.push(text);
+  }
+  return op;
+}
+
+function textOpDelete(op, n) {
+  if (n <= 0) return op;
+  const last = op.length - 1;
+  if (last >= 0 && isDeleteComponent(op[last])) {
+    op[last] -= n;
+  } else {
+    op.push(-n);
+  }
+  return op;
+}
+
+function textOpBaseLength(op) {
+  return op.reduce((sum, c) => (isInsertComponent(c) ? sum : sum + Math.abs(c)), 0);
+}
+
+function isNoopTextOp(op) {
+  return op.every(isRetainComponent);
+}
+
+function applyTextOp(op, text) {
+  const chars = Array.from(text);
+  if (textOpBaseLength(op) !== chars.length) {
+    throw new Error("操作の長さが本文と一致しません");
+  }
+  const parts = [];
+  let pos = 0;
+  for (const c of op) {
+    if (isRetainComponent(c)) {
+      parts.push(chars.slice(pos, pos + c).join(""));
+      pos += c;
+    } else if (isInsertComponent(c)) {
+      parts.push(c);
+    } else {
+      pos -= c;
+    }
+  }
+  return parts.join("");
+}
+
+/**
+ * 操作 a の後に続けて操作 b を適用するのと同じ結果になる1つの操作を返す
+ */
+function composeTextOps(a, b) {
+  const result = [];
+  let i1 = 0;
+  let i2 = 0;
+  let c1 = a[i1++];
+  let c2 = b[i2++];
+  while (c1 !== undefined || c2 !== undefined) {
+    if (isDeleteComponent(c1)) {
+      textOpDelete(result, -c1);
+      c1 = a[i1++];
+      continue;
+    }
+    if (isInsertComponent(c2)) {
+      textOpInsert(result, c2);
+      c2 = b[i2++];
+      continue;
+    }
+    if (c1 === undefined || c2 === undefined) {
+      throw new Error("合成できない操作です");
+    }
+
+    if (isRetainComponent(c1) && isRetainComponent(c2)) {
+      const n = Math.min(c1, c2);
+      textOpRetain(result, n);
+      c1 = c1 > n ? c1 - n : a[i1++];
+      c2 = c2 > n ? c2 - n : b[i2++];
+    } else if (isInsertComponent(c1) && isDeleteComponent(c2)) {
+      // 挿入した文字をすぐに削除した場合は何も残らない
+      const length = codePointLength(c1);
+      const n = Math.min(length, -c2);
+      c1 = length > n ? codePointSlice(c1, n) : a[i1++];
+      c2 = -c2 > n ? c2 + n : b[i2++];
+    } else if (isInsertComponent(c1) && isRetainComponent(c2)) {
+      const length = codePointLength(c1);
+      const n = Math.min(length, c2);
+      textOpInsert(result, codePointSlice(c1, 0, n));
+      c1 = length > n ? codePointSlice(c1, n) : a[i1++];
+      c2 = c2 > n ? c2 - n : b[i2++];
+    } else {
+      // 保持した範囲を b が削除する
+      const n = Math.min(c1, -c2);
+      textOpDelete(result, n);
+      c1 = c1 > n ? c1 - n : a[i1++];
+      c2 = -c2 > n ? c2 + n : b[i2++];
+    }
+  }
+  return result;
+}
+
+/**
+ * 同じ本文に対して同時に行われた操作 a, b を変換し [a', b'] を返す
+ * a の後に b'、b の後に a' を適用すると同じ本文になる。同じ位置への挿入は a が先になる
+ */
+function transformTextOps(a, b) {
+  if (textOpBaseLength(a) !== textOpBaseLength(b)) {
+    throw new Error("変換できない操作です");
+  }
+  const aPrime = [];
+  const bPrime = [];
+  let i1 = 0;
+  let i2 = 0;
+  let c1 = a[i1++];
+  let c2 = b[i2++];
+  while (c1 !== undefined || c2 !== undefined) {
+    if (isInsertComponent(c1)) {
+      textOpInsert(aPrime, c1);
+      textOpRetain(bPrime, codePointLength(c1));
+      c1 = a[i1++];
+      continue;
+    }
+    if (isInsertComponent(c2)) {
+      textOpRetain(aPrime, codePointLength(c2));
+      textOpInsert(bPrime, c2);
+      c2 = b[i2++];
+      continue;
+    }
+    if (c1 === undefined || c2 === undefined) {
+      throw new Error("変換できない操作です");
+    }
+
+    const n = Math.min(Math.abs(c1), Math.abs(c2));
+    if (isRetainComponent(c1) && isRetainComponent(c2)) {
+      textOpRetain(aPrime, n);
+      textOpRetain(bPrime, n);
+    } else if (isDeleteComponent(c1) && isRetainComponent(c2)) {
+      textOpDelete(aPrime, n);
+    } else if (isRetainComponent(c1) && isDeleteComponent(c2)) {
+      textOpDelete(bPrime, n);
+    }
+    // 両方が削除した範囲はどちらの結果にも現れない
+    c1 = Math.abs(c1) > n ? c1 - Math.sign(c1) * n : a[i1++];
+    c2 = Math.abs(c2) > n ? c2 - Math.sign(c2) * n : b[i2++];
+  }
+  return [aPrime, bPrime];
+}
+
+/**
+ * 共通の先頭と末尾を除いた部分を置き換える操作を返す
+ */
+function diffTextOp(oldText, newText) {
+  let start = 0;
+  while (start < oldText.length && start < newText.length && oldText[start] === newText[start]) {
+    start++;
+  }
+  // サロゲートペアの途中で区切らない
+  if (start > 0 && isHighSurrogate(oldText.charCodeAt(start - 1))) {
+    start--;
+  }
+  let end = 0;
+  while (
+    end < oldText.length - start &&
+    end < newText.length - start &&
+    oldText[oldText.length - 1 - end] === newText[newText.length - 1 - end]
+  ) {
+    end++;
+  }
+  if (end > 0 && isLowSurrogate(oldText.charCodeAt(oldText.length - end))) {
+    end--;
+  }
+
+  const op = [];
+  textOpRetain(op, codePointLength(oldText.slice(0, start)));
+  textOpInsert(op, newText.slice(start, newText.length - end));
+  textOpDelete(op, codePointLength(oldText.slice(start, oldText.length - end)));
+  textOpRetain(op, codePointLength(oldText.slice(oldText.length - end)));
+  return op;
+}
+
+function isHighSurrogate(code) {
+  return code >= 0xd800 && code <= 0xdbff;
+}
+
+function isLowSurrogate(code) {
+  return code >= 0xdc00 && code <= 0xdfff;
+}
+
+/**
+ * 操作を適用した後のカーソル位置（コードポイント単位）を返す
+ * カーソルと同じ位置への他の人の挿入ではカーソルを動かさない
+ */
+function transformCursor(op, cursor) {
+  let pos = 0;
+  let shift = 0;
+  for (const c of op) {
+    if (pos >= cursor) break;
+    if (isRetainComponent(c)) {
+      pos += c;
+    } else if (isInsertComponent(c)) {
+      shift += codePointLength(c);
+    } else {
+      shift -= Math.min(-c, cursor - pos);
+      pos -= c;
+    }
+  }
+  return cursor + shift;
+}
+
+// ---- テキストエリアとの連携 ----
+
+function collabTextarea() {
+  return document.getElementById("note-content");
+}
+
+// テキストエリアの内容を置き換え、カーソルと選択範囲を操作に合わせて移動する
+function replaceTextareaContent(newText, op) {
+  const textarea = collabTextarea();
+  const oldText = textarea.value;
+  const toCodePoint = (index) => codePointLength(oldText.slice(0, index));
+  const toUtf16 = (text, index) => codePointSlice(text, 0, index).length;
+  const start = transformCursor(op, toCodePoint(textarea.selectionStart));
+  const end = transformCursor(op, toCodePoint(textarea.selectionEnd));
+  const scrollTop = textarea.scrollTop;
+
+  textarea.value = newText;
+  if (document.activeElement === textarea) {
+    textarea.setSelectionRange(toUtf16(newText, start), toUtf16(newText, end));
+  }
+  textarea.scrollTop = scrollTop;
+  collabState.text = newText;
+  updateStats();
+}
+
+function onCollabInput(event) {
+  if (collabState.composing || (event && event.isComposing)) return;
+  const value = collabTextarea().value;
+  if (value === collabState.text) return;
+  const op = diffTextOp(collabState.text, value);
+  collabState.text = value;
+  applyClientOp(op);
+}
+
+function onCollabCompositionStart() {
+  collabState.composing = true;
+}
+
+// 変換確定までに届いたメッセージは確定後にまとめて処理する
+function onCollabCompositionEnd() {
+  collabState.composing = false;
+  onCollabInput();
+  const queued = collabState.queuedMessages;
+  collabState.queuedMessages = [];
+  queued.forEach(handleCollabMessage);
+}
+
+// ---- 同期の状態遷移 ----
+
+function applyClientOp(op) {
+  if (isNoopTextOp(op)) return;
+  if (collabState.awaiting === null) {
+    collabState.awaiting = op;
+    collabState.awaitingSeq = ++collabState.seq;
+    sendAwaitingOp();
+  } else if (collabState.buffer === null) {
+    collabState.buffer = op;
+  } else {
+    collabState.buffer = composeTextOps(collabState.buffer, op);
+  }
+}
+
+// 接続していない間は送らず、再接続時に送り直す
+function sendAwaitingOp() {
+  sendCollabMessage({
+    type: "op",
+    rev: collabState.rev,
+    seq: collabState.awaitingSeq,
+    op: collabState.awaiting,
+  });
+}
+
+function sendCollabMessage(message) {
+  const socket = collabState.socket;
+  if (socket && socket.readyState === WebSocket.OPEN) {
+    socket.send(JSON.stringify(message));
+  }
+}
+
+function handleAck(message) {
+  if (collabState.awaiting === null || message.seq !== collabState.awaitingSeq) return;
+  collabState.baseText = applyTextOp(collabState.awaiting, collabState.baseText);
+  collabState.rev = message.rev;
+  collabState.awaiting = null;
+  if (collabState.buffer !== null) {
+    collabState.awaiting = collabState.buffer;
+    collabState.awaitingSeq = ++collabState.seq;
+    collabState.buffer = null;
+    sendAwaitingOp();
+  }
+}
+
+function handleRemoteOp(message) {
+  let op = message.op;
+  collabState.baseText = applyTextOp(op, collabState.baseText);
+  collabState.rev = message.rev;
+  if (collabState.awaiting !== null) {
+    [collabState.awaiting, op] = transformTextOps(collabState.awaiting, op);
+  }
+  if (collabState.buffer !== null) {
+    [collabState.buffer, op] = transformTextOps(collabState.buffer, op);
+  }
+  replaceTextareaContent(applyTextOp(op, collabState.text), op);
+}
+
+// 文書全体を受け取ったときの処理
+// 別のセッションに接続し直した場合は、未送信の変更をサーバーの本文に合わせて変換して送り直す
+function handleInit(message) {
+  let pending = null;
+  if (collabState.initialized && collabState.epoch !== message.epoch) {
+    pending = collabState.awaiting;
+    if (collabState.buffer !== null) {
+      pending = pending === null ? collabState.buffer : composeTextOps(pending, collabState.buffer);
+    }
+  } else if (collabState.awaiting !== null) {
+    showToast("共同編集の同期をやり直しました。直前の変更が反映されていない可能性があります", "error");
+  }
+
+  const oldText = collabState.text;
+  let newText = message.content;
+  let local = null;
+  if (pending !== null) {
+    const external = diffTextOp(collabState.baseText, message.content);
+    [local] = transformTextOps(pending, external);
+    newText = applyTextOp(local, message.content);
+  }
+
+  collabState.epoch = message.epoch;
+  collabState.rev = message.rev;
+  collabState.baseText = message.content;
+  collabState.awaiting = null;
+  collabState.buffer = null;
+  collabState.initialized = true;
+  replaceTextareaContent(newText, diffTextOp(oldText, newText));
+  if (local !== null) {
+    applyClientOp(local);
+  }
+  if (collabState.pendingTitle === null) {
+    collabState.handlers.onTitle(message.title);
+  }
+  collabState.handlers.onSaved(message.version, collabState.awaiting === null);
+}
+
+// 同じセッションに再接続した場合は、確認待ちの操作を元の番号のまま送り直す
+// 既に適用されていればサーバーは無視し、続く履歴の中で確認が届く
+function handleResume() {
+  if (collabState.awaiting !== null) {
+    sendAwaitingOp();
+  }
+}
+
+function handleCollabMessage(message) {
+  if (collabState.composing && (message.type === "op" || message.type === "init")) {
+    collabState.queuedMessages.push(message);
+    return;
+  }
+
+  switch (message.type) {
+    case "init":
+      handleInit(message);
+      flushPendingTitle();
+      break;
+    case "resume":
+      handleResume();
+      flushPendingTitle();
+      break;
+    case "ack":
+      handleAck(message);
+      break;
+    case "op":
+      handleRemoteOp(message);
+      break;
+    case "title":
+      if (collabState.pendingTitle === null) {
+        collabState.handlers.onTitle(message.title);
+      }
+      break;
+    case "presence":
+      renderCollabPresence(message.clients);
+      break;
+    case "saved":
+      collabState.handlers.onSaved(
+        message.version,
+        collabState.awaiting === null && collabState.buffer === null
+      );
+      break;
+    case "error":
+      if (message.code === "gone") {
+        stopCollaboration();
+        showToast("このメモは削除されました", "error");
+      } else if (message.code === "revoked") {
+        stopCollaboration();
+        collabTextarea().readOnly = true;
+        showToast("このメモを編集する権限がなくなりました", "error");
+      } else {
+        updateSaveStatus("error");
+      }
+      break;
+  }
+}
+
+// ---- 接続管理 ----
+
+function collabSocketUrl() {
+  const protocol = window.location.protocol === "https:" ? "wss:" : "ws:";
+  const params = new URLSearchParams({ client: collabState.clientId });
+  if (collabState.initialized) {
+    params.set("epoch", collabState.epoch);
+    params.set("rev", String(collabState.rev));
+  }
+  return `${protocol}//${window.location.host}${collabState.path}?${params}`;
+}
+
+function connectCollab() {
+  const socket = new WebSocket(collabSocketUrl());
+  collabState.socket = socket;
+
+  socket.onopen = () => {
+    collabState.reconnectDelay = 1000;
+  };
+  socket.onmessage = (event) => {
+    try {
+      handleCollabMessage(JSON.parse(event.data));
+    } catch (error) {
+      // 状態が食い違った場合は接続し直して文書全体を受け取る
+      console.error("Collaboration error:", error);
+      collabState.initialized = false;
+      collabState.awaiting = null;
+      collabState.buffer = null;
+      socket.close();
+    }
+  };
+  socket.onclose = () => {
+    if (collabState.socket !== socket) return;
+    collabState.socket = null;
+    renderCollabPresence(null);
+    if (collabState.stopped) return;
+    setTimeout(connectCollab, collabState.reconnectDelay);
+    collabState.reconnectDelay = Math.min(collabState.reconnectDelay * 2, collabMaxReconnectDelay);
+  };
+}
+
+/**
+ * 共同編集に接続し直す（表示名の変更を接続中の編集者に反映する）
+ */
+function reconnectCollab() {
+  if (collabState.socket) {
+    collabState.socket.close();
+  }
+}
+
+function stopCollaboration() {
+  collabState.stopped = true;
+  if (collabState.socket) {
+    collabState.socket.close();
+  }
+}
+
+/**
+ * 共同編集を開始する
+ * @param {string} path - WebSocket のパス（例: /note/{id}/ws）
+ * @param {Object} handlers - onTitle(title), onSaved(version, upToDate) のコールバック
+ */
+function startCollaboration(path, handlers) {
+  if (typeof WebSocket === "undefined") return;
+  const textarea = collabTextarea();
+  collabState.path = path;
+  collabState.handlers = handlers;
+  collabState.clientId = crypto.randomUUID ? crypto.randomUUID() : String(Date.now()) + Math.random().toString(16).slice(2);
+  collabState.text = textarea.value;
+  collabState.baseText = textarea.value;
+
+  textarea.addEventListener("input", onCollabInput);
+  textarea.addEventListener("compositionstart", onCollabCompositionStart);
+  textarea.addEventListener("compositionend", onCollabCompositionEnd);
+  connectCollab();
+}
+
+/**
+ * 共同編集のセッションに参加済みかどうか
+ * 一度参加した後は切断中も true を返し、HTTP での保存と混在させない
+ */
+function isCollaborating() {
+  return collabState.initialized && !collabState.stopped;
+}
+
+function collabSetTitle(title) {
+  collabState.pendingTitle = title;
+  flushPendingTitle();
+}
+
+function flushPendingTitle() {
+  const socket = collabState.socket;
+  if (collabState.pendingTitle === null || !socket || socket.readyState !== WebSocket.OPEN) return;
+  sendCollabMessage({ type: "title", title: collabState.pendingTitle });
+  collabState.pendingTitle = null;
+}
+
+function collabSave() {
+  if (!collabState.socket || collabState.socket.readyState !== WebSocket.OPEN) {
+    showToast("サーバーに再接続中です。接続後に自動で保存されます", "info");
+    return;
+  }
+  updateSaveStatus("saving");
+  sendCollabMessage({ type: "save" });
+}
+
+// 接続中の編集者を表示する。clients が null の場合は切断中として表示する
+function renderCollabPresence(clients) {
+  const element = document.getElementById("collab-presence");
+  if (!element) return;
+  element.classList.remove("d-none");
+
+  if (clients === null) {
+    element.textContent = "オフライン（再接続中）";
+    element.title = "";
+    return;
+  }
+  const labels = clients.map((client) => (client.self ? `${client.label}（自分）` : client.label));
+  element.textContent = `👥 ${clients.length}人が編集中`;
+  element.title = labels.join("\n");
+}
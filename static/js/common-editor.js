@@ -25,6 +25,9 @@ function updateSaveStatus(status) {
     case "error":
       saveStatusElement.innerHTML = "保存エラー";
       break;
+    case "readonly":
+      saveStatusElement.innerHTML = "閲覧のみ";
+      break;
     default:
       saveStatusElement.innerHTML = "保存済み";
   }
@@ -35,6 +38,18 @@ function updateSaveStatus(status) {
   }
 }
 
+/**
+ * 保存リクエストの本文のフィールドを返す
+ * 保存済みの版の本文（baseContent）が分かっていれば差分の patch だけを送り、
+ * 分からなければ（null）全文を送る
+ */
+function contentSaveFields(baseContent, content) {
+  if (baseContent === null) {
+    return { content: content };
+  }
+  return { patch: diffTextOp(baseContent, content) };
+}
+
 /**
  * カーソル位置を更新する共通関数
  */
@@ -293,3 +308,72 @@ function convertUtcToLocal() {
     convertAllDatesToJST();
   }
 }
+
+/**
+ * 保存時の競合を解決するダイアログを表示する共通関数
+ * サーバー側の内容を採用する・自分の内容で上書きする・両方を結合する、のいずれかを選択させる
+ * @param {Object} serverNote - サーバー側の最新メモ（title, content, version）
+ * @param {Object} localNote - 手元の編集内容（title, content）
+ * @param {Function} onResolve - 選択結果（title, content, version, action）を受け取るコールバック
+ *   action は "server"（サーバーの内容を採用）, "local"（上書き保存）, "merge"（結合して編集継続）のいずれか
+ */
+function showConflictDialog(serverNote, localNote, onResolve) {
+  const existing = document.getElementById("conflict-dialog");
+  if (existing) {
+    existing.remove();
+  }
+
+  const overlay = document.createElement("div");
+  overlay.id = "conflict-dialog";
+  overlay.className = "position-fixed top-0 start-0 w-100 h-100 d-flex align-items-center justify-content-center";
+  overlay.style.cssText = "background-color: rgba(0, 0, 0, 0.5); z-index: 10000;";
+
+  const card = document.createElement("div");
+  card.className = "card shadow";
+  card.style.maxWidth = "480px";
+
+  const body = document.createElement("div");
+  body.className = "card-body";
+
+  const heading = document.createElement("h5");
+  heading.className = "card-title";
+  heading.textContent = "保存の競合";
+
+  const message = document.createElement("p");
+  message.className = "card-text";
+  message.textContent = "他の編集者がこのメモを更新しました。どの内容を保存するか選択してください。";
+
+  const actions = document.createElement("div");
+  actions.className = "d-grid gap-2";
+
+  const close = () => overlay.remove();
+  const addButton = (label, className, handler) => {
+    const button = document.createElement("button");
+    button.className = `btn ${className}`;
+    button.textContent = label;
+    button.onclick = () => {
+      close();
+      handler();
+    };
+    actions.appendChild(button);
+  };
+
+  addButton("サーバーの内容を読み込む（自分の変更を破棄）", "btn-outline-primary", () => {
+    onResolve({ title: serverNote.title, content: serverNote.content, version: serverNote.version, action: "server" });
+  });
+  addButton("自分の内容で上書き保存", "btn-outline-danger", () => {
+    onResolve({ title: localNote.title, content: localNote.content, version: serverNote.version, action: "local" });
+  });
+  addButton("両方を残して結合", "btn-outline-secondary", () => {
+    const merged =
+      "<<<<<<< 自分の変更\n" + localNote.content + "\n=======\n" + serverNote.content + "\n>>>>>>> サーバーの内容\n";
+    onResolve({ title: localNote.title, content: merged, version: serverNote.version, action: "merge" });
+  });
+
+  body.appendChild(heading);
+  body.appendChild(message);
+  body.appendChild(actions);
+  card.appendChild(body);
+  overlay.appendChild(card);
+  document.body.appendChild(overlay);
+}
@@ -1,5 +1,7 @@
 let currentNoteId = "";
 let isModified = false;
+// 表示中の版（note-version）の本文。分からない間は null で、保存時に全文を送る
+let savedContent = null;
 
 // updateSaveStatus, updateStats, updateCursorPosition関数はcommon-editor.jsに移動
 
@@ -22,72 +24,958 @@ function saveTitle() {
   span.textContent = input.value;
   span.classList.remove("d-none");
   input.classList.add("d-none");
+  if (isCollaborating()) {
+    // 共同編集中はサーバーがまとめて保存する
+    collabSetTitle(input.value);
+    isModified = true;
+    updateSaveStatus("unsaved");
+    return;
+  }
   saveNote();
 }
 
-function saveNote() {
-  const noteId = document.getElementById("note-id").value;
-  const title = document.getElementById("title-input").value;
-  const content = document.getElementById("note-content").value;
+function saveNote() {
+  if (isCollaborating()) {
+    collabSave();
+    return;
+  }
+
+  const noteId = document.getElementById("note-id").value;
+  const versionInput = document.getElementById("note-version");
+  const title = document.getElementById("title-input").value;
+  const content = document.getElementById("note-content").value;
+
+  // 保存中状態を表示
+  updateSaveStatus("saving");
+
+  fetch("/note/save", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({
+      id: noteId,
+      title: title,
+      ...contentSaveFields(savedContent, content),
+      version: Number(versionInput.value),
+    }),
+  })
+    .then((response) => response.json().then((data) => ({ status: response.status, data: data })))
+    .then(({ status, data }) => {
+      if (status === 409 && data.note) {
+        // 競合の解決後はサーバーの版を基準に差分を送る
+        savedContent = data.note.content;
+        updateSaveStatus("error");
+        showConflictDialog(data.note, { title: title, content: content }, applyResolvedNote);
+      } else if (data.status === "success") {
+        versionInput.value = data.version;
+        savedContent = content;
+        updateSaveStatus("saved");
+        isModified = false;
+        refreshNoteLinks();
+      } else if (status === 400 && data.error === "invalid patch" && savedContent !== null) {
+        // 手元の基準の本文がサーバーと異なっていたため、全文で保存し直す
+        savedContent = null;
+        saveNote();
+      } else {
+        updateSaveStatus("error");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      savedContent = null;
+      updateSaveStatus("error");
+    });
+}
+
+// 競合解決ダイアログで選ばれた内容をエディターに反映する
+function applyResolvedNote(resolved) {
+  document.getElementById("note-version").value = resolved.version;
+  document.getElementById("title-input").value = resolved.title;
+  document.getElementById("note-title").textContent = resolved.title;
+  document.getElementById("note-content").value = resolved.content;
+  updateStats();
+
+  if (resolved.action === "local") {
+    saveNote();
+  } else if (resolved.action === "server") {
+    isModified = false;
+    updateSaveStatus("saved");
+  } else {
+    // 結合した内容をもとに編集を続ける
+    isModified = true;
+    updateSaveStatus("unsaved");
+  }
+}
+
+function createNewNote() {
+  // フォルダを表示中ならそのフォルダに作成する
+  const folderId = new URLSearchParams(window.location.search).get("folder");
+  if (folderId) {
+    window.location.href = "/note/new?folder=" + encodeURIComponent(folderId);
+  } else {
+    window.location.href = "/note/new";
+  }
+}
+
+function createNoteFromTemplate(templateId) {
+  if (!templateId) {
+    return;
+  }
+  const params = new URLSearchParams({ template: templateId });
+  const folderId = new URLSearchParams(window.location.search).get("folder");
+  if (folderId) {
+    params.set("folder", folderId);
+  }
+  window.location.href = "/note/new?" + params.toString();
+}
+
+function saveAsTemplate() {
+  // テンプレートは保存済みの内容から作るため、未保存の変更があれば先に保存してもらう
+  if (isModified) {
+    showToast("変更を保存してからテンプレートにしてください", "error");
+    return;
+  }
+  const name = prompt("テンプレート名を入力してください\n（同じ名前のテンプレートは上書きされます）", document.getElementById("title-input").value);
+  if (!name || !name.trim()) {
+    return;
+  }
+
+  const noteId = document.getElementById("note-id").value;
+  fetch("/template", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ noteId: noteId, name: name.trim() }),
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
+    })
+    .then(() => {
+      // 「テンプレートから…」の一覧に反映するため再読み込みする
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("テンプレートの保存に失敗しました", "error");
+    });
+}
+
+function togglePinned() {
+  const button = document.getElementById("pin-button");
+  const pinned = button.dataset.pinned !== "true";
+  updateNoteFlag("pin", { pinned: pinned })
+    .then(() => {
+      // 一覧の並びが変わるため再読み込みする
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("ピン留めを変更できませんでした", "error");
+    });
+}
+
+function toggleFavorite() {
+  const button = document.getElementById("favorite-button");
+  const favorite = button.dataset.favorite !== "true";
+  updateNoteFlag("favorite", { favorite: favorite })
+    .then(() => {
+      button.dataset.favorite = String(favorite);
+      button.classList.toggle("active", favorite);
+      button.textContent = favorite ? "★" : "☆";
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("お気に入りを変更できませんでした", "error");
+    });
+}
+
+function updateNoteFlag(name, body) {
+  const noteId = document.getElementById("note-id").value;
+  return fetch("/note/" + encodeURIComponent(noteId) + "/" + name, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify(body),
+  }).then((response) => {
+    if (!response.ok) {
+      throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+    }
+    return response.json();
+  });
+}
+
+function deleteNote() {
+  if (confirm("このメモをゴミ箱に移動しますか？")) {
+    const noteId = document.getElementById("note-id").value;
+
+    fetch("/note/delete/" + encodeURIComponent(noteId), {
+      method: "DELETE",
+    })
+      .then((response) => response.json())
+      .then((data) => {
+        if (data.status === "success") {
+          window.location.href = "/note";
+        } else {
+          alert("削除に失敗しました");
+        }
+      })
+      .catch((error) => {
+        console.error("Error:", error);
+        alert("削除に失敗しました");
+      });
+  }
+}
+
+function showRevisions() {
+  const noteId = document.getElementById("note-id").value;
+  window.location.href = "/note/" + encodeURIComponent(noteId) + "/revisions";
+}
+
+function selectNote(noteId) {
+  // URL encode the noteId to prevent injection
+  // タグ絞り込みなどのクエリは維持する
+  window.location.href = "/note/" + encodeURIComponent(noteId) + window.location.search;
+}
+
+// タグ機能
+function addNoteTag() {
+  const noteId = document.getElementById("note-id").value;
+  const input = document.getElementById("tag-input");
+  const name = input.value.trim();
+  if (!name) {
+    return;
+  }
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/tags", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ name: name }),
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
+    })
+    .then(() => {
+      // タグクラウドも更新するため再読み込みする
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("タグの追加に失敗しました", "error");
+    });
+}
+
+function removeNoteTag(tagId) {
+  const noteId = document.getElementById("note-id").value;
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/tags/" + encodeURIComponent(tagId), {
+    method: "DELETE",
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("タグの削除に失敗しました", "error");
+    });
+}
+
+function renameTag(tagId, currentName) {
+  const name = prompt("新しいタグ名を入力してください\n（既存のタグ名を入力すると統合されます）", currentName);
+  if (!name || name.trim() === currentName) {
+    return;
+  }
+
+  fetch("/tag/" + encodeURIComponent(tagId), {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ name: name.trim() }),
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
+    })
+    .then((data) => {
+      // 統合された場合は統合先で絞り込む
+      const params = new URLSearchParams(window.location.search);
+      if (params.get("tag") === tagId) {
+        params.set("tag", data.tag.id);
+        window.location.search = params.toString();
+      } else {
+        window.location.reload();
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("タグ名の変更に失敗しました", "error");
+    });
+}
+
+function deleteTag(tagId) {
+  if (!confirm("このタグを削除しますか？\nメモからも外れます。")) {
+    return;
+  }
+
+  fetch("/tag/" + encodeURIComponent(tagId), {
+    method: "DELETE",
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      window.location.href = "/note";
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("タグの削除に失敗しました", "error");
+    });
+}
+
+// フォルダ機能
+function requestFolderApi(url, method, body) {
+  const options = { method: method };
+  if (body !== undefined) {
+    options.headers = { "Content-Type": "application/json" };
+    options.body = JSON.stringify(body);
+  }
+  return fetch(url, options).then((response) => {
+    if (!response.ok) {
+      const error = new Error(`HTTP ${response.status}: ${response.statusText}`);
+      error.status = response.status;
+      throw error;
+    }
+    return response.json();
+  });
+}
+
+function createFolder(parentId) {
+  const name = prompt("フォルダ名を入力してください");
+  if (!name || !name.trim()) {
+    return;
+  }
+
+  requestFolderApi("/folder", "POST", { name: name.trim(), parentId: parentId })
+    .then(() => {
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("フォルダの作成に失敗しました", "error");
+    });
+}
+
+function renameFolder(folderId, currentName) {
+  const name = prompt("新しいフォルダ名を入力してください", currentName);
+  if (!name || name.trim() === currentName) {
+    return;
+  }
+
+  requestFolderApi("/folder/" + encodeURIComponent(folderId), "PUT", { name: name.trim() })
+    .then(() => {
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("フォルダ名の変更に失敗しました", "error");
+    });
+}
+
+function deleteFolder(folderId) {
+  if (!confirm("このフォルダを削除しますか？")) {
+    return;
+  }
+  const cascade = confirm(
+    "フォルダ内のメモとサブフォルダもすべて削除しますか？\n" +
+      "キャンセルを選ぶと、中身は親フォルダに移動します。"
+  );
+
+  const mode = cascade ? "cascade" : "move";
+  requestFolderApi("/folder/" + encodeURIComponent(folderId) + "?mode=" + mode, "DELETE")
+    .then(() => {
+      window.location.href = "/note";
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("フォルダの削除に失敗しました", "error");
+    });
+}
+
+function moveNoteToFolder(folderId, noteId = document.getElementById("note-id").value) {
+  return requestFolderApi("/note/" + encodeURIComponent(noteId) + "/folder", "PUT", {
+    folderId: folderId,
+  })
+    .then(() => {
+      showToast("メモを移動しました", "success");
+      // フォルダで絞り込み中は一覧が変わるため再読み込みする
+      if (new URLSearchParams(window.location.search).get("folder")) {
+        window.location.reload();
+      }
+      return true;
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("メモの移動に失敗しました", "error");
+      return false;
+    });
+}
+
+function moveFolder(folderId, parentId) {
+  requestFolderApi("/folder/" + encodeURIComponent(folderId) + "/move", "PUT", {
+    parentId: parentId,
+  })
+    .then(() => {
+      window.location.reload();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      if (error.status === 409) {
+        showToast("フォルダを自身の配下には移動できません", "error");
+      } else {
+        showToast("フォルダの移動に失敗しました", "error");
+      }
+    });
+}
+
+// ドラッグ&ドロップでメモやフォルダをフォルダに移動する
+function onNoteDragStart(event) {
+  event.dataTransfer.setData("application/x-note-id", event.currentTarget.dataset.noteId);
+  event.dataTransfer.effectAllowed = "move";
+}
+
+// 手動の並び順のときだけ、メモをメモの上にドロップして並べ替える
+function isManualSort() {
+  return document.getElementById("notes-list").dataset.sort === "manual";
+}
+
+function clearNoteDropMarker(item) {
+  item.classList.remove("drop-before", "drop-after");
+}
+
+function onNoteDragOver(event) {
+  if (!isManualSort() || !event.dataTransfer.types.includes("application/x-note-id")) {
+    return;
+  }
+  event.preventDefault();
+  const item = event.currentTarget;
+  const rect = item.getBoundingClientRect();
+  const before = event.clientY < rect.top + rect.height / 2;
+  item.classList.toggle("drop-before", before);
+  item.classList.toggle("drop-after", !before);
+}
+
+function onNoteDragLeave(event) {
+  clearNoteDropMarker(event.currentTarget);
+}
+
+function onNoteDrop(event) {
+  if (!isManualSort()) {
+    return;
+  }
+  event.preventDefault();
+  const target = event.currentTarget;
+  const before = target.classList.contains("drop-before");
+  clearNoteDropMarker(target);
+
+  const noteId = event.dataTransfer.getData("application/x-note-id");
+  const notesList = document.getElementById("notes-list");
+  const item = notesList.querySelector(`.note-item[data-note-id="${CSS.escape(noteId)}"]`);
+  if (!item || item === target) {
+    return;
+  }
+  // ピン留めしたメモとそれ以外はそれぞれの中でだけ並べ替えられる
+  if (item.dataset.pinned !== target.dataset.pinned) {
+    showToast("ピン留めしたメモとそれ以外の間には移動できません", "error");
+    return;
+  }
+
+  const previousSibling = item.previousElementSibling;
+  notesList.insertBefore(item, before ? target : target.nextElementSibling);
+  const sameGroup = (element) =>
+    element && element.classList.contains("note-item") && element.dataset.pinned === item.dataset.pinned;
+  const after = item.previousElementSibling;
+  const next = item.nextElementSibling;
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/order", {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({
+      afterId: sameGroup(after) ? after.dataset.noteId : "",
+      beforeId: sameGroup(next) ? next.dataset.noteId : "",
+    }),
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      // 元の位置に戻す
+      notesList.insertBefore(item, previousSibling ? previousSibling.nextElementSibling : notesList.firstElementChild);
+      showToast("並べ替えに失敗しました", "error");
+    });
+}
+
+function onFolderDragStart(event) {
+  event.dataTransfer.setData("application/x-folder-id", event.currentTarget.dataset.folderId);
+  event.dataTransfer.effectAllowed = "move";
+}
+
+function onFolderDragOver(event) {
+  event.preventDefault();
+  event.currentTarget.classList.add("drag-over");
+}
+
+function onFolderDragLeave(event) {
+  event.currentTarget.classList.remove("drag-over");
+}
+
+function onFolderDrop(event) {
+  event.preventDefault();
+  const target = event.currentTarget;
+  target.classList.remove("drag-over");
+  const targetFolderId = target.dataset.folderId;
+
+  const noteId = event.dataTransfer.getData("application/x-note-id");
+  if (noteId) {
+    moveNoteToFolder(targetFolderId, noteId).then((moved) => {
+      if (moved && noteId === document.getElementById("note-id").value) {
+        document.getElementById("note-folder").value = targetFolderId;
+      }
+    });
+    return;
+  }
+
+  const folderId = event.dataTransfer.getData("application/x-folder-id");
+  if (folderId && folderId !== targetFolderId) {
+    moveFolder(folderId, targetFolderId);
+  }
+}
+
+// 添付ファイル機能
+function uploadAttachments(files) {
+  const noteId = document.getElementById("note-id").value;
+  const input = document.getElementById("attachment-input");
+
+  Array.from(files).forEach((file) => {
+    const formData = new FormData();
+    formData.append("file", file);
+
+    fetch("/note/" + encodeURIComponent(noteId) + "/attachments", {
+      method: "POST",
+      body: formData,
+    })
+      .then((response) => {
+        if (response.status === 413) {
+          throw new Error("ファイルサイズが上限を超えています");
+        }
+        if (response.status === 415) {
+          throw new Error("このファイル形式は添付できません");
+        }
+        if (!response.ok) {
+          throw new Error("添付に失敗しました");
+        }
+        return response.json();
+      })
+      .then((data) => {
+        addAttachmentToList(noteId, data.attachment);
+        showToast(file.name + " を添付しました", "success");
+      })
+      .catch((error) => {
+        console.error("Error:", error);
+        showToast(file.name + ": " + error.message, "error");
+      });
+  });
+
+  // 同じファイルを再度選択できるようにする
+  input.value = "";
+}
+
+function formatFileSize(size) {
+  if (size >= 1024 * 1024) {
+    return (size / (1024 * 1024)).toFixed(1) + " MB";
+  }
+  if (size >= 1024) {
+    return (size / 1024).toFixed(1) + " KB";
+  }
+  return size + " B";
+}
+
+function addAttachmentToList(noteId, attachment) {
+  const list = document.getElementById("attachment-list");
+
+  const item = document.createElement("span");
+  item.className = "attachment-item";
+  item.dataset.attachmentId = attachment.id;
+
+  const link = document.createElement("a");
+  link.href = "/note/" + encodeURIComponent(noteId) + "/attachments/" + encodeURIComponent(attachment.id);
+  link.target = "_blank";
+  link.rel = "noopener";
+  const icon = attachment.content_type.startsWith("image/") ? "🖼️" : "📄";
+  link.textContent = icon + " " + attachment.file_name;
+
+  const size = document.createElement("small");
+  size.className = "text-muted";
+  size.textContent = "(" + formatFileSize(attachment.size) + ")";
+
+  const deleteButton = document.createElement("button");
+  deleteButton.className = "btn-close ms-1";
+  deleteButton.style.fontSize = "0.5rem";
+  deleteButton.title = "添付ファイルを削除";
+  deleteButton.onclick = () => deleteAttachment(attachment.id);
+
+  item.appendChild(link);
+  item.appendChild(size);
+  item.appendChild(deleteButton);
+  list.appendChild(item);
+}
+
+function deleteAttachment(attachmentId) {
+  if (!confirm("この添付ファイルを削除しますか？")) {
+    return;
+  }
+  const noteId = document.getElementById("note-id").value;
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/attachments/" + encodeURIComponent(attachmentId), {
+    method: "DELETE",
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      const item = document.querySelector(`.attachment-item[data-attachment-id="${CSS.escape(attachmentId)}"]`);
+      if (item) {
+        item.remove();
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("添付ファイルの削除に失敗しました", "error");
+    });
+}
+
+// 共有リンク（閲覧のみ）での表示形式を切り替える
+// リンク・バックリンク欄を保存後の内容で更新する
+function refreshNoteLinks() {
+  // リンク欄はオーナーにだけ表示される
+  if (!document.getElementById("note-links")) {
+    return;
+  }
+  const noteId = document.getElementById("note-id").value;
+  fetch("/note/" + encodeURIComponent(noteId) + "/links")
+    .then((response) => response.json())
+    .then((data) => {
+      if (!data.links) {
+        return;
+      }
+      renderLinkList(document.getElementById("link-list"), data.links, (link) => {
+        if (link.note_id) {
+          return createLinkItem(link.note_id, link.title);
+        }
+        const item = document.createElement("span");
+        item.className = "link-item link-broken";
+        item.title = "このタイトルのメモはありません";
+        item.textContent = link.title + " ";
+        const badge = document.createElement("span");
+        badge.className = "badge bg-warning text-dark";
+        badge.textContent = "リンク切れ";
+        item.appendChild(badge);
+        return item;
+      });
+      renderLinkList(document.getElementById("backlink-list"), data.backlinks, (backlink) =>
+        createLinkItem(backlink.id, backlink.title)
+      );
+    })
+    .catch((error) => console.error("Error:", error));
+}
+
+function createLinkItem(noteId, title) {
+  const link = document.createElement("a");
+  link.className = "link-item";
+  link.href = "/note/" + encodeURIComponent(noteId);
+  link.textContent = title;
+  return link;
+}
+
+function renderLinkList(list, items, createItem) {
+  list.replaceChildren();
+  if (items.length === 0) {
+    const empty = document.createElement("span");
+    empty.className = "text-muted";
+    empty.textContent = "なし";
+    list.appendChild(empty);
+    return;
+  }
+  items.forEach((item) => list.appendChild(createItem(item)));
+}
 
-  // 保存中状態を表示
-  updateSaveStatus("saving");
+// すべてのメモのリンク切れを表示・非表示にする
+function showBrokenLinks() {
+  const list = document.getElementById("broken-link-list");
+  if (!list.classList.contains("d-none")) {
+    list.classList.add("d-none");
+    return;
+  }
+  fetch("/note/links/broken")
+    .then((response) => response.json())
+    .then((data) => {
+      if (!data.broken) {
+        showToast("リンク切れの取得に失敗しました", "error");
+        return;
+      }
+      list.replaceChildren();
+      if (data.broken.length === 0) {
+        list.textContent = "リンク切れはありません";
+      }
+      data.broken.forEach((broken) => {
+        const row = document.createElement("div");
+        row.appendChild(createLinkItem(broken.source_id, broken.source_title));
+        const target = document.createElement("span");
+        target.className = "text-muted";
+        target.textContent = "→ [[" + broken.title + "]]";
+        row.appendChild(target);
+        list.appendChild(row);
+      });
+      list.classList.remove("d-none");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("リンク切れの取得に失敗しました", "error");
+    });
+}
 
-  fetch("/note/save", {
-    method: "POST",
+function changeNoteFormat(format) {
+  const noteId = document.getElementById("note-id").value;
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/format", {
+    method: "PUT",
     headers: {
       "Content-Type": "application/json",
     },
-    body: JSON.stringify({
-      id: noteId,
-      title: title,
-      content: content,
-    }),
+    body: JSON.stringify({ format: format }),
   })
-    .then((response) => response.json())
-    .then((data) => {
-      if (data.status === "success") {
-        updateSaveStatus("saved");
-        isModified = false;
-      } else {
-        updateSaveStatus("error");
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
       }
+      return response.json();
+    })
+    .then(() => {
+      showToast("表示形式を変更しました", "success");
     })
     .catch((error) => {
       console.error("Error:", error);
-      updateSaveStatus("error");
+      showToast("表示形式の変更に失敗しました", "error");
     });
 }
 
-function createNewNote() {
-  window.location.href = "/note/new";
+// 検索入力のデバウンス用タイマー
+let searchTimer = null;
+
+function onSearchInput() {
+  clearTimeout(searchTimer);
+  searchTimer = setTimeout(searchNotes, 300);
 }
 
-function deleteNote() {
-  if (confirm("このメモを削除しますか？")) {
-    const noteId = document.getElementById("note-id").value;
+function searchNotes() {
+  const query = document.getElementById("note-search").value.trim();
+  const notesList = document.getElementById("notes-list");
+  const resultsList = document.getElementById("search-results");
 
-    fetch("/note/delete/" + encodeURIComponent(noteId), {
-      method: "DELETE",
+  if (!query) {
+    resultsList.classList.add("d-none");
+    notesList.classList.remove("d-none");
+    return;
+  }
+
+  fetch("/note/search?q=" + encodeURIComponent(query))
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
     })
-      .then((response) => response.json())
-      .then((data) => {
-        if (data.status === "success") {
-          window.location.href = "/note";
-        } else {
-          alert("削除に失敗しました");
-        }
-      })
-      .catch((error) => {
-        console.error("Error:", error);
-        alert("削除に失敗しました");
+    .then((data) => {
+      renderSearchResults(data.results || []);
+      notesList.classList.add("d-none");
+      resultsList.classList.remove("d-none");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast("検索に失敗しました", "error");
+    });
+}
+
+// 検索結果を描画する（title_html, snippet_html はサーバー側でエスケープ済み）
+function renderSearchResults(results) {
+  const resultsList = document.getElementById("search-results");
+  resultsList.innerHTML = "";
+
+  if (results.length === 0) {
+    const empty = document.createElement("div");
+    empty.className = "text-muted small";
+    empty.textContent = "該当するメモはありません";
+    resultsList.appendChild(empty);
+    return;
+  }
+
+  results.forEach((result) => {
+    const item = document.createElement("div");
+    item.className = "card mb-2 note-item";
+    item.onclick = () => selectNote(result.id);
+
+    const body = document.createElement("div");
+    body.className = "card-body p-2";
+
+    const title = document.createElement("h6");
+    title.className = "card-title mb-1";
+    title.style.fontSize = "0.9rem";
+    title.innerHTML = result.title_html;
+
+    const snippet = document.createElement("small");
+    snippet.className = "text-muted d-block search-snippet";
+    snippet.innerHTML = result.snippet_html;
+
+    body.appendChild(title);
+    body.appendChild(snippet);
+    item.appendChild(body);
+    resultsList.appendChild(item);
+  });
+}
+
+// メモ一覧の並び順を変更する（タグなどの絞り込みは維持する）
+function changeNoteSort(sort) {
+  const params = new URLSearchParams(window.location.search);
+  params.set("sort", sort);
+  window.location.search = params.toString();
+}
+
+// メモ一覧の続きを読み込み中かどうか
+let isLoadingNotes = false;
+
+// 一覧の末尾が表示されたら続きのメモを読み込む
+function loadMoreNotes() {
+  const notesList = document.getElementById("notes-list");
+  const cursor = notesList.dataset.nextCursor;
+  if (!cursor || isLoadingNotes) {
+    return;
+  }
+  isLoadingNotes = true;
+
+  // 絞り込みと並び順は表示中の画面と揃える
+  const current = new URLSearchParams(window.location.search);
+  const params = new URLSearchParams();
+  ["tag", "folder", "favorite", "sort"].forEach((key) => {
+    if (current.get(key)) {
+      params.set(key, current.get(key));
+    }
+  });
+  params.set("cursor", cursor);
+
+  const sentinel = document.getElementById("notes-list-sentinel");
+  sentinel.textContent = "読み込み中...";
+  fetch("/note/list?" + params.toString())
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      return response.json();
+    })
+    .then((data) => {
+      (data.notes || []).forEach((note) => {
+        notesList.insertBefore(createNoteListItem(note), sentinel);
       });
+      notesList.dataset.nextCursor = data.next_cursor || "";
+      sentinel.textContent = "";
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      sentinel.textContent = "";
+      showToast("メモ一覧の読み込みに失敗しました", "error");
+    })
+    .finally(() => {
+      isLoadingNotes = false;
+    });
+}
+
+// メモ一覧のカードを作る（テンプレートの .note-item と同じ構造）
+function createNoteListItem(note) {
+  const item = document.createElement("div");
+  item.className = "card mb-2 note-item";
+  item.classList.toggle("pinned", note.pinned);
+  item.dataset.noteId = note.id;
+  item.dataset.pinned = String(note.pinned);
+  item.draggable = true;
+  item.ondragstart = onNoteDragStart;
+  item.ondragover = onNoteDragOver;
+  item.ondragleave = onNoteDragLeave;
+  item.ondrop = onNoteDrop;
+  item.onclick = () => selectNote(note.id);
+
+  const body = document.createElement("div");
+  body.className = "card-body p-2";
+
+  const title = document.createElement("h6");
+  title.className = "card-title mb-1";
+  title.style.fontSize = "0.9rem";
+  title.textContent = (note.pinned ? "📌 " : "") + (note.favorite ? "★ " : "") + note.title;
+  body.appendChild(title);
+
+  if (note.snippet) {
+    const snippet = document.createElement("small");
+    snippet.className = "text-muted d-block note-snippet";
+    snippet.textContent = note.snippet;
+    body.appendChild(snippet);
   }
+
+  const updatedAt = document.createElement("small");
+  updatedAt.className = "text-muted";
+  if (note.updated_at) {
+    updatedAt.textContent = formatToJST(new Date(note.updated_at).toISOString().slice(0, 19));
+  }
+  body.appendChild(updatedAt);
+
+  item.appendChild(body);
+  return item;
 }
 
-function selectNote(noteId) {
-  // URL encode the noteId to prevent injection
-  window.location.href = "/note/" + encodeURIComponent(noteId);
+// 一覧の末尾の監視を始める
+function initializeNotesListScroll() {
+  const notesList = document.getElementById("notes-list");
+  const sentinel = document.getElementById("notes-list-sentinel");
+  if (!notesList || !sentinel) {
+    return;
+  }
+  const observer = new IntersectionObserver(
+    (entries) => {
+      if (entries.some((entry) => entry.isIntersecting)) {
+        loadMoreNotes();
+      }
+    },
+    { root: notesList, rootMargin: "0px 0px 200px 0px" }
+  );
+  observer.observe(sentinel);
 }
 
 // 共有機能（閲覧のみ）
@@ -100,6 +988,19 @@ function shareEditable() {
   shareNote(true);
 }
 
+// 共有リンクの制限の入力欄から、作成リクエストの値を返す（0 は制限なし）
+function shareLimits(editable) {
+  const maxViews = document.getElementById("share-burn").checked
+    ? 1
+    : Number(document.getElementById("share-max-views").value) || 0;
+  return {
+    expiresInHours: Number(document.getElementById("share-expires").value),
+    maxViews: maxViews,
+    editableForHours: editable ? Number(document.getElementById("share-editable-for").value) : 0,
+    password: document.getElementById("share-password").value,
+  };
+}
+
 function shareNote(editable) {
   const noteId = document.getElementById("note-id").value;
   const shareType = editable ? "編集可" : "閲覧のみ";
@@ -117,11 +1018,14 @@ function shareNote(editable) {
     body: JSON.stringify({
       noteId: noteId,
       editable: editable,
+      ...shareLimits(editable),
     }),
   })
     .then((response) => {
       if (response.status === 200) {
         return response.json();
+      } else if (response.status === 400) {
+        throw new Error("有効期限・閲覧回数・パスワードの指定が正しくありません");
       } else {
         throw new Error(`HTTP ${response.status}: ${response.statusText}`);
       }
@@ -131,7 +1035,8 @@ function shareNote(editable) {
         const shareUrl = window.location.origin + "/share/" + encodeURIComponent(data.shareId);
 
         // 共有リストを更新
-        addShareToList(data.shareId, editable);
+        addShareToList(data.shareId, editable, data.share);
+        document.getElementById("share-password").value = "";
 
         // クリップボードにコピー
         if (navigator.clipboard && window.isSecureContext) {
@@ -165,19 +1070,52 @@ function initializeEditor(noteId) {
 
   // テキストエリアの参照を取得
   const textarea = document.getElementById("note-content");
+  savedContent = textarea.value;
+  const canEdit = window.noteCanEdit !== false;
+  textarea.readOnly = !canEdit;
 
   // 共通のエディターイベントを初期化
   initializeCommonEditorEvents(textarea, {
-    enableAutoSave: true,
+    enableAutoSave: canEdit,
     autoSaveInterval: 5000,
     enableKeyboardShortcuts: true,
     saveCallback: () => {
-      if (isModified) {
+      // 共同編集中はサーバー側で自動保存される
+      if (canEdit && isModified && !isCollaborating()) {
         saveNote();
       }
     },
     markUnsavedCallback: updateStatsWithModified
   });
+
+  if (!canEdit) {
+    // 読み取り専用で共有されたメモは共同編集に参加しない
+    updateSaveStatus("readonly");
+    return;
+  }
+
+  startCollaboration("/note/" + encodeURIComponent(noteId) + "/ws", {
+    onTitle: applyCollabTitle,
+    onSaved: (version, upToDate) => {
+      document.getElementById("note-version").value = version;
+      // 共同編集で保存された版の本文は、手元と一致するときだけ分かる
+      savedContent = upToDate ? textarea.value : null;
+      if (upToDate) {
+        isModified = false;
+        updateSaveStatus("saved");
+        refreshNoteLinks();
+      }
+    },
+  });
+}
+
+// 他の編集者が変更したタイトルを反映する（編集中の入力欄は上書きしない）
+function applyCollabTitle(title) {
+  const input = document.getElementById("title-input");
+  document.getElementById("note-title").textContent = title;
+  if (input.classList.contains("d-none")) {
+    input.value = title;
+  }
 }
 
 document.addEventListener("DOMContentLoaded", function () {
@@ -188,6 +1126,9 @@ document.addEventListener("DOMContentLoaded", function () {
 
   // シェア番号を設定
   initializeShareNumbers();
+
+  // メモ一覧の無限スクロール
+  initializeNotesListScroll();
 });
 
 // シェア番号を初期化
@@ -230,10 +1171,281 @@ function deleteShare(shareId) {
   }
 }
 
+// 共有リンクのパスワードを設定・変更する。空欄ならパスワードを外す
+function setSharePassword(button, shareId) {
+  const password = prompt("共有リンクのパスワードを入力してください（空欄でパスワードを外します）");
+  if (password === null) {
+    return;
+  }
+
+  fetch("/share/" + encodeURIComponent(shareId) + "/password", {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ password: password }),
+  })
+    .then((response) => {
+      if (response.status === 200) {
+        return response.json();
+      } else if (response.status === 400) {
+        throw new Error("パスワードが長すぎます");
+      } else {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+    })
+    .then((data) => {
+      // 一覧のパスワード付きの表示を更新
+      const shareInfo = button.closest(".share-item").querySelector(".share-number").parentElement;
+      const badge = shareInfo.querySelector(".share-password-badge");
+      if (data.passwordProtected && !badge) {
+        shareInfo.insertBefore(createSharePasswordBadge(), shareInfo.querySelector(".share-limits"));
+      } else if (!data.passwordProtected && badge) {
+        badge.remove();
+      }
+      showToast(data.passwordProtected ? "パスワードを設定しました" : "パスワードを外しました", "success");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`パスワードの設定に失敗しました: ${error.message}`, "error");
+    });
+}
+
+// 共有リンクのアクセスの集計と最近のアクセスを表示・非表示にする
+function toggleShareAccess(button, shareId) {
+  const shareBody = button.closest(".share-item").querySelector(".card-body");
+  const existing = shareBody.querySelector(".share-access-log");
+  if (existing) {
+    existing.remove();
+    return;
+  }
+
+  fetch("/share/" + encodeURIComponent(shareId) + "/access")
+    .then((response) => {
+      if (response.status === 200) {
+        return response.json();
+      } else {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+    })
+    .then((data) => {
+      const statsDiv = shareBody.querySelector(".share-access");
+      if (statsDiv) {
+        statsDiv.textContent = formatShareAccessStats(data.stats);
+      }
+      shareBody.appendChild(createShareAccessLog(data.accesses || []));
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`アクセス記録の取得に失敗しました: ${error.message}`, "error");
+    });
+}
+
+function formatShareAccessStats(stats) {
+  if (!stats || (stats.views === 0 && stats.edits === 0)) {
+    return "アクセスなし";
+  }
+  let text = `閲覧 ${stats.views}回・編集 ${stats.edits}回・訪問者 ${stats.visitors}人`;
+  if (stats.last_accessed_at) {
+    text += "・最終: " + formatToJST(stats.last_accessed_at.replace(/(\.\d+)?Z$/, ""));
+  }
+  return text;
+}
+
+const shareAccessActionLabels = {
+  view: "閲覧",
+  edit: "編集",
+};
+
+function createShareAccessLog(accesses) {
+  const list = document.createElement("ul");
+  list.className = "share-access-log list-unstyled small mt-2 mb-0";
+  if (accesses.length === 0) {
+    const empty = document.createElement("li");
+    empty.className = "text-muted";
+    empty.textContent = "アクセスはまだありません";
+    list.appendChild(empty);
+    return list;
+  }
+
+  accesses.forEach((access) => {
+    const item = document.createElement("li");
+    item.className = "share-access-entry";
+
+    const time = document.createElement("span");
+    time.className = "text-muted me-1";
+    time.textContent = formatToJST(access.created_at.replace(/(\.\d+)?Z$/, ""));
+
+    const action = document.createElement("span");
+    action.className = "badge me-1 " + (access.action === "edit" ? "bg-success" : "bg-info");
+    action.textContent = shareAccessActionLabels[access.action] || access.action;
+
+    // ログイン中のユーザーでなければ IP アドレスのハッシュの先頭で訪問者を区別する
+    const visitor = document.createElement("span");
+    visitor.className = "me-1";
+    visitor.textContent = access.user_email || "訪問者 " + access.ip_hash.slice(0, 8);
+
+    item.appendChild(time);
+    item.appendChild(action);
+    item.appendChild(visitor);
+    if (access.user_agent) {
+      const agent = document.createElement("div");
+      agent.className = "share-access-agent text-muted";
+      agent.textContent = access.user_agent;
+      agent.title = access.user_agent;
+      item.appendChild(agent);
+    }
+    list.appendChild(item);
+  });
+  return list;
+}
+
+function createSharePasswordBadge() {
+  const badge = document.createElement("span");
+  badge.className = "badge bg-warning text-dark ms-1 share-password-badge";
+  badge.title = "パスワード付き";
+  badge.textContent = "🔒";
+  return badge;
+}
+
+// メモをメールアドレスのユーザーと共有する（共有済みなら権限を変更する）
+function addNoteMember() {
+  const noteId = document.getElementById("note-id").value;
+  const emailInput = document.getElementById("member-email");
+  const email = emailInput.value.trim();
+  if (!email) {
+    return;
+  }
+
+  fetch("/note/" + encodeURIComponent(noteId) + "/members", {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ email: email, role: document.getElementById("member-role").value }),
+  })
+    .then((response) => response.json().then((data) => ({ status: response.status, data: data })))
+    .then(({ status, data }) => {
+      if (status === 404 && data.error === "user not found") {
+        throw new Error("このメールアドレスのユーザーはいません");
+      }
+      if (status === 400 && data.error === "invalid note member") {
+        throw new Error("自分自身とは共有できません");
+      }
+      if (data.status !== "success") {
+        throw new Error(data.error || `HTTP ${status}`);
+      }
+      emailInput.value = "";
+      renderNoteMember(data.member);
+      showToast(`${data.member.email} と共有しました`, "success");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`共有に失敗しました: ${error.message}`, "error");
+    });
+}
+
+function changeNoteMemberRole(userId, role) {
+  const noteId = document.getElementById("note-id").value;
+  fetch("/note/" + encodeURIComponent(noteId) + "/members/" + encodeURIComponent(userId), {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ role: role }),
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      showToast("権限を変更しました", "success");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`権限の変更に失敗しました: ${error.message}`, "error");
+    });
+}
+
+function removeNoteMember(userId) {
+  if (!confirm("このユーザーとの共有を解除しますか？")) {
+    return;
+  }
+  const noteId = document.getElementById("note-id").value;
+  fetch("/note/" + encodeURIComponent(noteId) + "/members/" + encodeURIComponent(userId), {
+    method: "DELETE",
+  })
+    .then((response) => {
+      if (!response.ok) {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+      const item = document.querySelector(`.member-item[data-user-id="${CSS.escape(userId)}"]`);
+      if (item) {
+        item.remove();
+      }
+      const list = document.getElementById("members-list");
+      if (!list.querySelector(".member-item")) {
+        list.innerHTML = '<div class="text-muted small">共有しているユーザーはいません</div>';
+      }
+      showToast("共有を解除しました", "success");
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`共有の解除に失敗しました: ${error.message}`, "error");
+    });
+}
+
+const noteRoleLabels = { viewer: "閲覧者", commenter: "コメント可", editor: "編集者" };
+
+// 共有ユーザーの一覧に追加する。共有済みのユーザーは権限の表示を更新する
+function renderNoteMember(member) {
+  const list = document.getElementById("members-list");
+  const existing = list.querySelector(`.member-item[data-user-id="${CSS.escape(member.user_id)}"]`);
+  if (existing) {
+    existing.querySelector(".member-role").value = member.role;
+    return;
+  }
+  const empty = list.querySelector(".text-muted");
+  if (empty) {
+    empty.remove();
+  }
+
+  const item = document.createElement("div");
+  item.className = "member-item";
+  item.dataset.userId = member.user_id;
+
+  const email = document.createElement("span");
+  email.className = "member-email";
+  email.title = member.email;
+  email.textContent = member.email;
+
+  const select = document.createElement("select");
+  select.className = "form-select form-select-sm member-role";
+  select.title = "権限";
+  Object.entries(noteRoleLabels).forEach(([role, label]) => {
+    const option = document.createElement("option");
+    option.value = role;
+    option.textContent = label;
+    select.appendChild(option);
+  });
+  select.value = member.role;
+  select.onchange = () => changeNoteMemberRole(member.user_id, select.value);
+
+  const removeButton = document.createElement("button");
+  removeButton.className = "btn btn-sm btn-outline-danger";
+  removeButton.title = "共有を解除";
+  removeButton.textContent = "🗑️";
+  removeButton.onclick = () => removeNoteMember(member.user_id);
+
+  item.appendChild(email);
+  item.appendChild(select);
+  item.appendChild(removeButton);
+  list.appendChild(item);
+}
+
 // showToast関数はcommon-editor.jsに移動
 
 // 共有リストに新しいアイテムを追加
-function addShareToList(shareId, editable) {
+function addShareToList(shareId, editable, share) {
   const sharesList = document.getElementById("shares-list");
 
   // "共有リンクはありません" のメッセージがあれば削除
@@ -268,6 +1480,33 @@ function addShareToList(shareId, editable) {
   shareInfo.appendChild(numberSpan);
   shareInfo.appendChild(typeSpan);
 
+  if (share && share.passwordProtected) {
+    shareInfo.appendChild(createSharePasswordBadge());
+  }
+
+  // 有効期限・閲覧回数・編集できる期間
+  const limits = [];
+  if (share && share.expiresAt) {
+    limits.push("期限: " + formatToJST(share.expiresAt.replace(/(\.\d+)?Z$/, "")));
+  }
+  if (share && share.maxViews) {
+    limits.push(`閲覧: ${share.viewCount}/${share.maxViews}`);
+  }
+  if (share && share.editableUntil) {
+    limits.push("編集: " + formatToJST(share.editableUntil.replace(/(\.\d+)?Z$/, "")) + "まで");
+  }
+  if (limits.length > 0) {
+    const limitsDiv = document.createElement("div");
+    limitsDiv.className = "share-limits text-muted";
+    limitsDiv.textContent = limits.join(" ");
+    shareInfo.appendChild(limitsDiv);
+  }
+
+  const accessDiv = document.createElement("div");
+  accessDiv.className = "share-access text-muted";
+  accessDiv.textContent = formatShareAccessStats(null);
+  shareInfo.appendChild(accessDiv);
+
   const shareActions = document.createElement("div");
 
   const copyButton = document.createElement("button");
@@ -276,6 +1515,18 @@ function addShareToList(shareId, editable) {
   copyButton.textContent = "📋";
   copyButton.onclick = () => copyShareLink(shareId);
 
+  const passwordButton = document.createElement("button");
+  passwordButton.className = "btn btn-sm btn-outline-secondary me-1";
+  passwordButton.title = "パスワードを設定";
+  passwordButton.textContent = "🔑";
+  passwordButton.onclick = () => setSharePassword(passwordButton, shareId);
+
+  const accessButton = document.createElement("button");
+  accessButton.className = "btn btn-sm btn-outline-secondary me-1";
+  accessButton.title = "アクセス記録";
+  accessButton.textContent = "📊";
+  accessButton.onclick = () => toggleShareAccess(accessButton, shareId);
+
   const deleteButton = document.createElement("button");
   deleteButton.className = "btn btn-sm btn-outline-danger";
   deleteButton.title = "削除";
@@ -283,6 +1534,8 @@ function addShareToList(shareId, editable) {
   deleteButton.onclick = () => deleteShare(shareId);
 
   shareActions.appendChild(copyButton);
+  shareActions.appendChild(passwordButton);
+  shareActions.appendChild(accessButton);
   shareActions.appendChild(deleteButton);
 
   shareContent.appendChild(shareInfo);
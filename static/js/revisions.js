@@ -0,0 +1,28 @@
+// リビジョン表示用JavaScript
+
+function restoreRevision() {
+  const dataElement = document.getElementById("revision-data");
+  const noteId = dataElement.dataset.noteId;
+  const revisionId = dataElement.dataset.revisionId;
+
+  if (!confirm("このリビジョンの内容でメモを復元しますか？\n現在の内容は履歴に残ります。")) {
+    return;
+  }
+
+  fetch(
+    "/note/" + encodeURIComponent(noteId) + "/revisions/" + encodeURIComponent(revisionId) + "/restore",
+    { method: "POST" }
+  )
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.href = "/note/" + encodeURIComponent(noteId);
+      } else {
+        alert("復元に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("復元に失敗しました");
+    });
+}
@@ -0,0 +1,196 @@
+// 設定画面用JavaScript
+
+function saveTitleFormat(event) {
+  event.preventDefault();
+
+  const format = document.getElementById("title-format").value.trim();
+  fetch("/user/settings", {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ defaultTitleFormat: format }),
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else {
+        alert("タイトルの形式を保存できませんでした");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("タイトルの形式を保存できませんでした");
+    });
+}
+
+function renameTemplate(templateId, currentName) {
+  const name = prompt("新しいテンプレート名を入力してください", currentName);
+  if (!name || name.trim() === currentName) {
+    return;
+  }
+
+  fetch("/template/" + encodeURIComponent(templateId), {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ name: name.trim() }),
+  })
+    .then((response) => response.json().then((data) => ({ status: response.status, data: data })))
+    .then(({ status, data }) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else if (status === 409) {
+        alert("同じ名前のテンプレートがあります");
+      } else {
+        alert("名前の変更に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("名前の変更に失敗しました");
+    });
+}
+
+function deleteTemplate(templateId) {
+  if (!confirm("このテンプレートを削除しますか？")) {
+    return;
+  }
+
+  fetch("/template/" + encodeURIComponent(templateId), {
+    method: "DELETE",
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else {
+        alert("削除に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("削除に失敗しました");
+    });
+}
+
+function createAccessToken(event) {
+  event.preventDefault();
+
+  const name = document.getElementById("token-name").value.trim();
+  const scopes = Array.from(document.querySelectorAll('input[name="scope"]:checked')).map(
+    (input) => input.value
+  );
+  const expiresInDays = parseInt(document.getElementById("token-expiry").value, 10);
+
+  if (!name) {
+    alert("名前を入力してください");
+    return;
+  }
+  if (scopes.length === 0) {
+    alert("スコープを1つ以上選択してください");
+    return;
+  }
+
+  fetch("/user/tokens", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ name: name, scopes: scopes, expiresInDays: expiresInDays }),
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        document.getElementById("token-form").classList.add("d-none");
+        document.getElementById("new-token-value").value = data.token;
+        document.getElementById("new-token").classList.remove("d-none");
+      } else {
+        alert("トークンの発行に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("トークンの発行に失敗しました");
+    });
+}
+
+function copyNewToken() {
+  const input = document.getElementById("new-token-value");
+  navigator.clipboard.writeText(input.value).catch(() => {
+    input.select();
+  });
+}
+
+function revokeAccessToken(tokenId) {
+  if (!confirm("このトークンを取り消しますか？\nこのトークンを使っているクライアントは API を呼び出せなくなります。")) {
+    return;
+  }
+
+  fetch("/user/tokens/" + encodeURIComponent(tokenId), {
+    method: "DELETE",
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else {
+        alert("取り消しに失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("取り消しに失敗しました");
+    });
+}
+
+// ZIP / ENEX からメモを取り込み、取り込めなかったファイルを一覧で示す
+function importNotes(event) {
+  event.preventDefault();
+
+  const input = document.getElementById("import-file");
+  if (input.files.length === 0) {
+    return;
+  }
+  const formData = new FormData();
+  formData.append("file", input.files[0]);
+
+  const button = document.getElementById("import-button");
+  button.disabled = true;
+  fetch("/note/import", {
+    method: "POST",
+    body: formData,
+  })
+    .then((response) => response.json().then((data) => ({ ok: response.ok, data: data })))
+    .then(({ ok, data }) => {
+      if (!ok) {
+        throw new Error(data.error || "import failed");
+      }
+      renderImportResult(data);
+      input.value = "";
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("インポートに失敗しました: " + error.message);
+    })
+    .finally(() => {
+      button.disabled = false;
+    });
+}
+
+function renderImportResult(data) {
+  const summary = document.getElementById("import-summary");
+  summary.textContent = data.imported + "件のメモを取り込みました";
+  if (data.failed > 0) {
+    summary.textContent += "（" + data.failed + "件は取り込めませんでした）";
+  }
+
+  const failures = document.getElementById("import-failures");
+  failures.innerHTML = "";
+  data.results
+    .filter((result) => result.status === "failed")
+    .forEach((result) => {
+      const item = document.createElement("li");
+      item.className = "list-group-item list-group-item-warning";
+      item.textContent = result.file + ": " + result.error;
+      failures.appendChild(item);
+    });
+
+  document.getElementById("import-result").classList.remove("d-none");
+}
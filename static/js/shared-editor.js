@@ -2,6 +2,8 @@
 
 let isUnsaved = false;
 let lastSavedContent = "";
+// sharedNoteConfig.version の版の本文。分からない間は null で、保存時に全文を送る
+let baseContent = null;
 let sharedNoteConfig = {};
 
 // updateSaveStatus関数はcommon-editor.jsに移動
@@ -12,12 +14,16 @@ document.addEventListener("DOMContentLoaded", function () {
     shareId: dataElement.dataset.shareId,
     editable: dataElement.dataset.editable === "true",
     isSharedView: dataElement.dataset.isSharedView === "true",
+    version: Number(dataElement.dataset.version),
+    editorName: dataElement.dataset.editorName || "",
+    editorLoggedIn: dataElement.dataset.editorLoggedIn === "true",
   };
 
   const noteContent = document.getElementById("note-content");
 
   if (noteContent) {
     lastSavedContent = noteContent.value;
+    baseContent = noteContent.value;
     updateStats();
     // 初期状態は保存済み
     updateSaveStatus("saved");
@@ -28,13 +34,22 @@ document.addEventListener("DOMContentLoaded", function () {
       autoSaveInterval: 5000,
       enableKeyboardShortcuts: true,
       saveCallback: () => {
-        if (isUnsaved) {
+        // 共同編集中はサーバー側で自動保存される
+        if (isUnsaved && !isCollaborating()) {
           saveSharedNote();
         }
       },
       markUnsavedCallback: markUnsaved
     });
 
+    // ログインしていない編集者は、表示名を入力してから編集を始める
+    if (sharedNoteConfig.editorLoggedIn || sharedNoteConfig.editorName) {
+      startSharedCollaboration();
+    } else {
+      noteContent.readOnly = true;
+      promptEditorName();
+    }
+
     // ページ離脱時の警告
     window.addEventListener("beforeunload", function (e) {
       if (isUnsaved) {
@@ -51,6 +66,78 @@ document.addEventListener("DOMContentLoaded", function () {
 
 // updateStats, updateCursorPosition, updateSelectionInfo関数はcommon-editor.jsに移動
 
+// 共同編集に参加する。接続中の編集者には表示名が表示される
+function startSharedCollaboration() {
+  const noteContent = document.getElementById("note-content");
+  startCollaboration(`/share/${encodeURIComponent(sharedNoteConfig.shareId)}/ws`, {
+    onTitle: applyCollabTitle,
+    onSaved: (version, upToDate) => {
+      sharedNoteConfig.version = version;
+      baseContent = upToDate ? noteContent.value : null;
+      if (upToDate) {
+        lastSavedContent = noteContent.value;
+        isUnsaved = false;
+        updateSaveStatus("saved");
+      }
+    },
+  });
+}
+
+// 表示名の入力ダイアログを開く
+function promptEditorName() {
+  const modalElement = document.getElementById("editor-name-modal");
+  const input = document.getElementById("editor-name-input");
+  input.value = sharedNoteConfig.editorName;
+  modalElement.addEventListener("shown.bs.modal", () => input.focus(), { once: true });
+  bootstrap.Modal.getOrCreateInstance(modalElement).show();
+}
+
+// 表示名を Cookie に記憶し、以降の変更をその名前で記録する
+function saveEditorName(event) {
+  event.preventDefault();
+  const name = document.getElementById("editor-name-input").value.trim();
+  if (!name) {
+    return;
+  }
+
+  fetch(`/share/${encodeURIComponent(sharedNoteConfig.shareId)}/name`, {
+    method: "PUT",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ name: name }),
+  })
+    .then((response) => {
+      if (response.status === 200) {
+        return response.json();
+      } else if (response.status === 400) {
+        throw new Error("名前は50文字以内で入力してください");
+      } else if (response.status === 401) {
+        throw new Error("パスワードの再入力が必要です。ページを再読み込みしてください");
+      } else {
+        throw new Error(`HTTP ${response.status}: ${response.statusText}`);
+      }
+    })
+    .then((data) => {
+      const firstTime = !sharedNoteConfig.editorName;
+      sharedNoteConfig.editorName = data.name;
+      document.getElementById("editor-name").textContent = data.name;
+      bootstrap.Modal.getOrCreateInstance(document.getElementById("editor-name-modal")).hide();
+
+      if (firstTime) {
+        document.getElementById("note-content").readOnly = false;
+        startSharedCollaboration();
+      } else {
+        // 接続中の編集者の表示を新しい名前にする
+        reconnectCollab();
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      showToast(`表示名の保存に失敗しました: ${error.message}`, "error");
+    });
+}
+
 function markUnsaved() {
   const content = document.getElementById("note-content").value;
   if (content !== lastSavedContent) {
@@ -60,6 +147,15 @@ function markUnsaved() {
 }
 
 function saveSharedNote() {
+  if (!sharedNoteConfig.editorLoggedIn && !sharedNoteConfig.editorName) {
+    promptEditorName();
+    return;
+  }
+  if (isCollaborating()) {
+    collabSave();
+    return;
+  }
+
   const shareId = sharedNoteConfig.shareId;
   const content = document.getElementById("note-content").value;
   const title = document.getElementById("note-title").textContent;
@@ -74,28 +170,73 @@ function saveSharedNote() {
     },
     body: JSON.stringify({
       title: title,
-      content: content,
+      ...contentSaveFields(baseContent, content),
+      version: sharedNoteConfig.version,
     }),
   })
     .then((response) => {
+      if (response.status === 409) {
+        return response.json().then((data) => {
+          // 競合の解決後はサーバーの版を基準に差分を送る
+          baseContent = data.note.content;
+          updateSaveStatus("error");
+          showConflictDialog(data.note, { title: title, content: content }, applyResolvedNote);
+          return null;
+        });
+      }
+      if (response.status === 400 && baseContent !== null) {
+        // 手元の基準の本文がサーバーと異なっていたため、全文で保存し直す
+        baseContent = null;
+        saveSharedNote();
+        return null;
+      }
+      if (response.status === 401) {
+        // パスワードが変更されたか、入力してから時間が経った
+        throw new Error("パスワードの再入力が必要です。ページを再読み込みしてください");
+      }
       if (!response.ok) {
         throw new Error("保存に失敗しました");
       }
       return response.json();
     })
     .then((data) => {
+      if (!data) {
+        return;
+      }
+      sharedNoteConfig.version = data.version;
       lastSavedContent = content;
+      baseContent = content;
       isUnsaved = false;
       updateSaveStatus("saved");
       showToast("保存が完了しました", "success");
     })
     .catch((error) => {
       console.error("Error:", error);
+      baseContent = null;
       updateSaveStatus("error");
       showToast("保存に失敗しました: " + error.message, "error");
     });
 }
 
+// 競合解決ダイアログで選ばれた内容をエディターに反映する
+function applyResolvedNote(resolved) {
+  sharedNoteConfig.version = resolved.version;
+  document.getElementById("note-title").textContent = resolved.title;
+  document.getElementById("title-input").value = resolved.title;
+  document.getElementById("note-content").value = resolved.content;
+  updateStats();
+
+  if (resolved.action === "local") {
+    saveSharedNote();
+  } else if (resolved.action === "server") {
+    lastSavedContent = resolved.content;
+    isUnsaved = false;
+    updateSaveStatus("saved");
+  } else {
+    markUnsaved();
+  }
+}
+
 // editTitle関数はcommon-editor.jsに移動
 
 function saveTitle() {
@@ -108,10 +249,26 @@ function saveTitle() {
   titleInput.classList.add("d-none");
 
   // タイトルの変更を保存
+  if (isCollaborating()) {
+    // 共同編集中はサーバーがまとめて保存する
+    collabSetTitle(newTitle);
+    isUnsaved = true;
+    updateSaveStatus("unsaved");
+    return;
+  }
   markUnsaved();
   saveSharedNote();
 }
 
+// 他の編集者が変更したタイトルを反映する（編集中の入力欄は上書きしない）
+function applyCollabTitle(title) {
+  const titleInput = document.getElementById("title-input");
+  document.getElementById("note-title").textContent = title;
+  if (titleInput.classList.contains("d-none")) {
+    titleInput.value = title;
+  }
+}
+
 function copyCurrentUrl() {
   copyToClipboard(window.location.href, "リンクをクリップボードにコピーしました", "リンクのコピーに失敗しました");
 }
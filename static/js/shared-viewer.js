@@ -6,18 +6,66 @@ document.addEventListener("DOMContentLoaded", function () {
   // HTMLのデータ属性から設定を取得
   const dataElement = document.getElementById("shared-note-data");
   sharedNoteConfig = {
-    noteId: dataElement.dataset.noteId,
+    shareId: dataElement.dataset.shareId,
     editable: dataElement.dataset.editable === "true",
     isSharedView: dataElement.dataset.isSharedView === "true",
+    version: Number(dataElement.dataset.version),
+    format: dataElement.dataset.format,
   };
 
-  // 日時を現地時間に変換
-  convertUtcToLocal();
+  // 日時の現地時間への変換は datetime-utils.js が読み込み時に行う
 
   // 読み取り専用での追加機能があれば実装
   setupReadOnlyFeatures();
+
+  // オーナーの保存をリロードせずに反映する
+  subscribeNoteUpdates();
 });
 
+// Server-Sent Events で保存のたびに最新の内容を受け取る
+// 切断時は EventSource が自動で再接続する
+function subscribeNoteUpdates() {
+  if (typeof EventSource === "undefined") return;
+
+  const source = new EventSource(`/share/${encodeURIComponent(sharedNoteConfig.shareId)}/events`);
+  source.addEventListener("update", (event) => {
+    applyNoteUpdate(JSON.parse(event.data));
+  });
+  source.addEventListener("deleted", () => {
+    source.close();
+    showToast("このメモは削除されました", "error");
+  });
+}
+
+function applyNoteUpdate(update) {
+  // 再接続時などに古い内容で上書きしない
+  if (update.version < sharedNoteConfig.version) return;
+  sharedNoteConfig.version = update.version;
+
+  // 表示形式が変わった場合は表示要素が異なるため読み込み直す
+  if (update.format !== sharedNoteConfig.format) {
+    window.location.reload();
+    return;
+  }
+
+  document.getElementById("note-title").textContent = update.title;
+  const contentElement = document.querySelector(".shared-note-content");
+  if (contentElement) {
+    if (contentElement.tagName === "TEXTAREA") {
+      contentElement.value = update.content;
+    } else {
+      // サーバー側でサニタイズ済みの HTML
+      contentElement.innerHTML = update.html;
+    }
+  }
+
+  const updatedAt = document.getElementById("note-updated-at");
+  if (updatedAt && update.updatedAt) {
+    updatedAt.setAttribute("data-utc-time", update.updatedAt);
+    updatedAt.textContent = formatToJST(update.updatedAt);
+  }
+}
+
 function setupReadOnlyFeatures() {
   const contentDiv = document.querySelector(".shared-note-content");
   if (contentDiv) {
@@ -0,0 +1,63 @@
+// ゴミ箱画面用JavaScript
+
+function restoreNote(noteId) {
+  fetch("/note/trash/" + encodeURIComponent(noteId) + "/restore", {
+    method: "POST",
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.href = "/note/" + encodeURIComponent(noteId);
+      } else {
+        alert("復元に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("復元に失敗しました");
+    });
+}
+
+function deleteNotePermanently(noteId) {
+  if (!confirm("このメモを完全に削除しますか？\n履歴や共有リンクも削除され、元に戻せません。")) {
+    return;
+  }
+
+  fetch("/note/trash/" + encodeURIComponent(noteId), {
+    method: "DELETE",
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else {
+        alert("削除に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("削除に失敗しました");
+    });
+}
+
+function emptyTrash() {
+  if (!confirm("ゴミ箱のメモをすべて完全に削除しますか？\nこの操作は元に戻せません。")) {
+    return;
+  }
+
+  fetch("/note/trash", {
+    method: "DELETE",
+  })
+    .then((response) => response.json())
+    .then((data) => {
+      if (data.status === "success") {
+        window.location.reload();
+      } else {
+        alert("削除に失敗しました");
+      }
+    })
+    .catch((error) => {
+      console.error("Error:", error);
+      alert("削除に失敗しました");
+    });
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/infra/storage"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+)
+
+const maxAttachmentNameLength = 255
+
+var (
+	ErrAttachmentTooLarge       = errors.New("attachment is too large")
+	ErrAttachmentTypeNotAllowed = errors.New("attachment type is not allowed")
+)
+
+// allowedAttachmentTypes lists the content types accepted for upload. The
+// type is sniffed from the content, not taken from the client.
+var allowedAttachmentTypes = map[string]bool{
+	"image/png":                 true,
+	"image/jpeg":                true,
+	"image/gif":                 true,
+	"image/webp":                true,
+	"application/pdf":           true,
+	"text/plain; charset=utf-8": true,
+}
+
+// blobMu serializes storing blobs and collecting unreferenced ones, so that a
+// blob being uploaded is not removed before its metadata row is committed.
+var blobMu sync.Mutex
+
+type IAttachmentUsecase interface {
+	Upload(note *domain.Note, fileName string, r io.Reader) (*domain.Attachment, error)
+	FindByNote(note *domain.Note) ([]*domain.Attachment, error)
+	Find(note *domain.Note, attachmentId string) (*domain.Attachment, error)
+	Open(attachment *domain.Attachment) (io.ReadCloser, error)
+	Delete(attachment *domain.Attachment) error
+}
+
+type attachmentUsecase struct {
+	db       *gorm.DB
+	storage  storage.IBlobStorage
+	maxBytes int64
+}
+
+func NewAttachmentUsecase() IAttachmentUsecase {
+	db := sqlite.GetDB()
+	return &attachmentUsecase{
+		db:       db,
+		storage:  storage.GetStorage(),
+		maxBytes: env.Get().AttachmentMaxBytes,
+	}
+}
+
+func (a *attachmentUsecase) newQuery() (*repository.Query, repository.IAttachmentDo) {
+	q := repository.Use(a.db)
+	do := q.Attachment.WithContext(a.db.Statement.Context)
+	return q, do
+}
+
+// limitedReader fails with ErrAttachmentTooLarge once more than n bytes are read.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	read, err := l.r.Read(p)
+	l.n -= int64(read)
+	if l.n < 0 {
+		return read, ErrAttachmentTooLarge
+	}
+	return read, err
+}
+
+// sanitizeFileName keeps only the base name and drops control characters.
+func sanitizeFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 32 || r == 127 || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		name = "file"
+	}
+	for utf8.RuneCountInString(name) > maxAttachmentNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func (a *attachmentUsecase) Upload(note *domain.Note, fileName string, r io.Reader) (*domain.Attachment, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+
+	buffered := bufio.NewReaderSize(r, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	contentType := http.DetectContentType(head)
+	if !allowedAttachmentTypes[contentType] {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	blobMu.Lock()
+	defer blobMu.Unlock()
+
+	key, size, err := a.storage.Put(&limitedReader{r: buffered, n: a.maxBytes})
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &domain.Attachment{
+		ID:          newUUID(),
+		NoteID:      note.ID,
+		BlobKey:     key,
+		FileName:    sanitizeFileName(fileName),
+		ContentType: contentType,
+		Size:        int32(size),
+	}
+	_, do := a.newQuery()
+	if err := do.Create(attachment); err != nil {
+		collectBlobs(a.db, a.storage, []string{key})
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (a *attachmentUsecase) FindByNote(note *domain.Note) ([]*domain.Attachment, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := a.newQuery()
+	return do.Where(q.Attachment.NoteID.Eq(note.ID)).Order(q.Attachment.CreatedAt).Find()
+}
+
+// Find returns the attachment only if it belongs to note.
+func (a *attachmentUsecase) Find(note *domain.Note, attachmentId string) (*domain.Attachment, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := a.newQuery()
+	return do.Where(q.Attachment.ID.Eq(attachmentId), q.Attachment.NoteID.Eq(note.ID)).First()
+}
+
+func (a *attachmentUsecase) Open(attachment *domain.Attachment) (io.ReadCloser, error) {
+	if attachment == nil {
+		return nil, errors.New("attachment cannot be nil")
+	}
+	return a.storage.Open(attachment.BlobKey)
+}
+
+func (a *attachmentUsecase) Delete(attachment *domain.Attachment) error {
+	if attachment == nil {
+		return errors.New("attachment cannot be nil")
+	}
+	q, do := a.newQuery()
+	if _, err := do.Where(q.Attachment.ID.Eq(attachment.ID)).Delete(); err != nil {
+		return err
+	}
+	blobMu.Lock()
+	defer blobMu.Unlock()
+	collectBlobs(a.db, a.storage, []string{attachment.BlobKey})
+	return nil
+}
+
+// attachmentBlobKeys returns the blob keys used by the attachments of notes.
+func attachmentBlobKeys(tx *repository.Query, noteIDs []string) ([]string, error) {
+	var keys []string
+	err := tx.Attachment.WithContext(context.Background()).
+		Distinct(tx.Attachment.BlobKey).
+		Where(tx.Attachment.NoteID.In(noteIDs...)).
+		Pluck(tx.Attachment.BlobKey, &keys)
+	return keys, err
+}
+
+// collectBlobs removes the blobs among keys that no attachment refers to.
+// Callers must hold blobMu. Failures are logged because the metadata has
+// already been deleted and an orphaned blob is harmless.
+func collectBlobs(db *gorm.DB, blobs storage.IBlobStorage, keys []string) {
+	q := repository.Use(db)
+	do := q.Attachment.WithContext(db.Statement.Context)
+	for _, key := range keys {
+		count, err := do.Where(q.Attachment.BlobKey.Eq(key)).Count()
+		if err != nil {
+			slog.Error("failed to count blob references", "key", key, "error", err)
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+		if err := blobs.Delete(key); err != nil {
+			slog.Error("failed to delete blob", "key", key, "error", err)
+			continue
+		}
+		slog.Info("deleted unreferenced blob", "key", key)
+	}
+}
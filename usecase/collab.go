@@ -0,0 +1,650 @@
+package usecase
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"gorm.io/gorm"
+)
+
+const (
+	// collabSaveDelay is how long edits are collected before they are saved.
+	collabSaveDelay = 2 * time.Second
+	// collabHistoryLimit is the number of operations kept for clients that
+	// reconnect. Older clients receive the whole document again.
+	collabHistoryLimit = 500
+	// collabMaxContentLength matches the limit of the note editor form.
+	collabMaxContentLength = 1000000
+	// collabOutboxSize is the number of messages buffered per client before
+	// the client is considered too slow and disconnected.
+	collabOutboxSize = 256
+	// collabServerClientID marks operations made by the server itself, such
+	// as merging a save made outside the session.
+	collabServerClientID = "server"
+)
+
+var (
+	// ErrInvalidTextOp is returned when an operation cannot be applied to the
+	// current document, or would insert disallowed characters.
+	ErrInvalidTextOp = errors.New("invalid text operation")
+	// ErrCollabClientInUse is returned when a client ID is already connected
+	// as another editor.
+	ErrCollabClientInUse = errors.New("collab client id is used by another editor")
+)
+
+// CollabResume identifies the state a reconnecting client already has. The
+// zero value asks for the whole document.
+type CollabResume struct {
+	Epoch string
+	Rev   int
+}
+
+// CollabPresence describes a client connected to a session. ID is assigned
+// per connection; the ID chosen by the client is never shown to others.
+type CollabPresence struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Self  bool   `json:"self"`
+}
+
+// Messages sent to clients. Each has a Type so the client can dispatch on it.
+type (
+	// CollabInitMessage carries the whole document. It is sent on join and
+	// whenever a client cannot be brought up to date with operations.
+	CollabInitMessage struct {
+		Type    string `json:"type"`
+		Epoch   string `json:"epoch"`
+		Rev     int    `json:"rev"`
+		Title   string `json:"title"`
+		Content string `json:"content"`
+		Version int32  `json:"version"`
+	}
+	// CollabResumeMessage tells a reconnecting client that the operations
+	// it missed follow as CollabOpMessage.
+	CollabResumeMessage struct {
+		Type string `json:"type"`
+		Rev  int    `json:"rev"`
+	}
+	// CollabOpMessage is an operation of another client applied by the
+	// server as revision Rev.
+	CollabOpMessage struct {
+		Type string `json:"type"`
+		Rev  int    `json:"rev"`
+		Op   TextOp `json:"op"`
+	}
+	// CollabAckMessage tells a client that its operation Seq was applied as
+	// revision Rev.
+	CollabAckMessage struct {
+		Type string `json:"type"`
+		Rev  int    `json:"rev"`
+		Seq  int    `json:"seq"`
+	}
+	CollabTitleMessage struct {
+		Type  string `json:"type"`
+		Title string `json:"title"`
+	}
+	CollabPresenceMessage struct {
+		Type    string           `json:"type"`
+		Clients []CollabPresence `json:"clients"`
+	}
+	CollabSavedMessage struct {
+		Type      string     `json:"type"`
+		Version   int32      `json:"version"`
+		UpdatedAt *time.Time `json:"updatedAt"`
+	}
+	// CollabErrorMessage reports a problem. Code "gone" means the note was
+	// deleted and "revoked" that the client may no longer edit it; in both
+	// cases the client should stop reconnecting.
+	CollabErrorMessage struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+)
+
+// ICollabUsecase manages real-time editing sessions. All editors of a note,
+// the owner and editable share links alike, join the same session, which
+// orders their operations, broadcasts them and saves the merged document.
+type ICollabUsecase interface {
+	Join(note *domain.Note, clientID string, label string, editor NoteEditor, resume CollabResume, access CollabAccess) (*CollabClient, error)
+}
+
+type collabUsecase struct {
+	noteUsecase INoteUsecase
+	mu          sync.Mutex
+	sessions    map[string]*collabSession
+}
+
+var (
+	collabInstance *collabUsecase
+	collabOnce     sync.Once
+)
+
+// NewCollabUsecase returns the process-wide session manager, so that every
+// controller sees the same sessions.
+func NewCollabUsecase() ICollabUsecase {
+	collabOnce.Do(func() {
+		collabInstance = &collabUsecase{
+			noteUsecase: NewNoteUsecase(),
+			sessions:    map[string]*collabSession{},
+		}
+	})
+	return collabInstance
+}
+
+// collabSession is the authoritative state of one note being edited. Every
+// field is guarded by mu.
+type collabSession struct {
+	manager *collabUsecase
+	noteID  string
+	// epoch changes whenever a session is created, so that revisions of an
+	// earlier session are never mistaken for the current ones.
+	epoch string
+	mu    sync.Mutex
+
+	text    []rune
+	title   string
+	version int32
+	rev     int
+	// history holds the operations of revisions historyStart+1 to rev.
+	history      []collabHistoryEntry
+	historyStart int
+	lastSeq      map[string]int
+
+	// savedText and savedTitle are what was stored at version. They are the
+	// common base when merging a save made outside the session.
+	savedText  []rune
+	savedTitle string
+	dirty      bool
+	// editors changed the document since the last save, the most recent
+	// last. The revision of the next save credits all of them.
+	editors   []NoteEditor
+	saveTimer *time.Timer
+
+	clients map[string]*CollabClient
+	// labels keeps the label of a client ID so it is unchanged on reconnect.
+	labels map[string]string
+	// unsubscribe ends the subscription to the events of the note.
+	unsubscribe func()
+}
+
+type collabHistoryEntry struct {
+	clientID string
+	seq      int
+	op       TextOp
+}
+
+// CollabAccess reports whether a client may still edit the note. It is
+// called when the sharing of the note changes.
+type CollabAccess func() bool
+
+// CollabClient is one connection to a session. Messages for the client are
+// delivered on Outbox, which is closed when the client leaves or falls too
+// far behind.
+type CollabClient struct {
+	id         string
+	presenceID string
+	label      string
+	editor     NoteEditor
+	access     CollabAccess
+	session    *collabSession
+	outbox     chan any
+	closed     bool
+}
+
+// Join connects a client to the session of note, starting one if needed.
+// clientID is chosen by the client and stays the same across reconnects; it
+// is rejected while another editor uses it. The client is disconnected once
+// access returns false.
+func (c *collabUsecase) Join(note *domain.Note, clientID string, label string, editor NoteEditor, resume CollabResume, access CollabAccess) (*CollabClient, error) {
+	c.mu.Lock()
+	session, exists := c.sessions[note.ID]
+	if !exists {
+		session = newCollabSession(c, note)
+		c.sessions[note.ID] = session
+	}
+	session.mu.Lock()
+	c.mu.Unlock()
+	defer session.mu.Unlock()
+
+	if exists {
+		// セッション外で保存された変更があれば取り込む
+		if err := session.mergeStoredLocked(); err != nil {
+			slog.Error("failed to merge stored note into session", "noteId", note.ID, "error", err)
+		}
+	}
+
+	if old, ok := session.clients[clientID]; ok {
+		if old.editor != editor {
+			return nil, ErrCollabClientInUse
+		}
+		// 再接続前の古い接続は閉じる
+		old.closeLocked()
+	}
+	if _, ok := session.labels[clientID]; !ok {
+		session.labels[clientID] = label + " " + strconv.Itoa(len(session.labels)+1)
+	}
+	client := &CollabClient{
+		id:         clientID,
+		presenceID: newUUID(),
+		label:      session.labels[clientID],
+		editor:     editor,
+		access:     access,
+		session:    session,
+		outbox:     make(chan any, collabOutboxSize),
+	}
+	session.clients[clientID] = client
+
+	if resume.Epoch == session.epoch && resume.Rev >= session.historyStart && resume.Rev <= session.rev {
+		client.sendLocked(CollabResumeMessage{Type: "resume", Rev: resume.Rev})
+		for i, entry := range session.history[resume.Rev-session.historyStart:] {
+			client.sendLocked(entry.messageFor(client, resume.Rev+i+1))
+		}
+		client.sendLocked(CollabTitleMessage{Type: "title", Title: session.title})
+	} else {
+		client.sendLocked(session.initMessageLocked())
+	}
+	session.broadcastPresenceLocked()
+	return client, nil
+}
+
+func newCollabSession(manager *collabUsecase, note *domain.Note) *collabSession {
+	text := []rune(note.Content)
+	session := &collabSession{
+		manager:    manager,
+		noteID:     note.ID,
+		epoch:      newUUID(),
+		text:       text,
+		title:      note.Title,
+		version:    note.Version,
+		lastSeq:    map[string]int{},
+		savedText:  text,
+		savedTitle: note.Title,
+		clients:    map[string]*CollabClient{},
+		labels:     map[string]string{},
+	}
+	events, unsubscribe := manager.noteUsecase.Subscribe(note.ID)
+	session.unsubscribe = unsubscribe
+	go session.watchNoteEvents(events)
+	return session
+}
+
+// watchNoteEvents merges saves made outside the session as soon as they are
+// committed, disconnects clients that lost access to the note, and ends the
+// session when the note is moved to the trash.
+func (s *collabSession) watchNoteEvents(events <-chan NoteEvent) {
+	for event := range events {
+		s.mu.Lock()
+		switch event.Type {
+		case NoteEventUpdated:
+			if err := s.mergeNoteLocked(event.Note); err != nil {
+				slog.Error("failed to merge stored note into session", "noteId", s.noteID, "error", err)
+			}
+		case NoteEventDeleted:
+			s.closeAsGoneLocked()
+		case NoteEventAccessChanged:
+			s.closeRevokedLocked()
+		}
+		s.mu.Unlock()
+	}
+}
+
+// release saves and discards the session once its last client has left.
+func (c *collabUsecase) release(session *collabSession) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if len(session.clients) > 0 || c.sessions[session.noteID] != session {
+		return
+	}
+	if session.saveTimer != nil {
+		session.saveTimer.Stop()
+		session.saveTimer = nil
+	}
+	session.saveLocked()
+	session.unsubscribe()
+	delete(c.sessions, session.noteID)
+}
+
+// ID is the identifier the client chose. It stays the same across reconnects.
+func (cl *CollabClient) ID() string {
+	return cl.id
+}
+
+func (cl *CollabClient) Outbox() <-chan any {
+	return cl.outbox
+}
+
+// SubmitOp applies op, which the client based on revision rev, after
+// transforming it against the operations the client had not seen yet. seq
+// numbers the operations of a client so that a resent one is not applied
+// twice.
+func (cl *CollabClient) SubmitOp(rev int, seq int, op TextOp) error {
+	s := cl.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cl.closed {
+		return nil
+	}
+	if seq <= s.lastSeq[cl.id] {
+		return nil
+	}
+	if rev < s.historyStart || rev > s.rev {
+		// 変換に必要な履歴がないため、文書全体を送り直す
+		cl.sendLocked(s.initMessageLocked())
+		return nil
+	}
+
+	for _, entry := range s.history[rev-s.historyStart:] {
+		transformed, _, err := TransformTextOps(op, entry.op)
+		if err != nil {
+			cl.sendLocked(s.initMessageLocked())
+			return ErrInvalidTextOp
+		}
+		op = transformed
+	}
+	if !validTextOp(op) {
+		cl.sendLocked(s.initMessageLocked())
+		return ErrInvalidTextOp
+	}
+	text, err := op.Apply(s.text)
+	if err != nil || len(string(text)) > collabMaxContentLength {
+		cl.sendLocked(s.initMessageLocked())
+		return ErrInvalidTextOp
+	}
+
+	s.text = text
+	s.lastSeq[cl.id] = seq
+	s.appendOpLocked(cl.id, seq, op)
+	s.markDirtyLocked(cl.editor)
+	return nil
+}
+
+// SetTitle replaces the title. Concurrent title changes are resolved by the
+// order in which they reach the server.
+func (cl *CollabClient) SetTitle(title string) {
+	s := cl.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cl.closed || title == s.title {
+		return
+	}
+	s.title = title
+	for _, client := range s.clients {
+		if client != cl {
+			client.sendLocked(CollabTitleMessage{Type: "title", Title: title})
+		}
+	}
+	s.markDirtyLocked(cl.editor)
+}
+
+// Save stores pending changes immediately instead of waiting for the
+// debounce timer.
+func (cl *CollabClient) Save() {
+	s := cl.session
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cl.closed {
+		return
+	}
+	if !s.dirty {
+		cl.sendLocked(CollabSavedMessage{Type: "saved", Version: s.version})
+		return
+	}
+	s.saveLocked()
+}
+
+// Leave disconnects the client. The session is saved and discarded when the
+// last client leaves.
+func (cl *CollabClient) Leave() {
+	s := cl.session
+	s.mu.Lock()
+	if !cl.closed {
+		cl.closeLocked()
+		s.broadcastPresenceLocked()
+	}
+	empty := len(s.clients) == 0
+	s.mu.Unlock()
+
+	if empty {
+		s.manager.release(s)
+	}
+}
+
+func (cl *CollabClient) closeLocked() {
+	if cl.closed {
+		return
+	}
+	cl.closed = true
+	close(cl.outbox)
+	if cl.session.clients[cl.id] == cl {
+		delete(cl.session.clients, cl.id)
+	}
+}
+
+// sendLocked queues msg without blocking. A client whose queue is full is
+// disconnected; it resumes from its last revision when it reconnects.
+func (cl *CollabClient) sendLocked(msg any) {
+	if cl.closed {
+		return
+	}
+	select {
+	case cl.outbox <- msg:
+	default:
+		slog.Warn("collab client is too slow, disconnecting", "noteId", cl.session.noteID, "clientId", cl.id)
+		cl.closeLocked()
+	}
+}
+
+func (s *collabSession) broadcastLocked(msg any) {
+	for _, client := range s.clients {
+		client.sendLocked(msg)
+	}
+}
+
+// broadcastPresenceLocked sends the list of connected clients to each of
+// them, marking the entry of the receiver itself.
+func (s *collabSession) broadcastPresenceLocked() {
+	for _, receiver := range s.clients {
+		clients := make([]CollabPresence, 0, len(s.clients))
+		for _, client := range s.clients {
+			clients = append(clients, CollabPresence{ID: client.presenceID, Label: client.label, Self: client == receiver})
+		}
+		sort.Slice(clients, func(i, j int) bool { return clients[i].Label < clients[j].Label })
+		receiver.sendLocked(CollabPresenceMessage{Type: "presence", Clients: clients})
+	}
+}
+
+func (s *collabSession) initMessageLocked() CollabInitMessage {
+	return CollabInitMessage{
+		Type:    "init",
+		Epoch:   s.epoch,
+		Rev:     s.rev,
+		Title:   s.title,
+		Content: string(s.text),
+		Version: s.version,
+	}
+}
+
+// appendOpLocked records op as the next revision, acknowledges it to its
+// author and sends it to every other client.
+func (s *collabSession) appendOpLocked(clientID string, seq int, op TextOp) {
+	s.rev++
+	entry := collabHistoryEntry{clientID: clientID, seq: seq, op: op}
+	s.history = append(s.history, entry)
+	if len(s.history) > collabHistoryLimit {
+		s.history = s.history[1:]
+		s.historyStart++
+	}
+	for _, client := range s.clients {
+		client.sendLocked(entry.messageFor(client, s.rev))
+	}
+}
+
+// messageFor returns the message that tells client about revision rev.
+func (e collabHistoryEntry) messageFor(client *CollabClient, rev int) any {
+	if e.clientID == client.id {
+		return CollabAckMessage{Type: "ack", Rev: rev, Seq: e.seq}
+	}
+	return CollabOpMessage{Type: "op", Rev: rev, Op: e.op}
+}
+
+func (s *collabSession) markDirtyLocked(editor NoteEditor) {
+	s.dirty = true
+	s.editors = append(slices.DeleteFunc(s.editors, func(e NoteEditor) bool { return e == editor }), editor)
+	if s.saveTimer == nil {
+		s.saveTimer = time.AfterFunc(collabSaveDelay, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.saveTimer = nil
+			s.saveLocked()
+		})
+	}
+}
+
+// saveLocked stores the document through INoteUsecase. When the note was
+// saved outside the session in the meantime, that change is merged first and
+// the save is retried once.
+func (s *collabSession) saveLocked() {
+	// 編集者がいなければ、セッション外で保存された内容を取り込んだだけで保存する変更はない
+	if !s.dirty || len(s.editors) == 0 {
+		return
+	}
+	updated, err := s.updateNoteLocked()
+	if errors.Is(err, ErrNoteConflict) {
+		if err := s.mergeStoredLocked(); err != nil {
+			slog.Error("failed to merge stored note into session", "noteId", s.noteID, "error", err)
+			return
+		}
+		if !s.dirty {
+			return
+		}
+		updated, err = s.updateNoteLocked()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// ゴミ箱に移動された、または完全に削除された
+		s.closeAsGoneLocked()
+		return
+	}
+	if err != nil {
+		slog.Error("failed to save collaborative note", "noteId", s.noteID, "error", err)
+		s.broadcastLocked(CollabErrorMessage{Type: "error", Code: "save_failed", Message: "failed to save note"})
+		return
+	}
+
+	s.version = updated.Version
+	s.savedText = []rune(updated.Content)
+	s.savedTitle = updated.Title
+	s.dirty = string(s.text) != updated.Content || s.title != updated.Title
+	if !s.dirty {
+		s.editors = nil
+	}
+	s.broadcastLocked(CollabSavedMessage{Type: "saved", Version: updated.Version, UpdatedAt: updated.UpdatedAt})
+}
+
+func (s *collabSession) updateNoteLocked() (*domain.Note, error) {
+	note := &domain.Note{
+		ID:      s.noteID,
+		Title:   s.title,
+		Content: string(s.text),
+		Version: s.version,
+	}
+	last := len(s.editors) - 1
+	return s.manager.noteUsecase.UpdateNoteByEditors(note, s.editors[last], s.editors[:last])
+}
+
+// mergeStoredLocked brings in a version of the note saved outside the
+// session. The external change and the unsaved session edits are both
+// expressed as operations on the last saved text and transformed against
+// each other, so neither is lost.
+func (s *collabSession) mergeStoredLocked() error {
+	stored, err := s.manager.noteUsecase.Find(s.noteID)
+	if err != nil {
+		return err
+	}
+	return s.mergeNoteLocked(stored)
+}
+
+// mergeNoteLocked merges stored unless the session already has that version
+// or a newer one.
+func (s *collabSession) mergeNoteLocked(stored *domain.Note) error {
+	if stored.Version <= s.version {
+		return nil
+	}
+
+	storedText := []rune(stored.Content)
+	external := diffTextOp(s.savedText, storedText)
+	local := diffTextOp(s.savedText, s.text)
+	_, externalPrime, err := TransformTextOps(local, external)
+	if err != nil {
+		return err
+	}
+	text, err := externalPrime.Apply(s.text)
+	if err != nil {
+		return err
+	}
+	s.text = text
+	if !externalPrime.IsNoop() {
+		s.appendOpLocked(collabServerClientID, 0, externalPrime)
+	}
+
+	if s.title == s.savedTitle && s.title != stored.Title {
+		s.title = stored.Title
+		s.broadcastLocked(CollabTitleMessage{Type: "title", Title: stored.Title})
+	}
+	s.savedText = storedText
+	s.savedTitle = stored.Title
+	s.version = stored.Version
+	s.dirty = string(s.text) != stored.Content || s.title != stored.Title
+	if !s.dirty {
+		s.editors = nil
+	}
+	return nil
+}
+
+// closeAsGoneLocked tells the clients that the note was deleted and
+// disconnects them. Unsaved edits are discarded.
+func (s *collabSession) closeAsGoneLocked() {
+	s.dirty = false
+	s.broadcastLocked(CollabErrorMessage{Type: "error", Code: "gone", Message: "note was deleted"})
+	for _, client := range s.clients {
+		client.closeLocked()
+	}
+}
+
+// closeRevokedLocked disconnects the clients that may no longer edit the
+// note. Their operations received so far are kept.
+func (s *collabSession) closeRevokedLocked() {
+	revoked := false
+	for _, client := range s.clients {
+		if client.access == nil || client.access() {
+			continue
+		}
+		client.sendLocked(CollabErrorMessage{Type: "error", Code: "revoked", Message: "access to the note was revoked"})
+		client.closeLocked()
+		revoked = true
+	}
+	if revoked {
+		s.broadcastPresenceLocked()
+	}
+}
+
+// validTextOp rejects inserts containing control characters, using the same
+// rule as the text input validation of the forms.
+func validTextOp(op TextOp) bool {
+	for _, c := range op {
+		for _, r := range c.insert {
+			if isControlRune(r) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package usecase
+
+import "strings"
+
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffInsert
+	DiffDelete
+)
+
+// DiffLine is a single line of a line-based diff.
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+func (d DiffLine) IsInsert() bool { return d.Op == DiffInsert }
+func (d DiffLine) IsDelete() bool { return d.Op == DiffDelete }
+
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
+
+// DiffLines computes a line-based diff from a to b using Myers' algorithm.
+func DiffLines(a, b string) []DiffLine {
+	x, y := splitLines(a), splitLines(b)
+
+	// 共通の先頭・末尾は探索対象から外す
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	result := make([]DiffLine, 0, len(x)+len(y))
+	for _, line := range x[:prefix] {
+		result = append(result, DiffLine{Op: DiffEqual, Text: line})
+	}
+	result = append(result, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		result = append(result, DiffLine{Op: DiffEqual, Text: line})
+	}
+	return result
+}
+
+func myers(x, y []string) []DiffLine {
+	n, m := len(x), len(y)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+	offset := max
+	v := make([]int, 2*max+2)
+	trace := make([][]int, 0, max+1)
+
+search:
+	for d := 0; d <= max; d++ {
+		snapshot := make([]int, len(v))
+		copy(snapshot, v)
+		trace = append(trace, snapshot)
+		for k := -d; k <= d; k += 2 {
+			var i int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				i = v[offset+k+1]
+			} else {
+				i = v[offset+k-1] + 1
+			}
+			j := i - k
+			for i < n && j < m && x[i] == y[j] {
+				i++
+				j++
+			}
+			v[offset+k] = i
+			if i >= n && j >= m {
+				break search
+			}
+		}
+	}
+
+	// 探索経路を逆順に辿って編集スクリプトを組み立てる
+	result := make([]DiffLine, 0, n+m)
+	i, j := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := i - j
+		var prevK int
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevI := v[offset+prevK]
+		prevJ := prevI - prevK
+		for i > prevI && j > prevJ {
+			i--
+			j--
+			result = append(result, DiffLine{Op: DiffEqual, Text: x[i]})
+		}
+		if d > 0 {
+			if i == prevI {
+				j--
+				result = append(result, DiffLine{Op: DiffInsert, Text: y[j]})
+			} else {
+				i--
+				result = append(result, DiffLine{Op: DiffDelete, Text: x[i]})
+			}
+		}
+	}
+
+	for l, r := 0, len(result)-1; l < r; l, r = l+1, r-1 {
+		result[l], result[r] = result[r], result[l]
+	}
+	return result
+}
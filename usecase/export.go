@@ -0,0 +1,336 @@
+package usecase
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+)
+
+const (
+	// exportFormatVersion is written to the manifest so that importers can
+	// tell which layout an archive uses.
+	exportFormatVersion = 1
+
+	// exportBatchSize bounds how many notes, each up to 1 MB, are held in
+	// memory while the archive is written.
+	exportBatchSize = 20
+
+	exportNotesDir     = "notes"
+	exportManifestName = "manifest.json"
+
+	maxExportFileNameLength = 100
+)
+
+// exportFrontMatter is the YAML front-matter at the top of every exported
+// note.
+type exportFrontMatter struct {
+	ID        string         `yaml:"id"`
+	Title     string         `yaml:"title"`
+	CreatedAt *time.Time     `yaml:"created_at,omitempty"`
+	UpdatedAt *time.Time     `yaml:"updated_at,omitempty"`
+	Format    string         `yaml:"format"`
+	Folder    string         `yaml:"folder,omitempty"`
+	Tags      []string       `yaml:"tags,omitempty"`
+	Shares    []*exportShare `yaml:"shares,omitempty"`
+}
+
+type exportShare struct {
+	ID       string `yaml:"id" json:"id"`
+	URL      string `yaml:"url" json:"url"`
+	Editable bool   `yaml:"editable" json:"editable"`
+}
+
+// exportManifest is written as manifest.json after every note.
+type exportManifest struct {
+	FormatVersion int                    `json:"format_version"`
+	ExportedAt    time.Time              `json:"exported_at"`
+	User          exportManifestUser     `json:"user"`
+	NoteCount     int                    `json:"note_count"`
+	Notes         []*exportManifestEntry `json:"notes"`
+}
+
+type exportManifestUser struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type exportManifestEntry struct {
+	ID        string         `json:"id"`
+	Title     string         `json:"title"`
+	Path      string         `json:"path"`
+	CreatedAt *time.Time     `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+	Format    string         `json:"format"`
+	Folder    string         `json:"folder,omitempty"`
+	Tags      []string       `json:"tags"`
+	Shares    []*exportShare `json:"shares"`
+}
+
+// ExportOptions controls what an archive contains. Share URLs are built by
+// appending /share/<id> to ShareBaseURL, and are left out unless
+// IncludeShares is set.
+type ExportOptions struct {
+	ShareBaseURL  string
+	IncludeShares bool
+}
+
+type IExportUsecase interface {
+	Prepare(user *domain.User, opts ExportOptions) (*NoteArchive, error)
+}
+
+type exportUsecase struct {
+	db *gorm.DB
+}
+
+func NewExportUsecase() IExportUsecase {
+	db := sqlite.GetDB()
+	return &exportUsecase{
+		db: db,
+	}
+}
+
+// NoteArchive writes every note of a user as a ZIP archive. The folders,
+// tags and share links are loaded by Prepare; the notes themselves are read
+// in batches while the archive is written.
+type NoteArchive struct {
+	db          *gorm.DB
+	user        *domain.User
+	folderPaths map[string]string
+	tags        map[string][]string
+	shares      map[string][]*exportShare
+}
+
+// Prepare loads what the archive needs besides the notes, so that errors can
+// still be reported before anything is written.
+func (e *exportUsecase) Prepare(user *domain.User, opts ExportOptions) (*NoteArchive, error) {
+	if user == nil {
+		return nil, errors.New("user cannot be nil")
+	}
+	q := repository.Use(e.db)
+	ctx := e.db.Statement.Context
+
+	folders, err := q.Folder.WithContext(ctx).Where(q.Folder.UserID.Eq(user.ID)).Find()
+	if err != nil {
+		return nil, err
+	}
+
+	var tagRows []struct {
+		NoteID string
+		Name   string
+	}
+	err = q.NoteTag.WithContext(ctx).Select(q.NoteTag.NoteID, q.Tag.Name).
+		Join(q.Tag, q.Tag.ID.EqCol(q.NoteTag.TagID)).
+		Where(q.Tag.UserID.Eq(user.ID)).
+		Order(q.Tag.Name).
+		Scan(&tagRows)
+	if err != nil {
+		return nil, err
+	}
+
+	var shares []*domain.SharingInfo
+	if opts.IncludeShares {
+		shares, err = q.SharingInfo.WithContext(ctx).
+			Join(q.Note, q.Note.ID.EqCol(q.SharingInfo.NoteID)).
+			Where(q.Note.AuthorID.Eq(user.ID)).
+			Order(q.SharingInfo.ID).
+			Find()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	archive := &NoteArchive{
+		db:          e.db,
+		user:        user,
+		folderPaths: exportFolderPaths(folders),
+		tags:        map[string][]string{},
+		shares:      map[string][]*exportShare{},
+	}
+	baseURL := strings.TrimSuffix(opts.ShareBaseURL, "/")
+	for _, row := range tagRows {
+		archive.tags[row.NoteID] = append(archive.tags[row.NoteID], row.Name)
+	}
+	for _, share := range shares {
+		archive.shares[share.NoteID] = append(archive.shares[share.NoteID], &exportShare{
+			ID:       share.ID,
+			URL:      baseURL + "/share/" + share.ID,
+			Editable: share.Editable,
+		})
+	}
+	return archive, nil
+}
+
+// exportFolderPaths returns the slash separated path of every folder, e.g.
+// "仕事/会議".
+func exportFolderPaths(folders []*domain.Folder) map[string]string {
+	byID := make(map[string]*domain.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.ID] = folder
+	}
+	paths := make(map[string]string, len(folders))
+	var resolve func(folder *domain.Folder, depth int) string
+	resolve = func(folder *domain.Folder, depth int) string {
+		if p, ok := paths[folder.ID]; ok {
+			return p
+		}
+		name := exportFileName(folder.Name)
+		// 親が見つからない場合や循環している場合はそこで打ち切る
+		if folder.ParentID != nil && depth < len(folders) {
+			if parent, ok := byID[*folder.ParentID]; ok {
+				name = resolve(parent, depth+1) + "/" + name
+			}
+		}
+		paths[folder.ID] = name
+		return name
+	}
+	for _, folder := range folders {
+		resolve(folder, 0)
+	}
+	return paths
+}
+
+// exportFileName turns a title into a name that is valid on common file
+// systems.
+func exportFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+	name = strings.Trim(name, " .")
+	if utf8.RuneCountInString(name) > maxExportFileNameLength {
+		name = string([]rune(name)[:maxExportFileNameLength])
+	}
+	if name == "" {
+		name = "untitled"
+	}
+	return name
+}
+
+// WriteZip streams the archive to w. Each note is written as
+// notes/<folder path>/<title>.md with YAML front-matter, followed by
+// manifest.json. Only one batch of notes is held in memory at a time.
+func (a *NoteArchive) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	manifest := exportManifest{
+		FormatVersion: exportFormatVersion,
+		ExportedAt:    time.Now().UTC(),
+		User:          exportManifestUser{ID: a.user.ID, Email: a.user.Email},
+		Notes:         []*exportManifestEntry{},
+	}
+	// 大文字小文字を区別しないファイルシステムでも重複しない名前にする
+	usedPaths := map[string]bool{}
+
+	q := repository.Use(a.db)
+	var batch []*domain.Note
+	err := q.Note.WithContext(a.db.Statement.Context).
+		Where(q.Note.AuthorID.Eq(a.user.ID)).
+		FindInBatches(&batch, exportBatchSize, func(tx gen.Dao, _ int) error {
+			for _, note := range batch {
+				entry, err := a.writeNote(zw, note, usedPaths)
+				if err != nil {
+					return err
+				}
+				manifest.Notes = append(manifest.Notes, entry)
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+
+	manifest.NoteCount = len(manifest.Notes)
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: exportManifestName, Method: zip.Deflate, Modified: manifest.ExportedAt})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(mw)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (a *NoteArchive) writeNote(zw *zip.Writer, note *domain.Note, usedPaths map[string]bool) (*exportManifestEntry, error) {
+	folder := ""
+	if note.FolderID != nil {
+		folder = a.folderPaths[*note.FolderID]
+	}
+	base := exportFileName(note.Title)
+	name := path.Join(exportNotesDir, folder, base+".md")
+	for i := 2; usedPaths[strings.ToLower(name)]; i++ {
+		name = path.Join(exportNotesDir, folder, fmt.Sprintf("%s (%d).md", base, i))
+	}
+	usedPaths[strings.ToLower(name)] = true
+
+	tags := a.tags[note.ID]
+	shares := a.shares[note.ID]
+	var front strings.Builder
+	encoder := yaml.NewEncoder(&front)
+	encoder.SetIndent(2)
+	err := encoder.Encode(exportFrontMatter{
+		ID:        note.ID,
+		Title:     note.Title,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+		Format:    note.Format,
+		Folder:    folder,
+		Tags:      tags,
+		Shares:    shares,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+	if note.UpdatedAt != nil {
+		header.Modified = *note.UpdatedAt
+	}
+	fw, err := zw.CreateHeader(header)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range []string{"---\n", front.String(), "---\n", note.Content} {
+		if _, err := io.WriteString(fw, part); err != nil {
+			return nil, err
+		}
+	}
+
+	if tags == nil {
+		tags = []string{}
+	}
+	if shares == nil {
+		shares = []*exportShare{}
+	}
+	return &exportManifestEntry{
+		ID:        note.ID,
+		Title:     note.Title,
+		Path:      name,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+		Format:    note.Format,
+		Folder:    folder,
+		Tags:      tags,
+		Shares:    shares,
+	}, nil
+}
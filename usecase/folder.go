@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+const maxFolderNameLength = 100
+
+var (
+	ErrInvalidFolderName = errors.New("invalid folder name")
+	// ErrFolderCycle is returned when a folder would become its own ancestor.
+	ErrFolderCycle = errors.New("folder cannot be moved into itself or its descendants")
+)
+
+// FolderDeleteMode decides what happens to the contents of a deleted folder.
+type FolderDeleteMode int
+
+const (
+	// FolderDeleteMoveToParent moves subfolders and notes to the parent folder.
+	FolderDeleteMoveToParent FolderDeleteMode = iota
+	// FolderDeleteCascade deletes subfolders and moves every note in them to
+	// the trash.
+	FolderDeleteCascade
+)
+
+type IFolderUsecase interface {
+	Find(folderId string) (*domain.Folder, error)
+	FindByUser(userID string) ([]*domain.Folder, error)
+	Create(user *domain.User, parent *domain.Folder, name string) (*domain.Folder, error)
+	Rename(folder *domain.Folder, name string) (*domain.Folder, error)
+	Move(folder *domain.Folder, parent *domain.Folder) error
+	Delete(folder *domain.Folder, mode FolderDeleteMode) error
+}
+
+type folderUsecase struct {
+	db *gorm.DB
+}
+
+func NewFolderUsecase() IFolderUsecase {
+	db := sqlite.GetDB()
+	return &folderUsecase{
+		db: db,
+	}
+}
+
+func (f *folderUsecase) newQuery() (*repository.Query, repository.IFolderDo) {
+	q := repository.Use(f.db)
+	do := q.Folder.WithContext(f.db.Statement.Context)
+	return q, do
+}
+
+func normalizeFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > maxFolderNameLength {
+		return "", ErrInvalidFolderName
+	}
+	return name, nil
+}
+
+// assignParent sets column to the parent's ID, or NULL for the top level.
+func assignParent(column field.String, parentID *string) field.AssignExpr {
+	if parentID == nil {
+		return column.Null()
+	}
+	return column.Value(*parentID)
+}
+
+func (f *folderUsecase) Find(folderId string) (*domain.Folder, error) {
+	q, do := f.newQuery()
+	return do.Where(q.Folder.ID.Eq(folderId)).First()
+}
+
+func (f *folderUsecase) FindByUser(userID string) ([]*domain.Folder, error) {
+	q, do := f.newQuery()
+	return do.Where(q.Folder.UserID.Eq(userID)).Order(q.Folder.Name).Find()
+}
+
+func (f *folderUsecase) Create(user *domain.User, parent *domain.Folder, name string) (*domain.Folder, error) {
+	if user == nil {
+		return nil, errors.New("user cannot be nil")
+	}
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	folder := &domain.Folder{
+		ID:     newUUID(),
+		UserID: user.ID,
+		Name:   name,
+	}
+	if parent != nil {
+		if parent.UserID != user.ID {
+			return nil, errors.New("cannot create folder in another user's folder")
+		}
+		folder.ParentID = &parent.ID
+	}
+	_, do := f.newQuery()
+	if err := do.Create(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (f *folderUsecase) Rename(folder *domain.Folder, name string) (*domain.Folder, error) {
+	if folder == nil {
+		return nil, errors.New("folder cannot be nil")
+	}
+	name, err := normalizeFolderName(name)
+	if err != nil {
+		return nil, err
+	}
+	q, do := f.newQuery()
+	if _, err := do.Where(q.Folder.ID.Eq(folder.ID)).UpdateSimple(q.Folder.Name.Value(name)); err != nil {
+		return nil, err
+	}
+	folder.Name = name
+	return folder, nil
+}
+
+// Move makes parent the new parent of folder, or moves it to the top level
+// when parent is nil. Moving a folder under itself or one of its descendants
+// returns ErrFolderCycle.
+func (f *folderUsecase) Move(folder *domain.Folder, parent *domain.Folder) error {
+	if folder == nil {
+		return errors.New("folder cannot be nil")
+	}
+	var parentID *string
+	if parent != nil {
+		if parent.UserID != folder.UserID {
+			return errors.New("cannot move folder into another user's folder")
+		}
+		parentID = &parent.ID
+	}
+
+	q, _ := f.newQuery()
+	return q.Transaction(func(tx *repository.Query) error {
+		if parentID != nil {
+			if err := checkFolderCycle(tx, folder.ID, *parentID); err != nil {
+				return err
+			}
+		}
+		do := tx.Folder.WithContext(context.Background())
+		_, err := do.Where(tx.Folder.ID.Eq(folder.ID)).UpdateSimple(assignParent(tx.Folder.ParentID, parentID))
+		if err != nil {
+			return err
+		}
+		folder.ParentID = parentID
+		return nil
+	})
+}
+
+// checkFolderCycle walks up from parentID to the root and fails if folderID
+// is found on the way.
+func checkFolderCycle(tx *repository.Query, folderID string, parentID string) error {
+	do := tx.Folder.WithContext(context.Background())
+	visited := map[string]bool{}
+	for id := parentID; ; {
+		if id == folderID {
+			return ErrFolderCycle
+		}
+		if visited[id] {
+			// 既存データが循環している場合も移動を拒否する
+			return ErrFolderCycle
+		}
+		visited[id] = true
+
+		current, err := do.Where(tx.Folder.ID.Eq(id)).First()
+		if err != nil {
+			return err
+		}
+		if current.ParentID == nil {
+			return nil
+		}
+		id = *current.ParentID
+	}
+}
+
+// descendantFolderIDs returns folderID and the IDs of all folders below it.
+func descendantFolderIDs(tx *repository.Query, folderID string) ([]string, error) {
+	do := tx.Folder.WithContext(context.Background())
+	ids := []string{folderID}
+	seen := map[string]bool{folderID: true}
+	for level := []string{folderID}; len(level) > 0; {
+		var children []string
+		if err := do.Where(tx.Folder.ParentID.In(level...)).Pluck(tx.Folder.ID, &children); err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for _, child := range children {
+			if !seen[child] {
+				seen[child] = true
+				ids = append(ids, child)
+				level = append(level, child)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (f *folderUsecase) Delete(folder *domain.Folder, mode FolderDeleteMode) error {
+	if folder == nil {
+		return errors.New("folder cannot be nil")
+	}
+	var trashedIDs []string
+	q, _ := f.newQuery()
+	err := q.Transaction(func(tx *repository.Query) error {
+		ctx := context.Background()
+		folderDo := tx.Folder.WithContext(ctx)
+		noteDo := tx.Note.WithContext(ctx)
+
+		switch mode {
+		case FolderDeleteCascade:
+			folderIDs, err := descendantFolderIDs(tx, folder.ID)
+			if err != nil {
+				return err
+			}
+			if err := noteDo.Where(tx.Note.FolderID.In(folderIDs...)).Pluck(tx.Note.ID, &trashedIDs); err != nil {
+				return err
+			}
+			// メモはゴミ箱に移動する（復元時はフォルダなしに戻る）
+			trashed, err := noteDo.Where(tx.Note.FolderID.In(folderIDs...)).Delete()
+			if err != nil {
+				return err
+			}
+			if _, err := folderDo.Where(tx.Folder.ID.In(folderIDs...)).Delete(); err != nil {
+				return err
+			}
+			if err := resolveNoteLinksByTarget(tx, trashedIDs); err != nil {
+				return err
+			}
+			slog.Info("folder deleted with contents", "folderID", folder.ID, "folders", len(folderIDs), "trashedNotes", trashed.RowsAffected)
+			return nil
+		case FolderDeleteMoveToParent:
+			_, err := folderDo.Where(tx.Folder.ParentID.Eq(folder.ID)).
+				UpdateSimple(assignParent(tx.Folder.ParentID, folder.ParentID))
+			if err != nil {
+				return err
+			}
+			_, err = noteDo.Where(tx.Note.FolderID.Eq(folder.ID)).
+				UpdateColumnSimple(assignParent(tx.Note.FolderID, folder.ParentID))
+			if err != nil {
+				return err
+			}
+			if _, err := folderDo.Where(tx.Folder.ID.Eq(folder.ID)).Delete(); err != nil {
+				return err
+			}
+			slog.Info("folder deleted", "folderID", folder.ID)
+			return nil
+		default:
+			return errors.New("unknown folder delete mode")
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, noteID := range trashedIDs {
+		publishNoteEvent(noteID, NoteEvent{Type: NoteEventDeleted})
+	}
+	return nil
+}
@@ -0,0 +1,450 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"golang.org/x/net/html"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxImportFiles is the maximum number of notes in a single import.
+	maxImportFiles = 1000
+	// maxImportFileBytes is the largest file that is read from an archive.
+	// The content limit itself is checked by the caller; this only keeps a
+	// single entry from exhausting memory.
+	maxImportFileBytes = 2 << 20
+	// maxImportTotalBytes is the total size of the files read from a ZIP
+	// archive, so that a small archive cannot expand without bound.
+	maxImportTotalBytes = 256 << 20
+)
+
+var (
+	// ErrInvalidImportArchive is returned when an upload is not a readable
+	// ZIP or ENEX file.
+	ErrInvalidImportArchive = errors.New("invalid import archive")
+	// ErrImportTooLarge is returned when an archive has too many files or
+	// expands to too many bytes.
+	ErrImportTooLarge = errors.New("import archive is too large")
+
+	// The errors below are set on a single ImportFile.
+	ErrImportUnsupportedFile    = errors.New("unsupported file type")
+	ErrImportFileTooLarge       = errors.New("file is too large")
+	ErrImportNotUTF8            = errors.New("file is not valid UTF-8")
+	ErrImportInvalidFrontMatter = errors.New("invalid front-matter")
+)
+
+// ImportFile is a note read from an import archive. Err is set when the file
+// cannot be imported; such files are skipped by Import. NoteID is set once
+// the note has been created.
+type ImportFile struct {
+	Name      string
+	Title     string
+	Content   string
+	Format    string
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	Err       error
+	NoteID    string
+}
+
+// importFrontMatter is the part of the front-matter honored on import. The
+// dates are strings so that the common layouts can be accepted.
+type importFrontMatter struct {
+	Title     string `yaml:"title"`
+	CreatedAt string `yaml:"created_at"`
+	UpdatedAt string `yaml:"updated_at"`
+	Format    string `yaml:"format"`
+}
+
+var importDateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+type IImportUsecase interface {
+	ReadZip(r io.ReaderAt, size int64) ([]*ImportFile, error)
+	ReadENEX(r io.Reader) ([]*ImportFile, error)
+	Import(user *domain.User, files []*ImportFile) error
+}
+
+type importUsecase struct {
+	db *gorm.DB
+}
+
+func NewImportUsecase() IImportUsecase {
+	db := sqlite.GetDB()
+	return &importUsecase{
+		db: db,
+	}
+}
+
+// ReadZip reads every .md, .markdown and .txt file of a ZIP archive. Other
+// files are reported as ErrImportUnsupportedFile, except for directories,
+// hidden files and the manifest.json written by the export.
+func (i *importUsecase) ReadZip(r io.ReaderAt, size int64) ([]*ImportFile, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidImportArchive
+	}
+
+	files := []*ImportFile{}
+	var total int64
+	for _, entry := range zr.File {
+		name := entry.Name
+		base := path.Base(name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(base, ".") ||
+			strings.HasPrefix(name, "__MACOSX/") || name == exportManifestName {
+			continue
+		}
+		if len(files) >= maxImportFiles {
+			return nil, ErrImportTooLarge
+		}
+		file := &ImportFile{Name: name}
+		files = append(files, file)
+
+		format := ""
+		switch strings.ToLower(path.Ext(base)) {
+		case ".md", ".markdown":
+			format = NoteFormatMarkdown
+		case ".txt":
+			format = NoteFormatPlain
+		default:
+			file.Err = ErrImportUnsupportedFile
+			continue
+		}
+		if entry.UncompressedSize64 > maxImportFileBytes {
+			file.Err = ErrImportFileTooLarge
+			continue
+		}
+
+		data, err := readZipEntry(entry)
+		if err != nil {
+			file.Err = err
+			continue
+		}
+		total += int64(len(data))
+		if total > maxImportTotalBytes {
+			return nil, ErrImportTooLarge
+		}
+		file.Format = format
+		file.Title = strings.TrimSuffix(base, path.Ext(base))
+		file.Err = parseImportText(file, data)
+	}
+	return files, nil
+}
+
+// readZipEntry reads an entry without trusting the size in its header.
+func readZipEntry(entry *zip.File) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, maxImportFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImportFileBytes {
+		return nil, ErrImportFileTooLarge
+	}
+	return data, nil
+}
+
+// parseImportText fills the note of a text file, taking the title, dates
+// and format from the front-matter if there is one.
+func parseImportText(file *ImportFile, data []byte) error {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return ErrImportNotUTF8
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	front, body, ok := splitFrontMatter(content)
+	if !ok {
+		file.Content = content
+		return nil
+	}
+	var meta importFrontMatter
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return ErrImportInvalidFrontMatter
+	}
+	if title := strings.TrimSpace(meta.Title); title != "" {
+		file.Title = title
+	}
+	var err error
+	if file.CreatedAt, err = parseImportDate(meta.CreatedAt); err != nil {
+		return err
+	}
+	if file.UpdatedAt, err = parseImportDate(meta.UpdatedAt); err != nil {
+		return err
+	}
+	if meta.Format == NoteFormatPlain || meta.Format == NoteFormatMarkdown {
+		file.Format = meta.Format
+	}
+	file.Content = body
+	return nil
+}
+
+// splitFrontMatter separates a leading block delimited by "---" lines.
+func splitFrontMatter(content string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(content, "---\n")
+	if !ok {
+		return "", content, false
+	}
+	if front, ok := strings.CutPrefix(rest, "---\n"); ok {
+		return "", front, true
+	}
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n---") {
+			return "", content, false
+		}
+		return rest[:len(rest)-len("\n---")], "", true
+	}
+	return rest[:end], rest[end+len("\n---\n"):], true
+}
+
+func parseImportDate(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: invalid date %q", ErrImportInvalidFrontMatter, value)
+}
+
+// enexNote is a note of an Evernote export. Attachments (resource elements)
+// are not imported.
+type enexNote struct {
+	Title   string `xml:"title"`
+	Content string `xml:"content"`
+	Created string `xml:"created"`
+	Updated string `xml:"updated"`
+}
+
+const enexDateLayout = "20060102T150405Z"
+
+// ReadENEX reads the notes of an Evernote .enex export. The ENML content is
+// converted to plain text.
+func (i *importUsecase) ReadENEX(r io.Reader) ([]*ImportFile, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Entity = xml.HTMLEntity
+
+	files := []*ImportFile{}
+	foundRoot := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidImportArchive
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "en-export" {
+			foundRoot = true
+			continue
+		}
+		if start.Name.Local != "note" {
+			continue
+		}
+		if len(files) >= maxImportFiles {
+			return nil, ErrImportTooLarge
+		}
+
+		var note enexNote
+		if err := decoder.DecodeElement(&note, &start); err != nil {
+			return nil, ErrInvalidImportArchive
+		}
+		file := &ImportFile{
+			Name:   fmt.Sprintf("%d: %s", len(files)+1, note.Title),
+			Title:  strings.TrimSpace(note.Title),
+			Format: NoteFormatPlain,
+		}
+		files = append(files, file)
+		if len(note.Content) > maxImportFileBytes {
+			file.Err = ErrImportFileTooLarge
+			continue
+		}
+		file.Content, err = enmlToText(note.Content)
+		if err != nil {
+			file.Err = err
+			continue
+		}
+		file.CreatedAt = parseENEXDate(note.Created)
+		file.UpdatedAt = parseENEXDate(note.Updated)
+	}
+	if !foundRoot {
+		return nil, ErrInvalidImportArchive
+	}
+	return files, nil
+}
+
+func parseENEXDate(value string) *time.Time {
+	t, err := time.Parse(enexDateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// enmlBlockElements start on a new line when converted to text.
+var enmlBlockElements = map[string]bool{
+	"div": true, "p": true, "br": true, "li": true, "tr": true, "hr": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "pre": true, "table": true, "ul": true, "ol": true,
+}
+
+// enmlToText converts the XHTML of an ENEX note to plain text. Lists become
+// "- " items and checkboxes become "[ ]" or "[x]".
+func enmlToText(enml string) (string, error) {
+	doc, err := html.Parse(strings.NewReader(enml))
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	newline := func() {
+		s := b.String()
+		if s != "" && !strings.HasSuffix(s, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if enmlBlockElements[n.Data] {
+				newline()
+			}
+			switch n.Data {
+			case "li":
+				b.WriteString("- ")
+			case "en-todo":
+				checked := false
+				for _, attr := range n.Attr {
+					if attr.Key == "checked" && attr.Val == "true" {
+						checked = true
+					}
+				}
+				if checked {
+					b.WriteString("[x] ")
+				} else {
+					b.WriteString("[ ] ")
+				}
+			case "en-media":
+				// 添付ファイルは取り込まないため、あった位置だけを残す
+				b.WriteString("[添付ファイル]")
+			case "script", "style", "head":
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if n.Type == html.ElementNode && enmlBlockElements[n.Data] && n.Data != "br" {
+			newline()
+		}
+	}
+	walk(doc)
+	return strings.TrimSpace(b.String()), nil
+}
+
+// Import creates a note for every file without an error, in a single
+// transaction. Dates missing from a file are set by the database.
+func (i *importUsecase) Import(user *domain.User, files []*ImportFile) error {
+	if user == nil {
+		return errors.New("user cannot be nil")
+	}
+	q := repository.Use(i.db)
+	err := q.Transaction(func(tx *repository.Query) error {
+		do := tx.Note.WithContext(i.db.Statement.Context)
+		titleFormat, err := titleFormatOf(tx, user.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		// 取り込んだメモは手動の並び順の先頭に、後のファイルほど上に置く
+		sortKey := ""
+		var titles []string
+		for _, file := range files {
+			if file.Err != nil {
+				continue
+			}
+			note := &domain.Note{
+				ID:        newUUID(),
+				AuthorID:  user.ID,
+				Title:     file.Title,
+				Content:   file.Content,
+				Format:    file.Format,
+				CreatedAt: file.CreatedAt,
+				UpdatedAt: file.UpdatedAt,
+			}
+			if note.Title == "" {
+				note.Title = expandTitle(titleFormat, user, now)
+			}
+			if note.Format == "" {
+				note.Format = NoteFormatPlain
+			}
+			if note.UpdatedAt == nil {
+				note.UpdatedAt = note.CreatedAt
+			}
+			if sortKey == "" {
+				sortKey, err = topSortKey(tx, user.ID)
+			} else {
+				sortKey, err = sortKeyBetween("", sortKey)
+			}
+			if err != nil {
+				return err
+			}
+			key := sortKey
+			note.SortKey = &key
+			if err := do.Create(note); err != nil {
+				return err
+			}
+			if err := syncNoteLinks(tx, note); err != nil {
+				return err
+			}
+			titles = append(titles, note.Title)
+			file.NoteID = note.ID
+		}
+		// 取り込んだメモ同士のリンクと、既存のメモからのリンクを解決する
+		return resolveNoteLinksByTitle(tx, user.ID, titles)
+	})
+	if err != nil {
+		// ロールバックされたため、作成したことにしない
+		for _, file := range files {
+			file.NoteID = ""
+		}
+		return err
+	}
+	return nil
+}
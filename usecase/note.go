@@ -1,23 +1,106 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log/slog"
-	"sort"
+	"strings"
 	"time"
 
 	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/pubsub"
 	"github.com/ToshihiroOgino/elib/infra/sqlite"
 	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gen/field"
 	"gorm.io/gorm"
 )
 
+// ErrNoteConflict is returned when a note was updated by someone else since
+// the version the caller based its changes on.
+var ErrNoteConflict = errors.New("note was updated by someone else")
+
+// ErrInvalidNotePatch is returned when a patch inserts characters that are not
+// allowed in a note or makes the content too long. A patch that does not fit
+// the stored content fails with ErrTextOpMismatch.
+var ErrInvalidNotePatch = errors.New("invalid note patch")
+
+// ErrInvalidNoteFormat is returned when a note format is not one of the
+// NoteFormat constants.
+var ErrInvalidNoteFormat = errors.New("invalid note format")
+
+// Note formats decide how a note is rendered on the read-only share page.
+const (
+	NoteFormatPlain    = "plain"
+	NoteFormatMarkdown = "markdown"
+)
+
+// validNoteFormat reports whether format is one of the NoteFormat constants.
+func validNoteFormat(format string) bool {
+	return format == NoteFormatPlain || format == NoteFormatMarkdown
+}
+
+// NewNoteFields are the fields of a note given when it is created, such as
+// in an API request. Nil fields get the defaults of CreateNote.
+type NewNoteFields struct {
+	Title   *string
+	Content *string
+	Format  *string
+}
+
+// ErrNoteInTrash is returned by Find when the note has been moved to the trash.
+var ErrNoteInTrash = errors.New("note is in the trash")
+
+// Note event types. NoteEventAccessChanged is published when a share link or
+// a member of the note is changed or removed, so that open connections check
+// whether they may still access the note.
+const (
+	NoteEventUpdated       = "updated"
+	NoteEventDeleted       = "deleted"
+	NoteEventAccessChanged = "access_changed"
+)
+
+// NoteEvent is published after a change to a note is committed. Note holds
+// the stored note after an update and is nil for the other events.
+// Subscribers share the value and must not modify it.
+type NoteEvent struct {
+	Type string
+	Note *domain.Note
+}
+
+// noteEvents is shared by every usecase that changes notes.
+var noteEvents = pubsub.NewHub[NoteEvent]()
+
+func publishNoteEvent(noteID string, event NoteEvent) {
+	noteEvents.Publish("note:"+noteID, event)
+}
+
 type INoteUsecase interface {
-	CreateNote(user *domain.User) (*domain.Note, error)
-	UpdateNote(note *domain.Note) (*domain.Note, error)
+	CreateNote(user *domain.User, folder *domain.Folder) (*domain.Note, error)
+	CreateNoteWithFields(user *domain.User, fields NewNoteFields, folder *domain.Folder) (*domain.Note, error)
+	CreateNoteFromTemplate(user *domain.User, template *domain.NoteTemplate, folder *domain.Folder, maxContentBytes int) (*domain.Note, error)
+	UpdateNote(note *domain.Note, editor NoteEditor) (*domain.Note, error)
+	UpdateNoteWithFormat(note *domain.Note, format string, editor NoteEditor) (*domain.Note, error)
+	UpdateNoteByEditors(note *domain.Note, editor NoteEditor, coEditors []NoteEditor) (*domain.Note, error)
+	PatchNote(note *domain.Note, patch TextOp, maxContentBytes int, editor NoteEditor) (*domain.Note, error)
 	Find(noteId string) (*domain.Note, error)
-	FindNotesByUserID(userID string) ([]*domain.Note, error)
+	ListNotes(userID string, opts NoteListOptions) (*NoteListPage, error)
+	FindLinks(note *domain.Note) ([]*NoteLink, error)
+	FindBacklinks(note *domain.Note) ([]*Backlink, error)
+	FindBrokenLinks(userID string) ([]*BrokenLink, error)
+	MoveToFolder(note *domain.Note, folder *domain.Folder) error
+	SetPinned(note *domain.Note, pinned bool) error
+	SetFavorite(note *domain.Note, favorite bool) error
+	MoveNote(note *domain.Note, afterID string, beforeID string) error
+	SetFormat(note *domain.Note, format string) error
+	Search(userID string, query string, limit int, offset int) ([]*NoteSearchResult, error)
 	Delete(note *domain.Note) error
+	FindTrashed(noteId string) (*domain.Note, error)
+	FindTrashByUserID(userID string) ([]*domain.Note, error)
+	Restore(note *domain.Note) error
+	DeletePermanently(note *domain.Note) error
+	EmptyTrash(userID string) (int64, error)
+	PurgeTrash(before time.Time) (int64, error)
+	Subscribe(noteID string) (<-chan NoteEvent, func())
 }
 
 type noteUsecase struct {
@@ -37,65 +120,348 @@ func (n *noteUsecase) newQuery() (*repository.Query, repository.INoteDo) {
 	return q, do
 }
 
-func defaultTitle() string {
-	timestamp := time.Now().Format("20060102150405")
-	title := fmt.Sprintf("Untitled_%s", timestamp)
-	return title
+// CreateNote creates an empty note titled by the title format of user in
+// folder, or at the top level when folder is nil.
+func (n *noteUsecase) CreateNote(user *domain.User, folder *domain.Folder) (*domain.Note, error) {
+	return n.CreateNoteWithFields(user, NewNoteFields{}, folder)
 }
 
-func (n *noteUsecase) CreateNote(user *domain.User) (*domain.Note, error) {
+// CreateNoteWithFields creates a note like CreateNote with the given fields
+// set in the same insert, so that no empty note is left behind if they
+// cannot be saved.
+func (n *noteUsecase) CreateNoteWithFields(user *domain.User, fields NewNoteFields, folder *domain.Folder) (*domain.Note, error) {
+	if fields.Format != nil && !validNoteFormat(*fields.Format) {
+		return nil, ErrInvalidNoteFormat
+	}
 	note := &domain.Note{
 		ID:       newUUID(),
-		Title:    defaultTitle(),
-		Content:  "",
 		AuthorID: user.ID,
 	}
-	_, do := n.newQuery()
-	if err := do.Create(note); err != nil {
+	if fields.Title != nil {
+		note.Title = *fields.Title
+	} else {
+		q, _ := n.newQuery()
+		format, err := titleFormatOf(q, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		note.Title = expandTitle(format, user, time.Now())
+	}
+	if fields.Content != nil {
+		note.Content = *fields.Content
+	}
+	if fields.Format != nil {
+		note.Format = *fields.Format
+	}
+	if err := n.create(note, folder); err != nil {
 		slog.Error("failed to create note", "error", err)
 		return nil, err
 	}
 	return note, nil
 }
 
-func (n *noteUsecase) UpdateNote(note *domain.Note) (*domain.Note, error) {
-	_, do := n.newQuery()
-	if err := do.Save(note); err != nil {
+// create inserts note into folder at the top of the manual order of its
+// author.
+func (n *noteUsecase) create(note *domain.Note, folder *domain.Folder) error {
+	if folder != nil {
+		if folder.UserID != note.AuthorID {
+			return errors.New("cannot create note in another user's folder")
+		}
+		note.FolderID = &folder.ID
+	}
+	note.Title = stripControlChars(note.Title)
+	note.Content = stripControlChars(note.Content)
+	q, _ := n.newQuery()
+	return q.Transaction(func(tx *repository.Query) error {
+		key, err := topSortKey(tx, note.AuthorID)
+		if err != nil {
+			return err
+		}
+		note.SortKey = &key
+		if err := tx.Note.WithContext(n.db.Statement.Context).Create(note); err != nil {
+			return err
+		}
+		if err := syncNoteLinks(tx, note); err != nil {
+			return err
+		}
+		return resolveNoteLinksByTitle(tx, note.AuthorID, []string{note.Title})
+	})
+}
+
+// CreateNoteFromTemplate creates a note of user with the title, content and
+// format of template in folder, expanding the placeholders in the title and
+// content. A template without a title gets the title format of user. If the
+// expanded content is longer than maxContentBytes, it fails with
+// ErrTemplateTooLarge.
+func (n *noteUsecase) CreateNoteFromTemplate(user *domain.User, template *domain.NoteTemplate, folder *domain.Folder, maxContentBytes int) (*domain.Note, error) {
+	if template == nil {
+		return nil, errors.New("template cannot be nil")
+	}
+	q, _ := n.newQuery()
+	titleFormat := template.Title
+	if strings.TrimSpace(titleFormat) == "" {
+		var err error
+		if titleFormat, err = titleFormatOf(q, user.ID); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now()
+	// 変数の展開で本文が伸びるため、展開後の長さで制限する
+	content := expandPlaceholders(template.Content, user, now)
+	if len(content) > maxContentBytes {
+		return nil, ErrTemplateTooLarge
+	}
+	note := &domain.Note{
+		ID:       newUUID(),
+		Title:    expandTitle(titleFormat, user, now),
+		Content:  content,
+		Format:   template.Format,
+		AuthorID: user.ID,
+	}
+	if err := n.create(note, folder); err != nil {
+		slog.Error("failed to create note from template", "templateId", template.ID, "error", err)
 		return nil, err
 	}
 	return note, nil
 }
 
+// UpdateNote saves the title and content of note if its Version still matches
+// the stored one, and returns the note with the incremented version. When the
+// title changes, the links to the note in other notes are renamed as well.
+func (n *noteUsecase) UpdateNote(note *domain.Note, editor NoteEditor) (*domain.Note, error) {
+	return n.UpdateNoteByEditors(note, editor, nil)
+}
+
+// UpdateNoteWithFormat saves note like UpdateNote and changes its format in
+// the same update. An empty format keeps the stored one.
+func (n *noteUsecase) UpdateNoteWithFormat(note *domain.Note, format string, editor NoteEditor) (*domain.Note, error) {
+	if format != "" && !validNoteFormat(format) {
+		return nil, ErrInvalidNoteFormat
+	}
+	return n.update(note, format, editor, nil, func(*domain.Note) (string, error) {
+		return note.Content, nil
+	})
+}
+
+// UpdateNoteByEditors saves note like UpdateNote for changes of several
+// editors, such as the edits collected in a collaborative editing session.
+// editor made the last change and coEditors the others; the revision credits
+// all of them.
+func (n *noteUsecase) UpdateNoteByEditors(note *domain.Note, editor NoteEditor, coEditors []NoteEditor) (*domain.Note, error) {
+	return n.update(note, "", editor, coEditors, func(*domain.Note) (string, error) {
+		return note.Content, nil
+	})
+}
+
+// PatchNote saves note like UpdateNote, except that its content is given as
+// patch against the stored content of note.Version, so that a client only
+// sends what it changed. The patched content must be at most maxContentBytes
+// long.
+func (n *noteUsecase) PatchNote(note *domain.Note, patch TextOp, maxContentBytes int, editor NoteEditor) (*domain.Note, error) {
+	if !validTextOp(patch) {
+		return nil, ErrInvalidNotePatch
+	}
+	return n.update(note, "", editor, nil, func(stored *domain.Note) (string, error) {
+		// 差分は基準の版の本文に対するものなので、古い版への差分は適用しない
+		if stored.Version != note.Version {
+			return "", ErrNoteConflict
+		}
+		content, err := patch.Apply([]rune(stored.Content))
+		if err != nil {
+			return "", err
+		}
+		patched := string(content)
+		if len(patched) > maxContentBytes {
+			return "", ErrInvalidNotePatch
+		}
+		return patched, nil
+	})
+}
+
+// update saves note with the content returned by contentOf for the stored
+// note. A non-empty format is set in the same update.
+func (n *noteUsecase) update(note *domain.Note, format string, editor NoteEditor, coEditors []NoteEditor, contentOf func(stored *domain.Note) (string, error)) (*domain.Note, error) {
+	q, _ := n.newQuery()
+	var updated *domain.Note
+	var relinked []*domain.Note
+	err := q.Transaction(func(tx *repository.Query) error {
+		do := tx.Note.WithContext(n.db.Statement.Context)
+		stored, err := do.Where(tx.Note.ID.Eq(note.ID)).First()
+		if err != nil {
+			return err
+		}
+		content, err := contentOf(stored)
+		if err != nil {
+			return err
+		}
+		if err := ensureBaselineRevision(tx, stored); err != nil {
+			return err
+		}
+		var assigns []field.AssignExpr
+		if format != "" {
+			assigns = append(assigns, tx.Note.Format.Value(format))
+		}
+		updated, err = updateNoteContent(tx, note.ID, note.Version, note.Title, content, assigns...)
+		if err != nil {
+			return err
+		}
+		if err := recordRevision(tx, updated, editor, "", coEditors); err != nil {
+			return err
+		}
+		relinked, err = updateNoteLinks(tx, stored, updated, editor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	publishNoteEvent(updated.ID, NoteEvent{Type: NoteEventUpdated, Note: updated})
+	for _, source := range relinked {
+		publishNoteEvent(source.ID, NoteEvent{Type: NoteEventUpdated, Note: source})
+	}
+	return updated, nil
+}
+
+// updateNoteContent writes title and content only when the stored version
+// equals baseVersion. The check and the increment happen in a single UPDATE.
+// Control characters are removed, since search uses them as match markers.
+// assigns are written in the same UPDATE.
+func updateNoteContent(tx *repository.Query, noteID string, baseVersion int32, title, content string, assigns ...field.AssignExpr) (*domain.Note, error) {
+	n := tx.Note
+	do := n.WithContext(context.Background())
+	assigns = append([]field.AssignExpr{
+		n.Title.Value(stripControlChars(title)),
+		n.Content.Value(stripControlChars(content)),
+		n.Version.Add(1),
+	}, assigns...)
+	res, err := do.Where(n.ID.Eq(noteID), n.Version.Eq(baseVersion)).UpdateSimple(assigns...)
+	if err != nil {
+		return nil, err
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrNoteConflict
+	}
+	return do.Where(n.ID.Eq(noteID)).First()
+}
+
+// Find returns the note, or ErrNoteInTrash if it has been deleted.
 func (n *noteUsecase) Find(noteId string) (*domain.Note, error) {
 	q, do := n.newQuery()
-	note, err := do.Where(q.Note.ID.Eq(noteId)).First()
+	note, err := do.Unscoped().Where(q.Note.ID.Eq(noteId)).First()
 	if err != nil {
 		return nil, err
 	}
+	if note.DeletedAt.Valid {
+		return nil, ErrNoteInTrash
+	}
 	return note, nil
 }
 
-func (n *noteUsecase) FindNotesByUserID(userID string) ([]*domain.Note, error) {
+// MoveToFolder places note in folder, or at the top level when folder is nil.
+// Moving does not change the note's updated_at.
+func (n *noteUsecase) MoveToFolder(note *domain.Note, folder *domain.Folder) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
 	q, do := n.newQuery()
-	notes, err := do.Where(q.Note.AuthorID.Eq(userID)).Find()
-	if err != nil {
-		return nil, err
+	assign := q.Note.FolderID.Null()
+	if folder != nil {
+		if folder.UserID != note.AuthorID {
+			return errors.New("cannot move note into another user's folder")
+		}
+		assign = q.Note.FolderID.Value(folder.ID)
 	}
-	sort.Slice(notes, func(i, j int) bool {
-		return notes[i].UpdatedAt.After(*notes[j].UpdatedAt)
-	})
-	return notes, nil
+	_, err := do.Where(q.Note.ID.Eq(note.ID)).UpdateColumnSimple(assign)
+	return err
 }
 
-func (n *noteUsecase) Delete(note *domain.Note) error {
+// SetFormat changes how note is rendered. Like moving, it does not change
+// the note's updated_at or version.
+func (n *noteUsecase) SetFormat(note *domain.Note, format string) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	if !validNoteFormat(format) {
+		return ErrInvalidNoteFormat
+	}
 	q, do := n.newQuery()
-	res, err := do.Where(q.Note.ID.Eq(note.ID)).Delete()
-	if err != nil {
+	if _, err := do.Where(q.Note.ID.Eq(note.ID)).UpdateColumnSimple(q.Note.Format.Value(format)); err != nil {
 		return err
 	}
-	if res.Error != nil {
-		return res.Error
+	note.Format = format
+	published := *note
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventUpdated, Note: &published})
+	return nil
+}
+
+// Delete moves note to the trash. It can be restored until it is purged.
+func (n *noteUsecase) Delete(note *domain.Note) error {
+	q, _ := n.newQuery()
+	var rowsAffected int64
+	err := q.Transaction(func(tx *repository.Query) error {
+		res, err := tx.Note.WithContext(n.db.Statement.Context).Where(tx.Note.ID.Eq(note.ID)).Delete()
+		if err != nil {
+			return err
+		}
+		rowsAffected = res.RowsAffected
+		return resolveNoteLinksByTarget(tx, []string{note.ID})
+	})
+	if err != nil {
+		return err
 	}
-	slog.Info("Note deleted successfully", "noteID", note.ID, "rowsAffected", res.RowsAffected)
+	slog.Info("Note moved to trash", "noteID", note.ID, "rowsAffected", rowsAffected)
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventDeleted})
 	return nil
 }
+
+// Subscribe returns the events of a note and a function that ends the
+// subscription. Events are dropped while the subscriber is not reading.
+func (n *noteUsecase) Subscribe(noteID string) (<-chan NoteEvent, func()) {
+	return noteEvents.Subscribe("note:" + noteID)
+}
+
+// deleteNotes removes the notes with the given IDs together with the rows
+// that refer to them. It returns the number of deleted notes and the blob keys
+// of their attachments, which the caller collects after committing.
+func deleteNotes(tx *repository.Query, noteIDs []string) (int64, []string, error) {
+	if len(noteIDs) == 0 {
+		return 0, nil, nil
+	}
+	ctx := context.Background()
+	blobKeys, err := attachmentBlobKeys(tx, noteIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+	// 外部キー制約は有効化していないため、関連データを明示的に削除する
+	if _, err := tx.Attachment.WithContext(ctx).Where(tx.Attachment.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.NoteTag.WithContext(ctx).Where(tx.NoteTag.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.NoteRevisionEditor.WithContext(ctx).Where(tx.NoteRevisionEditor.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.NoteRevision.WithContext(ctx).Where(tx.NoteRevision.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.SharingInfo.WithContext(ctx).Where(tx.SharingInfo.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.NoteLink.WithContext(ctx).Where(tx.NoteLink.SourceID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.NoteMember.WithContext(ctx).Where(tx.NoteMember.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	if _, err := tx.ShareAccessLog.WithContext(ctx).Where(tx.ShareAccessLog.NoteID.In(noteIDs...)).Delete(); err != nil {
+		return 0, nil, err
+	}
+	res, err := tx.Note.WithContext(ctx).Unscoped().Where(tx.Note.ID.In(noteIDs...)).Delete()
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := resolveNoteLinksByTarget(tx, noteIDs); err != nil {
+		return 0, nil, err
+	}
+	return res.RowsAffected, blobKeys, nil
+}
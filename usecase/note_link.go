@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/repository"
+)
+
+const (
+	// maxNoteLinks bounds how many distinct links of a note are indexed.
+	maxNoteLinks = 1000
+	// maxNoteLinkTitleBytes is the title limit of the save endpoints. Longer
+	// titles cannot name a note.
+	maxNoteLinkTitleBytes = 500
+)
+
+// noteLinkPattern matches [[Title]] and [[Title|label]].
+var noteLinkPattern = regexp.MustCompile(`\[\[([^\[\]|\n]+)(\|[^\[\]\n]*)?\]\]`)
+
+// resolveNoteLinksSQL points links at the oldest note of the source's author
+// with the linked title, ignoring notes in the trash, or at nothing.
+const resolveNoteLinksSQL = `UPDATE note_links SET target_id = (
+	SELECT n.id FROM notes n JOIN notes s ON s.id = note_links.source_id
+	WHERE n.author_id = s.author_id AND n.title = note_links.target_title AND n.deleted_at IS NULL
+	ORDER BY n.created_at, n.id LIMIT 1
+) WHERE `
+
+// NoteLink is a [[Title]] link in a note. NoteID is empty if no note has the
+// title.
+type NoteLink struct {
+	Title  string `json:"title"`
+	NoteID string `json:"note_id,omitempty"`
+}
+
+// Backlink is a note that links to another note.
+type Backlink struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// BrokenLink is a link to a title that no note of the user has.
+type BrokenLink struct {
+	SourceID    string `json:"source_id"`
+	SourceTitle string `json:"source_title"`
+	Title       string `json:"title"`
+}
+
+// parseNoteLinks returns the distinct titles linked from content in order.
+func parseNoteLinks(content string) []string {
+	var titles []string
+	seen := map[string]bool{}
+	for _, match := range noteLinkPattern.FindAllStringSubmatch(content, -1) {
+		title := strings.TrimSpace(match[1])
+		if title == "" || len(title) > maxNoteLinkTitleBytes || seen[title] {
+			continue
+		}
+		seen[title] = true
+		titles = append(titles, title)
+		if len(titles) == maxNoteLinks {
+			break
+		}
+	}
+	return titles
+}
+
+// renameNoteLinks rewrites the links to oldTitle in content to newTitle,
+// keeping their labels.
+func renameNoteLinks(content, oldTitle, newTitle string) (string, bool) {
+	changed := false
+	renamed := noteLinkPattern.ReplaceAllStringFunc(content, func(link string) string {
+		match := noteLinkPattern.FindStringSubmatch(link)
+		if strings.TrimSpace(match[1]) != oldTitle {
+			return link
+		}
+		changed = true
+		return "[[" + newTitle + match[2] + "]]"
+	})
+	return renamed, changed
+}
+
+func execNoteLinks(tx *repository.Query, sql string, args ...interface{}) error {
+	return tx.NoteLink.WithContext(context.Background()).UnderlyingDB().Exec(sql, args...).Error
+}
+
+// resolveNoteLinksByTitle resolves the links of the notes of authorID to any
+// of titles, after notes with those titles were created, renamed, trashed or
+// restored.
+func resolveNoteLinksByTitle(tx *repository.Query, authorID string, titles []string) error {
+	if len(titles) == 0 {
+		return nil
+	}
+	return execNoteLinks(tx, resolveNoteLinksSQL+
+		`target_title IN ? AND source_id IN (SELECT id FROM notes WHERE author_id = ?)`, titles, authorID)
+}
+
+// resolveNoteLinksByTarget resolves the links that point at noteIDs again,
+// after those notes were moved to the trash or deleted.
+func resolveNoteLinksByTarget(tx *repository.Query, noteIDs []string) error {
+	if len(noteIDs) == 0 {
+		return nil
+	}
+	return execNoteLinks(tx, resolveNoteLinksSQL+`target_id IN ?`, noteIDs)
+}
+
+// syncNoteLinks replaces the indexed links of note with the links in its
+// content.
+func syncNoteLinks(tx *repository.Query, note *domain.Note) error {
+	l := tx.NoteLink
+	do := l.WithContext(context.Background())
+	if _, err := do.Where(l.SourceID.Eq(note.ID)).Delete(); err != nil {
+		return err
+	}
+	titles := parseNoteLinks(note.Content)
+	if len(titles) == 0 {
+		return nil
+	}
+	links := make([]*domain.NoteLink, 0, len(titles))
+	for _, title := range titles {
+		links = append(links, &domain.NoteLink{SourceID: note.ID, TargetTitle: title})
+	}
+	if err := do.CreateInBatches(links, 100); err != nil {
+		return err
+	}
+	return execNoteLinks(tx, resolveNoteLinksSQL+`source_id = ?`, note.ID)
+}
+
+// updateNoteLinks indexes the links of a note saved with new content. If the
+// title changed, the links to the note in the other notes of its author are
+// rewritten to the new title; those notes are saved as edits by editor and
+// returned, so that their events can be published after the commit.
+func updateNoteLinks(tx *repository.Query, stored *domain.Note, updated *domain.Note, editor NoteEditor) ([]*domain.Note, error) {
+	if err := syncNoteLinks(tx, updated); err != nil {
+		return nil, err
+	}
+	if stored.Title == updated.Title {
+		return nil, nil
+	}
+
+	n, l := tx.Note, tx.NoteLink
+	sources, err := n.WithContext(context.Background()).
+		Join(l, l.SourceID.EqCol(n.ID)).
+		Where(l.TargetID.Eq(updated.ID), n.ID.Neq(updated.ID)).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	var rewritten []*domain.Note
+	for _, source := range sources {
+		content, changed := renameNoteLinks(source.Content, stored.Title, updated.Title)
+		if !changed {
+			continue
+		}
+		if err := ensureBaselineRevision(tx, source); err != nil {
+			return nil, err
+		}
+		saved, err := updateNoteContent(tx, source.ID, source.Version, source.Title, content)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordRevision(tx, saved, editor, "", nil); err != nil {
+			return nil, err
+		}
+		if err := syncNoteLinks(tx, saved); err != nil {
+			return nil, err
+		}
+		rewritten = append(rewritten, saved)
+	}
+	if err := resolveNoteLinksByTitle(tx, updated.AuthorID, []string{stored.Title, updated.Title}); err != nil {
+		return nil, err
+	}
+	return rewritten, nil
+}
+
+// FindLinks returns the links in note by title.
+func (n *noteUsecase) FindLinks(note *domain.Note) ([]*NoteLink, error) {
+	q := repository.Use(n.db)
+	l := q.NoteLink
+	rows, err := l.WithContext(n.db.Statement.Context).
+		Where(l.SourceID.Eq(note.ID)).
+		Order(l.TargetTitle).
+		Find()
+	if err != nil {
+		return nil, err
+	}
+	links := make([]*NoteLink, 0, len(rows))
+	for _, row := range rows {
+		link := &NoteLink{Title: row.TargetTitle}
+		if row.TargetID != nil {
+			link.NoteID = *row.TargetID
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// FindBacklinks returns the notes outside the trash that link to note, most
+// recently updated first.
+func (n *noteUsecase) FindBacklinks(note *domain.Note) ([]*Backlink, error) {
+	q, do := n.newQuery()
+	backlinks := []*Backlink{}
+	err := do.Select(q.Note.ID, q.Note.Title, q.Note.UpdatedAt).
+		Join(q.NoteLink, q.NoteLink.SourceID.EqCol(q.Note.ID)).
+		Where(q.NoteLink.TargetID.Eq(note.ID), q.Note.ID.Neq(note.ID)).
+		Order(q.Note.UpdatedAt.Desc(), q.Note.ID).
+		Scan(&backlinks)
+	return backlinks, err
+}
+
+// FindBrokenLinks returns the links in the notes of userID outside the trash
+// that do not lead to a note.
+func (n *noteUsecase) FindBrokenLinks(userID string) ([]*BrokenLink, error) {
+	q, do := n.newQuery()
+	broken := []*BrokenLink{}
+	err := do.Select(q.Note.ID.As("source_id"), q.Note.Title.As("source_title"), q.NoteLink.TargetTitle.As("title")).
+		Join(q.NoteLink, q.NoteLink.SourceID.EqCol(q.Note.ID)).
+		Where(q.Note.AuthorID.Eq(userID), q.NoteLink.TargetID.IsNull()).
+		Order(q.Note.Title, q.NoteLink.TargetTitle).
+		Scan(&broken)
+	return broken, err
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Sort orders of the note list.
+const (
+	NoteSortUpdated = "updated"
+	NoteSortCreated = "created"
+	NoteSortTitle   = "title"
+	// NoteSortManual is the order the user arranged by drag and drop.
+	NoteSortManual = "manual"
+)
+
+const (
+	defaultNoteListLimit = 50
+	maxNoteListLimit     = 100
+
+	// noteSnippetLength is the number of characters of content shown under
+	// the title in the note list.
+	noteSnippetLength = 80
+)
+
+// ErrInvalidNoteSort is returned when a sort order is not one of the NoteSort
+// constants.
+var ErrInvalidNoteSort = errors.New("invalid note sort order")
+
+// ErrInvalidNoteCursor is returned when a cursor was not issued by ListNotes
+// or was issued for a different sort order.
+var ErrInvalidNoteCursor = errors.New("invalid note list cursor")
+
+// NoteSummary is a note in the list without its content.
+type NoteSummary struct {
+	ID        string     `json:"id"`
+	Title     string     `json:"title"`
+	Snippet   string     `json:"snippet"`
+	Format    string     `json:"format"`
+	FolderID  *string    `json:"folder_id"`
+	Version   int32      `json:"version"`
+	Pinned    bool       `json:"pinned"`
+	Favorite  bool       `json:"favorite"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// NoteListOptions selects a page of the note list. TagID and FolderID narrow
+// the list to the notes with the tag or directly in the folder, and Favorite
+// to the favorites. Cursor is the NextCursor of the previous page, or empty
+// for the first page.
+type NoteListOptions struct {
+	TagID    string
+	FolderID string
+	Favorite bool
+	Sort     string
+	Cursor   string
+	Limit    int
+}
+
+// NoteListPage is a page of the note list. NextCursor is empty on the last
+// page.
+type NoteListPage struct {
+	Notes      []*NoteSummary
+	NextCursor string
+}
+
+// noteCursor is the position after the last note of a page. Value holds the
+// sort column as stored, so that it compares exactly like the column does.
+type noteCursor struct {
+	Sort   string `json:"s"`
+	Pinned bool   `json:"p,omitempty"`
+	Value  string `json:"v"`
+	ID     string `json:"i"`
+}
+
+func encodeNoteCursor(cursor noteCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeNoteCursor(s string, sort string) (*noteCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidNoteCursor
+	}
+	var cursor noteCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Sort != sort || cursor.ID == "" {
+		return nil, ErrInvalidNoteCursor
+	}
+	return &cursor, nil
+}
+
+// noteSortColumn returns the column a sort order uses and whether it is
+// descending. Ties are broken by id in ascending order.
+func noteSortColumn(sort string) (string, bool, error) {
+	switch sort {
+	case "", NoteSortUpdated:
+		return "n.updated_at", true, nil
+	case NoteSortCreated:
+		return "n.created_at", true, nil
+	case NoteSortTitle:
+		return "n.title", false, nil
+	case NoteSortManual:
+		return "n.sort_key", false, nil
+	}
+	return "", false, ErrInvalidNoteSort
+}
+
+type noteSummaryRow struct {
+	ID        string
+	Title     string
+	Snippet   string
+	Format    string
+	FolderID  *string
+	Version   int32
+	Pinned    bool
+	Favorite  bool
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+	SortValue string
+}
+
+// ListNotes returns a page of the notes of userID. Pinned notes come first
+// in every sort order. The list is ordered and paged in SQL with a keyset
+// cursor, and only the start of each content is read.
+func (n *noteUsecase) ListNotes(userID string, opts NoteListOptions) (*NoteListPage, error) {
+	if opts.Sort == "" {
+		opts.Sort = NoteSortUpdated
+	}
+	column, desc, err := noteSortColumn(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = defaultNoteListLimit
+	}
+	if limit > maxNoteListLimit {
+		limit = maxNoteListLimit
+	}
+
+	args := []interface{}{noteSnippetLength}
+	var sql strings.Builder
+	// CAST で保存されている文字列のまま取り出し、カーソルの比較に使う
+	sql.WriteString(`SELECT n.id, n.title, n.format, n.folder_id, n.version, n.pinned, n.favorite, n.created_at, n.updated_at,
+	substr(n.content, 1, ?) AS snippet,
+	CAST(` + column + ` AS TEXT) AS sort_value
+FROM notes n`)
+	if opts.TagID != "" {
+		sql.WriteString(` JOIN note_tags nt ON nt.note_id = n.id AND nt.tag_id = ?`)
+		args = append(args, opts.TagID)
+	}
+	sql.WriteString(` WHERE n.author_id = ? AND n.deleted_at IS NULL`)
+	args = append(args, userID)
+	if opts.FolderID != "" {
+		sql.WriteString(` AND n.folder_id = ?`)
+		args = append(args, opts.FolderID)
+	}
+	if opts.Favorite {
+		sql.WriteString(` AND n.favorite`)
+	}
+	if opts.Cursor != "" {
+		cursor, err := decodeNoteCursor(opts.Cursor, opts.Sort)
+		if err != nil {
+			return nil, err
+		}
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		// ピン留めしたメモが先に並ぶため、ピン留めの有無もカーソルと比べる
+		sql.WriteString(` AND (n.pinned < ? OR (n.pinned = ? AND (` + column + ` ` + op + ` ? OR (` + column + ` = ? AND n.id > ?))))`)
+		args = append(args, cursor.Pinned, cursor.Pinned, cursor.Value, cursor.Value, cursor.ID)
+	}
+	order := " ASC"
+	if desc {
+		order = " DESC"
+	}
+	sql.WriteString(` ORDER BY n.pinned DESC, ` + column + order + `, n.id LIMIT ?`)
+	// 次のページがあるかを知るため1件多く取得する
+	args = append(args, limit+1)
+
+	var rows []noteSummaryRow
+	if err := n.db.Raw(sql.String(), args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &NoteListPage{Notes: make([]*NoteSummary, 0, min(len(rows), limit))}
+	if len(rows) > limit {
+		last := rows[limit-1]
+		page.NextCursor = encodeNoteCursor(noteCursor{Sort: opts.Sort, Pinned: last.Pinned, Value: last.SortValue, ID: last.ID})
+		rows = rows[:limit]
+	}
+	for _, row := range rows {
+		page.Notes = append(page.Notes, &NoteSummary{
+			ID:        row.ID,
+			Title:     row.Title,
+			Snippet:   strings.Join(strings.Fields(row.Snippet), " "),
+			Format:    row.Format,
+			FolderID:  row.FolderID,
+			Version:   row.Version,
+			Pinned:    row.Pinned,
+			Favorite:  row.Favorite,
+			CreatedAt: row.CreatedAt,
+			UpdatedAt: row.UpdatedAt,
+		})
+	}
+	return page, nil
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Roles of the users a note is shared with. NoteRoleOwner is never stored;
+// it is what NoteRole returns for the author of the note.
+const (
+	NoteRoleOwner     = "owner"
+	NoteRoleViewer    = "viewer"
+	NoteRoleCommenter = "commenter"
+	NoteRoleEditor    = "editor"
+)
+
+var (
+	ErrInvalidNoteRole = errors.New("invalid note role")
+	// ErrMemberUserNotFound is returned when a note is shared with an email
+	// address that has no account.
+	ErrMemberUserNotFound = errors.New("user not found")
+	// ErrInvalidNoteMember is returned when the owner tries to share a note
+	// with themselves.
+	ErrInvalidNoteMember = errors.New("invalid note member")
+)
+
+// NoteMemberItem is a user a note is shared with.
+type NoteMemberItem struct {
+	UserID    string     `json:"user_id"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+// SharedNoteSummary is a note another user has shared with the current user.
+type SharedNoteSummary struct {
+	ID         string     `json:"id"`
+	Title      string     `json:"title"`
+	OwnerEmail string     `json:"owner_email"`
+	Role       string     `json:"role"`
+	UpdatedAt  *time.Time `json:"updated_at"`
+}
+
+// CanEditNote reports whether role may change the content of a note.
+func CanEditNote(role string) bool {
+	return role == NoteRoleOwner || role == NoteRoleEditor
+}
+
+// validNoteRole reports whether role can be given to a member.
+func validNoteRole(role string) bool {
+	return role == NoteRoleViewer || role == NoteRoleCommenter || role == NoteRoleEditor
+}
+
+type INoteMemberUsecase interface {
+	NoteRole(note *domain.Note, user *domain.User) (string, error)
+	FindByNote(note *domain.Note) ([]*NoteMemberItem, error)
+	FindSharedWithUser(userID string) ([]*SharedNoteSummary, error)
+	Share(note *domain.Note, email string, role string) (*NoteMemberItem, error)
+	SetRole(note *domain.Note, userID string, role string) error
+	Remove(note *domain.Note, userID string) error
+}
+
+type noteMemberUsecase struct {
+	db *gorm.DB
+}
+
+func NewNoteMemberUsecase() INoteMemberUsecase {
+	db := sqlite.GetDB()
+	return &noteMemberUsecase{
+		db: db,
+	}
+}
+
+func (m *noteMemberUsecase) newQuery() (*repository.Query, repository.INoteMemberDo) {
+	q := repository.Use(m.db)
+	do := q.NoteMember.WithContext(m.db.Statement.Context)
+	return q, do
+}
+
+// NoteRole returns what user may do with note: NoteRoleOwner for its author,
+// the role it is shared with, or "" if the user has no access.
+func (m *noteMemberUsecase) NoteRole(note *domain.Note, user *domain.User) (string, error) {
+	if note == nil || user == nil {
+		return "", errors.New("note and user cannot be nil")
+	}
+	if note.AuthorID == user.ID {
+		return NoteRoleOwner, nil
+	}
+	q, do := m.newQuery()
+	member, err := do.Where(q.NoteMember.NoteID.Eq(note.ID), q.NoteMember.UserID.Eq(user.ID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+func (m *noteMemberUsecase) FindByNote(note *domain.Note) ([]*NoteMemberItem, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := m.newQuery()
+	var members []*NoteMemberItem
+	err := do.Select(q.NoteMember.UserID, q.User.Email, q.NoteMember.Role, q.NoteMember.CreatedAt).
+		Join(q.User, q.User.ID.EqCol(q.NoteMember.UserID)).
+		Where(q.NoteMember.NoteID.Eq(note.ID)).
+		Order(q.User.Email).
+		Scan(&members)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// FindSharedWithUser returns the notes other users have shared with the
+// user, most recently updated first. Notes in the trash are left out.
+func (m *noteMemberUsecase) FindSharedWithUser(userID string) ([]*SharedNoteSummary, error) {
+	q, do := m.newQuery()
+	var notes []*SharedNoteSummary
+	err := do.Select(q.Note.ID, q.Note.Title, q.User.Email.As("owner_email"), q.NoteMember.Role, q.Note.UpdatedAt).
+		Join(q.Note, q.Note.ID.EqCol(q.NoteMember.NoteID), q.Note.DeletedAt.IsNull()).
+		Join(q.User, q.User.ID.EqCol(q.Note.AuthorID)).
+		Where(q.NoteMember.UserID.Eq(userID)).
+		Order(q.Note.UpdatedAt.Desc(), q.Note.ID).
+		Scan(&notes)
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Share gives the user with the email address role on note. If the note is
+// already shared with the user, their role is changed.
+func (m *noteMemberUsecase) Share(note *domain.Note, email string, role string) (*NoteMemberItem, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	if !validNoteRole(role) {
+		return nil, ErrInvalidNoteRole
+	}
+	q, do := m.newQuery()
+	user, err := q.User.WithContext(context.Background()).Where(q.User.Email.Eq(strings.TrimSpace(email))).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMemberUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == note.AuthorID {
+		return nil, ErrInvalidNoteMember
+	}
+
+	now := time.Now()
+	member := &domain.NoteMember{NoteID: note.ID, UserID: user.ID, Role: role, UpdatedAt: &now}
+	err = do.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: q.NoteMember.NoteID.ColumnName().String()},
+			{Name: q.NoteMember.UserID.ColumnName().String()},
+		},
+		DoUpdates: clause.AssignmentColumns([]string{
+			q.NoteMember.Role.ColumnName().String(),
+			q.NoteMember.UpdatedAt.ColumnName().String(),
+		}),
+	}).Create(member)
+	if err != nil {
+		return nil, err
+	}
+	// The user may have been a member with another role, so connected
+	// editors recheck their access as after SetRole.
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventAccessChanged})
+	return &NoteMemberItem{UserID: user.ID, Email: user.Email, Role: role, CreatedAt: member.CreatedAt}, nil
+}
+
+// SetRole changes the role of a user note is shared with.
+func (m *noteMemberUsecase) SetRole(note *domain.Note, userID string, role string) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	if !validNoteRole(role) {
+		return ErrInvalidNoteRole
+	}
+	q, do := m.newQuery()
+	res, err := do.Where(q.NoteMember.NoteID.Eq(note.ID), q.NoteMember.UserID.Eq(userID)).
+		UpdateSimple(q.NoteMember.Role.Value(role))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventAccessChanged})
+	return nil
+}
+
+// Remove stops sharing note with the user.
+func (m *noteMemberUsecase) Remove(note *domain.Note, userID string) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	q, do := m.newQuery()
+	res, err := do.Where(q.NoteMember.NoteID.Eq(note.ID), q.NoteMember.UserID.Eq(userID)).Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventAccessChanged})
+	return nil
+}
@@ -0,0 +1,318 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidNoteOrder is returned when a note cannot be placed between the
+// given neighbours, e.g. because they are not adjacent in the manual order.
+var ErrInvalidNoteOrder = errors.New("invalid note order")
+
+// Sort keys are fractional indexes: an integer part whose length is given
+// by its first character ("a0", "a1", ..., "b00", ...; "Zz" and below for
+// keys before "a0") followed by an optional fraction that never ends in "0".
+// Keys compare as plain strings, so a key between any two keys always exists
+// and moving a note only rewrites that note.
+const sortKeyDigits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestSortKeyInteger is the smallest integer part. Keys below it only
+// grow their fraction.
+const smallestSortKeyInteger = "A00000000000000000000000000"
+
+func sortKeyIntegerLength(head byte) (int, error) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, nil
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, nil
+	}
+	return 0, ErrInvalidNoteOrder
+}
+
+// splitSortKey returns the integer part and the fraction of key.
+func splitSortKey(key string) (string, string, error) {
+	if key == "" {
+		return "", "", ErrInvalidNoteOrder
+	}
+	length, err := sortKeyIntegerLength(key[0])
+	if err != nil || length > len(key) {
+		return "", "", ErrInvalidNoteOrder
+	}
+	integer, fraction := key[:length], key[length:]
+	if integer == smallestSortKeyInteger && fraction == "" || strings.HasSuffix(fraction, "0") {
+		return "", "", ErrInvalidNoteOrder
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(sortKeyDigits, key[i]) < 0 {
+			return "", "", ErrInvalidNoteOrder
+		}
+	}
+	return integer, fraction, nil
+}
+
+// sortKeyMidpoint returns a fraction between a and b. An empty b means no
+// upper bound.
+func sortKeyMidpoint(a, b string) string {
+	if b != "" {
+		// 共通する先頭の桁はそのまま使う
+		n := 0
+		for n < len(b) {
+			digitA := byte('0')
+			if n < len(a) {
+				digitA = a[n]
+			}
+			if digitA != b[n] {
+				break
+			}
+			n++
+		}
+		if n > 0 {
+			return b[:n] + sortKeyMidpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(sortKeyDigits, a[0])
+	}
+	digitB := len(sortKeyDigits)
+	if b != "" {
+		digitB = strings.IndexByte(sortKeyDigits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(sortKeyDigits[(digitA+digitB+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(sortKeyDigits[digitA]) + sortKeyMidpoint(rest, "")
+}
+
+// incrementSortKeyInteger returns the integer after x, or false if x is the
+// largest one.
+func incrementSortKeyInteger(x string) (string, bool) {
+	head, digits := x[0], []byte(x[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(sortKeyDigits, digits[i]) + 1
+		if d < len(sortKeyDigits) {
+			digits[i] = sortKeyDigits[d]
+			return string(head) + string(digits), true
+		}
+		digits[i] = '0'
+	}
+	switch head {
+	case 'Z':
+		return "a0", true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		digits = append(digits, '0')
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+// decrementSortKeyInteger returns the integer before x, or false if x is the
+// smallest one.
+func decrementSortKeyInteger(x string) (string, bool) {
+	last := sortKeyDigits[len(sortKeyDigits)-1]
+	head, digits := x[0], []byte(x[1:])
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := strings.IndexByte(sortKeyDigits, digits[i]) - 1
+		if d >= 0 {
+			digits[i] = sortKeyDigits[d]
+			return string(head) + string(digits), true
+		}
+		digits[i] = last
+	}
+	switch head {
+	case 'a':
+		return "Z" + string(last), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		digits = append(digits, last)
+	} else {
+		digits = digits[:len(digits)-1]
+	}
+	return string(head) + string(digits), true
+}
+
+// sortKeyBetween returns a key that sorts after a and before b. An empty a
+// or b means the start or the end of the list.
+func sortKeyBetween(a, b string) (string, error) {
+	var intA, fracA, intB, fracB string
+	var err error
+	if a != "" {
+		if intA, fracA, err = splitSortKey(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if intB, fracB, err = splitSortKey(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalidNoteOrder
+	}
+
+	switch {
+	case a == "" && b == "":
+		return "a0", nil
+	case a == "":
+		if intB == smallestSortKeyInteger {
+			return intB + sortKeyMidpoint("", fracB), nil
+		}
+		if intB < b {
+			return intB, nil
+		}
+		key, ok := decrementSortKeyInteger(intB)
+		if !ok {
+			return "", ErrInvalidNoteOrder
+		}
+		if key == smallestSortKeyInteger {
+			// 最小の整数部だけのキーはないため、小数部を付ける
+			return key + sortKeyMidpoint("", ""), nil
+		}
+		return key, nil
+	case b == "":
+		key, ok := incrementSortKeyInteger(intA)
+		if !ok {
+			return intA + sortKeyMidpoint(fracA, ""), nil
+		}
+		return key, nil
+	}
+	if intA == intB {
+		return intA + sortKeyMidpoint(fracA, fracB), nil
+	}
+	key, ok := incrementSortKeyInteger(intA)
+	if !ok {
+		return "", ErrInvalidNoteOrder
+	}
+	if key < b {
+		return key, nil
+	}
+	return intA + sortKeyMidpoint(fracA, ""), nil
+}
+
+// topSortKey returns a key that puts a new note of userID at the top of the
+// manual order. Notes in the trash are included so that a restored note
+// keeps a consistent place.
+func topSortKey(tx *repository.Query, userID string) (string, error) {
+	var first struct {
+		SortKey *string
+	}
+	err := tx.Note.WithContext(context.Background()).Unscoped().
+		Select(tx.Note.SortKey.Min().As("sort_key")).
+		Where(tx.Note.AuthorID.Eq(userID)).
+		Scan(&first)
+	if err != nil {
+		return "", err
+	}
+	if first.SortKey == nil {
+		return sortKeyBetween("", "")
+	}
+	return sortKeyBetween("", *first.SortKey)
+}
+
+// updateNoteListColumn sets a column that only affects how notes are listed.
+// The update methods of gen always set updated_at, which would move the note
+// in the list, so the column is set with UpdateColumn of gorm instead.
+func updateNoteListColumn(db *gorm.DB, noteID string, column string, value interface{}) error {
+	return db.Model(&domain.Note{}).Where("id = ?", noteID).UpdateColumn(column, value).Error
+}
+
+// SetPinned pins note to the top of the list or unpins it. Like moving, it
+// does not change the note's updated_at or version.
+func (n *noteUsecase) SetPinned(note *domain.Note, pinned bool) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	q, _ := n.newQuery()
+	if err := updateNoteListColumn(n.db, note.ID, q.Note.Pinned.ColumnName().String(), pinned); err != nil {
+		return err
+	}
+	note.Pinned = pinned
+	return nil
+}
+
+// SetFavorite adds note to or removes it from the favorites. It does not
+// change the note's updated_at or version either.
+func (n *noteUsecase) SetFavorite(note *domain.Note, favorite bool) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	q, _ := n.newQuery()
+	if err := updateNoteListColumn(n.db, note.ID, q.Note.Favorite.ColumnName().String(), favorite); err != nil {
+		return err
+	}
+	note.Favorite = favorite
+	return nil
+}
+
+// MoveNote places note between the notes afterID and beforeID in the manual
+// order, where afterID is the note shown above it and beforeID the one
+// below. Either may be empty at the start or the end of the list, and both
+// must belong to the author of note.
+func (n *noteUsecase) MoveNote(note *domain.Note, afterID string, beforeID string) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	if afterID == "" && beforeID == "" || afterID == note.ID || beforeID == note.ID {
+		return ErrInvalidNoteOrder
+	}
+	q, _ := n.newQuery()
+	return q.Transaction(func(tx *repository.Query) error {
+		do := tx.Note.WithContext(n.db.Statement.Context)
+		neighbourKey := func(id string) (string, error) {
+			if id == "" {
+				return "", nil
+			}
+			neighbour, err := do.Select(tx.Note.SortKey).
+				Where(tx.Note.ID.Eq(id), tx.Note.AuthorID.Eq(note.AuthorID)).
+				First()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", ErrInvalidNoteOrder
+			}
+			if err != nil {
+				return "", err
+			}
+			if neighbour.SortKey == nil {
+				return "", ErrInvalidNoteOrder
+			}
+			return *neighbour.SortKey, nil
+		}
+		after, err := neighbourKey(afterID)
+		if err != nil {
+			return err
+		}
+		before, err := neighbourKey(beforeID)
+		if err != nil {
+			return err
+		}
+		key, err := sortKeyBetween(after, before)
+		if err != nil {
+			return err
+		}
+		if err := updateNoteListColumn(do.UnderlyingDB(), note.ID, tx.Note.SortKey.ColumnName().String(), key); err != nil {
+			return err
+		}
+		note.SortKey = &key
+		return nil
+	})
+}
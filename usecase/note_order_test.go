@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// largestSortKeyInteger is the integer part after which no integer exists.
+var largestSortKeyInteger = "z" + strings.Repeat("z", 26)
+
+func TestSortKeyBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{"empty bounds", "", "", "a0"},
+		{"before the first key", "", "a0", "Zz"},
+		{"after the last key", "a0", "", "a1"},
+		{"between adjacent integers", "a0", "a1", "a0V"},
+		{"between an integer and its fraction", "a0", "a0V", "a0G"},
+		{"between a fraction and the next integer", "a0V", "a1", "a0l"},
+		{"between adjacent fractions", "a0V", "a0W", "a0VV"},
+		{"between distant integers", "a0", "a5", "a1"},
+		{"before a key with a fraction", "", "a1V", "a1"},
+		{"carry past the end of the integer part", "az", "", "b00"},
+		{"carry from negative to positive integers", "Zz", "", "a0"},
+		{"borrow into a longer negative integer", "", "Z0", "Yzz"},
+		{"after the largest integer", largestSortKeyInteger, "", largestSortKeyInteger + "V"},
+		{"before the smallest integer", "", smallestSortKeyInteger + "V", smallestSortKeyInteger + "G"},
+		{"down to the smallest integer", "", "A" + strings.Repeat("0", 25) + "1", smallestSortKeyInteger + "V"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sortKeyBetween(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("sortKeyBetween(%q, %q): %v", tt.a, tt.b, err)
+			}
+			if got != tt.want {
+				t.Errorf("sortKeyBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+			if _, _, err := splitSortKey(got); err != nil {
+				t.Errorf("sortKeyBetween(%q, %q) = %q, which is not a valid key", tt.a, tt.b, got)
+			}
+		})
+	}
+}
+
+func TestSortKeyBetweenRejectsInvalidBounds(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+	}{
+		{"bounds in the wrong order", "a1", "a0"},
+		{"equal bounds", "a0", "a0"},
+		{"fraction ending in 0", "a0V0", ""},
+		{"invalid head", "", "00"},
+		{"integer part too short", "b0", ""},
+		{"smallest integer without a fraction", "", smallestSortKeyInteger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := sortKeyBetween(tt.a, tt.b); !errors.Is(err, ErrInvalidNoteOrder) {
+				t.Errorf("sortKeyBetween(%q, %q) error = %v, want ErrInvalidNoteOrder", tt.a, tt.b, err)
+			}
+		})
+	}
+}
+
+func TestIncrementSortKeyInteger(t *testing.T) {
+	tests := []struct {
+		x    string
+		want string
+		ok   bool
+	}{
+		{"a0", "a1", true},
+		{"a9", "aA", true},
+		{"az", "b00", true},
+		{"b0z", "b10", true},
+		{"bzz", "c000", true},
+		{"Zz", "a0", true},
+		{"Yzz", "Z0", true},
+		{largestSortKeyInteger, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := incrementSortKeyInteger(tt.x)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("incrementSortKeyInteger(%q) = %q, %v, want %q, %v", tt.x, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDecrementSortKeyInteger(t *testing.T) {
+	tests := []struct {
+		x    string
+		want string
+		ok   bool
+	}{
+		{"a1", "a0", true},
+		{"aA", "a9", true},
+		{"a0", "Zz", true},
+		{"b00", "az", true},
+		{"Z0", "Yzz", true},
+		{"Yz0", "Yyz", true},
+		{smallestSortKeyInteger, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := decrementSortKeyInteger(tt.x)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("decrementSortKeyInteger(%q) = %q, %v, want %q, %v", tt.x, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSortKeyMidpoint(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want string
+	}{
+		{"", "", "V"},
+		{"", "V", "G"},
+		{"V", "", "l"},
+		{"V", "W", "VV"},
+		{"", "01", "00V"},
+		{"y", "", "z"},
+		{"z", "", "zV"},
+	}
+	for _, tt := range tests {
+		got := sortKeyMidpoint(tt.a, tt.b)
+		if got != tt.want {
+			t.Errorf("sortKeyMidpoint(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+		}
+		if got <= tt.a || tt.b != "" && got >= tt.b {
+			t.Errorf("sortKeyMidpoint(%q, %q) = %q, which is not between them", tt.a, tt.b, got)
+		}
+	}
+}
+
+func TestSortKeyBetweenKeepsOrder(t *testing.T) {
+	const n = 2000
+	next := func(t *testing.T, a, b string) string {
+		t.Helper()
+		key, err := sortKeyBetween(a, b)
+		if err != nil {
+			t.Fatalf("sortKeyBetween(%q, %q): %v", a, b, err)
+		}
+		if a != "" && key <= a || b != "" && key >= b {
+			t.Fatalf("sortKeyBetween(%q, %q) = %q, which is not between them", a, b, key)
+		}
+		if _, _, err := splitSortKey(key); err != nil {
+			t.Fatalf("sortKeyBetween(%q, %q) = %q, which is not a valid key", a, b, key)
+		}
+		return key
+	}
+
+	t.Run("repeated prepend", func(t *testing.T) {
+		// インポートと同じく、先頭に追加し続ける
+		key := ""
+		for range n {
+			key = next(t, "", key)
+		}
+	})
+	t.Run("repeated append", func(t *testing.T) {
+		key := ""
+		for range n {
+			key = next(t, key, "")
+		}
+	})
+	t.Run("repeated insert after the same key", func(t *testing.T) {
+		a, b := "a0", "a1"
+		for range 200 {
+			b = next(t, a, b)
+		}
+	})
+	t.Run("repeated insert before the same key", func(t *testing.T) {
+		a, b := "a0", "a1"
+		for range 200 {
+			a = next(t, a, b)
+		}
+	})
+}
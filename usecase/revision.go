@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+// NoteEditor identifies who changed a note.
+// UserID is set for logged-in users, ShareID for edits through a share link.
+// DisplayName is the name shown in the history: the name an anonymous editor
+// of a share link entered, or the email address of a logged-in one.
+type NoteEditor struct {
+	UserID      string
+	ShareID     string
+	DisplayName string
+}
+
+// maxEditorNameLength is the maximum length of a display name in characters.
+const maxEditorNameLength = 50
+
+// ErrInvalidEditorName is returned when a display name is empty or too long.
+var ErrInvalidEditorName = errors.New("invalid editor name")
+
+// NormalizeEditorName trims and collapses whitespace in a display name.
+func NormalizeEditorName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxEditorNameLength {
+		return "", ErrInvalidEditorName
+	}
+	return name, nil
+}
+
+type IRevisionUsecase interface {
+	FindByNote(note *domain.Note) ([]*domain.NoteRevision, error)
+	Find(note *domain.Note, revisionId string) (*domain.NoteRevision, error)
+	FindPrevious(revision *domain.NoteRevision) (*domain.NoteRevision, error)
+	FindLatest(note *domain.Note) (*domain.NoteRevision, error)
+	FindCoEditors(revisions []*domain.NoteRevision) (map[string][]*domain.NoteRevisionEditor, error)
+	Restore(note *domain.Note, revision *domain.NoteRevision, editor NoteEditor) (*domain.Note, error)
+}
+
+type revisionUsecase struct {
+	db *gorm.DB
+}
+
+func NewRevisionUsecase() IRevisionUsecase {
+	db := sqlite.GetDB()
+	return &revisionUsecase{
+		db: db,
+	}
+}
+
+func (r *revisionUsecase) newQuery() (*repository.Query, repository.INoteRevisionDo) {
+	q := repository.Use(r.db)
+	do := q.NoteRevision.WithContext(r.db.Statement.Context)
+	return q, do
+}
+
+func (r *revisionUsecase) FindByNote(note *domain.Note) ([]*domain.NoteRevision, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := r.newQuery()
+	rev := q.NoteRevision
+	// 一覧では本文を読み込まない
+	return do.Select(rev.ID, rev.NoteID, rev.Title, rev.AuthorID, rev.ShareID, rev.EditorName, rev.RestoredFrom, rev.CreatedAt, rev.UpdatedAt).
+		Where(rev.NoteID.Eq(note.ID)).
+		Order(rev.CreatedAt.Desc()).
+		Find()
+}
+
+func (r *revisionUsecase) Find(note *domain.Note, revisionId string) (*domain.NoteRevision, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := r.newQuery()
+	return do.Where(q.NoteRevision.ID.Eq(revisionId), q.NoteRevision.NoteID.Eq(note.ID)).First()
+}
+
+// FindPrevious returns the revision right before the given one, or nil if it is the oldest.
+func (r *revisionUsecase) FindPrevious(revision *domain.NoteRevision) (*domain.NoteRevision, error) {
+	q, do := r.newQuery()
+	rev := q.NoteRevision
+	prev, err := do.Where(rev.NoteID.Eq(revision.NoteID), rev.CreatedAt.Lt(*revision.CreatedAt)).
+		Order(rev.CreatedAt.Desc()).
+		First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return prev, err
+}
+
+// FindLatest returns the most recent revision of note without its content, or
+// nil if the note has no history. Its editor is the last one who changed the
+// note.
+func (r *revisionUsecase) FindLatest(note *domain.Note) (*domain.NoteRevision, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := r.newQuery()
+	rev := q.NoteRevision
+	latest, err := do.Select(rev.ID, rev.NoteID, rev.AuthorID, rev.ShareID, rev.EditorName, rev.CreatedAt, rev.UpdatedAt).
+		Where(rev.NoteID.Eq(note.ID)).
+		Order(rev.CreatedAt.Desc()).
+		First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return latest, err
+}
+
+// FindCoEditors returns the co-editors of revisions by revision ID. Only
+// revisions saved by a collaborative editing session with several editors
+// have co-editors.
+func (r *revisionUsecase) FindCoEditors(revisions []*domain.NoteRevision) (map[string][]*domain.NoteRevisionEditor, error) {
+	coEditors := map[string][]*domain.NoteRevisionEditor{}
+	if len(revisions) == 0 {
+		return coEditors, nil
+	}
+	ids := make([]string, 0, len(revisions))
+	for _, revision := range revisions {
+		ids = append(ids, revision.ID)
+	}
+	q := repository.Use(r.db)
+	re := q.NoteRevisionEditor
+	rows, err := re.WithContext(r.db.Statement.Context).Where(re.RevisionID.In(ids...)).Find()
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		coEditors[row.RevisionID] = append(coEditors[row.RevisionID], row)
+	}
+	return coEditors, nil
+}
+
+func (r *revisionUsecase) Restore(note *domain.Note, revision *domain.NoteRevision, editor NoteEditor) (*domain.Note, error) {
+	if note == nil || revision == nil {
+		return nil, errors.New("note and revision cannot be nil")
+	}
+	if revision.NoteID != note.ID {
+		return nil, errors.New("revision does not belong to note")
+	}
+
+	var relinked []*domain.Note
+	q := repository.Use(r.db)
+	err := q.Transaction(func(tx *repository.Query) error {
+		do := tx.Note.WithContext(r.db.Statement.Context)
+		stored, err := do.Where(tx.Note.ID.Eq(note.ID)).First()
+		if err != nil {
+			return err
+		}
+		if err := ensureBaselineRevision(tx, stored); err != nil {
+			return err
+		}
+		restored, err := updateNoteContent(tx, stored.ID, stored.Version, revision.Title, revision.Content)
+		if err != nil {
+			return err
+		}
+		note = restored
+		// 復元は履歴を書き換えず、常に新しいリビジョンとして記録する
+		if err := recordRevision(tx, restored, editor, revision.ID, nil); err != nil {
+			return err
+		}
+		relinked, err = updateNoteLinks(tx, stored, restored, editor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("note restored from revision", "noteID", note.ID, "revisionID", revision.ID)
+	publishNoteEvent(note.ID, NoteEvent{Type: NoteEventUpdated, Note: note})
+	for _, source := range relinked {
+		publishNoteEvent(source.ID, NoteEvent{Type: NoteEventUpdated, Note: source})
+	}
+	return note, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// revisionEditor returns the editor recorded on revision, who made its last
+// change.
+func revisionEditor(revision *domain.NoteRevision) NoteEditor {
+	return NoteEditor{
+		UserID:      stringValue(revision.AuthorID),
+		ShareID:     stringValue(revision.ShareID),
+		DisplayName: stringValue(revision.EditorName),
+	}
+}
+
+func coEditorOf(row *domain.NoteRevisionEditor) NoteEditor {
+	return NoteEditor{
+		UserID:      stringValue(row.AuthorID),
+		ShareID:     stringValue(row.ShareID),
+		DisplayName: stringValue(row.EditorName),
+	}
+}
+
+// containsEditors reports whether every editor of editors is in known. Editors
+// of the same share link with different names are different editors.
+func containsEditors(known []NoteEditor, editors []NoteEditor) bool {
+	for _, editor := range editors {
+		if !slices.Contains(known, editor) {
+			return false
+		}
+	}
+	return true
+}
+
+// replaceCoEditors records coEditors, except editor, as the co-editors of
+// revision.
+func replaceCoEditors(tx *repository.Query, revision *domain.NoteRevision, editor NoteEditor, coEditors []NoteEditor) error {
+	re := tx.NoteRevisionEditor
+	do := re.WithContext(context.Background())
+	if _, err := do.Where(re.RevisionID.Eq(revision.ID)).Delete(); err != nil {
+		return err
+	}
+	rows := make([]*domain.NoteRevisionEditor, 0, len(coEditors))
+	for _, coEditor := range coEditors {
+		if coEditor == editor || coEditor == (NoteEditor{}) {
+			continue
+		}
+		rows = append(rows, &domain.NoteRevisionEditor{
+			RevisionID: revision.ID,
+			NoteID:     revision.NoteID,
+			AuthorID:   optionalString(coEditor.UserID),
+			ShareID:    optionalString(coEditor.ShareID),
+			EditorName: optionalString(coEditor.DisplayName),
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return do.Create(rows...)
+}
+
+// ensureBaselineRevision records the stored state of a note that has no
+// history yet, so that the first update after this feature shipped is undoable.
+// The baseline has no editor so that it is never merged with later saves.
+func ensureBaselineRevision(tx *repository.Query, stored *domain.Note) error {
+	rev := tx.NoteRevision
+	do := rev.WithContext(context.Background())
+	count, err := do.Where(rev.NoteID.Eq(stored.ID)).Count()
+	if err != nil || count > 0 {
+		return err
+	}
+	return do.Create(&domain.NoteRevision{
+		ID:        newUUID(),
+		NoteID:    stored.ID,
+		Title:     stored.Title,
+		Content:   stored.Content,
+		CreatedAt: stored.UpdatedAt,
+		UpdatedAt: stored.UpdatedAt,
+	})
+}
+
+// recordRevision stores the current state of note as a revision by editor,
+// who made the last change. coEditors are the other editors whose changes
+// are included, as in a collaborative editing session. Consecutive saves
+// within the merge window are folded into the latest revision unless
+// restoredFrom is set, if all their editors already took part in it.
+func recordRevision(tx *repository.Query, note *domain.Note, editor NoteEditor, restoredFrom string, coEditors []NoteEditor) error {
+	rev := tx.NoteRevision
+	do := rev.WithContext(context.Background())
+	now := time.Now().UTC()
+
+	latest, err := do.Where(rev.NoteID.Eq(note.ID)).Order(rev.CreatedAt.Desc()).First()
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if latest != nil {
+		if latest.Title == note.Title && latest.Content == note.Content && restoredFrom == "" {
+			return nil
+		}
+		if restoredFrom == "" && latest.RestoredFrom == nil &&
+			latest.CreatedAt != nil && now.Sub(*latest.CreatedAt) < env.Get().RevisionMergeWindow {
+			merged, err := mergeRevision(tx, latest, note, editor, coEditors, now)
+			if merged || err != nil {
+				return err
+			}
+		}
+	}
+
+	revision := &domain.NoteRevision{
+		ID:           newUUID(),
+		NoteID:       note.ID,
+		Title:        note.Title,
+		Content:      note.Content,
+		AuthorID:     optionalString(editor.UserID),
+		ShareID:      optionalString(editor.ShareID),
+		EditorName:   optionalString(editor.DisplayName),
+		RestoredFrom: optionalString(restoredFrom),
+		CreatedAt:    &now,
+		UpdatedAt:    &now,
+	}
+	if err := do.Create(revision); err != nil {
+		return err
+	}
+	if len(coEditors) > 0 {
+		if err := replaceCoEditors(tx, revision, editor, coEditors); err != nil {
+			return err
+		}
+	}
+	return pruneRevisions(tx, note.ID)
+}
+
+// mergeRevision folds the save of note into latest if editor and coEditors
+// all took part in latest. editor becomes the editor of latest, and its
+// previous editor one of the co-editors.
+func mergeRevision(tx *repository.Query, latest *domain.NoteRevision, note *domain.Note, editor NoteEditor, coEditors []NoteEditor, now time.Time) (bool, error) {
+	re := tx.NoteRevisionEditor
+	rows, err := re.WithContext(context.Background()).Where(re.RevisionID.Eq(latest.ID)).Find()
+	if err != nil {
+		return false, err
+	}
+	previous := revisionEditor(latest)
+	known := []NoteEditor{previous}
+	for _, row := range rows {
+		known = append(known, coEditorOf(row))
+	}
+	// 同じ共有リンクでも名前の違う編集者の変更はまとめない
+	if !containsEditors(known, append([]NoteEditor{editor}, coEditors...)) {
+		return false, nil
+	}
+
+	rev := tx.NoteRevision
+	assigns := []field.AssignExpr{
+		rev.Title.Value(note.Title),
+		rev.Content.Value(note.Content),
+		rev.UpdatedAt.Value(now),
+	}
+	if previous != editor {
+		assigns = append(assigns,
+			assignOptionalString(rev.AuthorID, editor.UserID),
+			assignOptionalString(rev.ShareID, editor.ShareID),
+			assignOptionalString(rev.EditorName, editor.DisplayName),
+		)
+	}
+	if _, err := rev.WithContext(context.Background()).Where(rev.ID.Eq(latest.ID)).UpdateSimple(assigns...); err != nil {
+		return true, err
+	}
+	if previous == editor {
+		return true, nil
+	}
+	return true, replaceCoEditors(tx, latest, editor, known)
+}
+
+// assignOptionalString sets column to value, or NULL if value is empty.
+func assignOptionalString(column field.String, value string) field.AssignExpr {
+	if value == "" {
+		return column.Null()
+	}
+	return column.Value(value)
+}
+
+// pruneRevisions drops the oldest revisions of a note beyond the configured limit.
+func pruneRevisions(tx *repository.Query, noteID string) error {
+	limit := env.Get().RevisionLimit
+	if limit <= 0 {
+		return nil
+	}
+	rev := tx.NoteRevision
+	do := rev.WithContext(context.Background())
+
+	var expired []string
+	err := do.Where(rev.NoteID.Eq(noteID)).
+		Order(rev.CreatedAt.Desc()).
+		Offset(limit).
+		Pluck(rev.ID, &expired)
+	if err != nil || len(expired) == 0 {
+		return err
+	}
+	re := tx.NoteRevisionEditor
+	if _, err := re.WithContext(context.Background()).Where(re.RevisionID.In(expired...)).Delete(); err != nil {
+		return err
+	}
+	res, err := do.Where(rev.ID.In(expired...)).Delete()
+	if err != nil {
+		return err
+	}
+	slog.Debug("pruned note revisions", "noteID", noteID, "rowsAffected", res.RowsAffected)
+	return nil
+}
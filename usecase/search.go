@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"html"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	// trigram トークナイザーは3文字未満の語を索引から検索できない
+	minTrigramTerm = 3
+
+	// FTS5 の snippet/highlight で使う区切り文字。
+	// 制御文字はメモの保存時に除去されるため（stripControlChars）本文中には現れない。
+	markStart = "\x02"
+	markEnd   = "\x03"
+)
+
+// NoteSearchResult is a single hit of a full-text search.
+// TitleHTML and SnippetHTML are HTML-escaped with matches wrapped in <mark>.
+type NoteSearchResult struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	TitleHTML   string     `json:"title_html"`
+	SnippetHTML string     `json:"snippet_html"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+type searchRow struct {
+	ID            string
+	Title         string
+	TitleMarked   string
+	SnippetMarked string
+	UpdatedAt     *time.Time
+}
+
+// splitSearchTerms separates terms that the trigram index can answer from
+// shorter ones that need a LIKE scan.
+func splitSearchTerms(query string) (indexed []string, scanned []string) {
+	for _, term := range strings.Fields(query) {
+		if utf8.RuneCountInString(term) >= minTrigramTerm {
+			indexed = append(indexed, term)
+		} else {
+			scanned = append(scanned, term)
+		}
+	}
+	return indexed, scanned
+}
+
+// ftsMatchExpr builds an FTS5 query where every term must match as a phrase.
+func ftsMatchExpr(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+func likePattern(term string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + replacer.Replace(term) + "%"
+}
+
+// asciiLower lowercases ASCII letters only so that byte offsets are preserved.
+func asciiLower(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + ('a' - 'A')
+		}
+	}
+	return string(b)
+}
+
+// markTerms wraps case-insensitive (ASCII) occurrences of terms with markers.
+func markTerms(text string, terms []string) string {
+	if len(terms) == 0 {
+		return text
+	}
+	lower := asciiLower(text)
+	marked := make([]bool, len(text)+1)
+	ends := make([]bool, len(text)+1)
+	for _, term := range terms {
+		needle := asciiLower(term)
+		if needle == "" {
+			continue
+		}
+		for from := 0; ; {
+			idx := strings.Index(lower[from:], needle)
+			if idx < 0 {
+				break
+			}
+			start := from + idx
+			marked[start] = true
+			ends[start+len(needle)] = true
+			from = start + len(needle)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i <= len(text); i++ {
+		if ends[i] {
+			b.WriteString(markEnd)
+		}
+		if marked[i] {
+			b.WriteString(markStart)
+		}
+		if i < len(text) {
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// markedToHTML escapes text and turns the markers into <mark> elements.
+func markedToHTML(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = strings.ReplaceAll(escaped, markStart, "<mark>")
+	escaped = strings.ReplaceAll(escaped, markEnd, "</mark>")
+	return escaped
+}
+
+func (n *noteUsecase) Search(userID string, query string, limit int, offset int) ([]*NoteSearchResult, error) {
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	indexed, scanned := splitSearchTerms(query)
+	if len(indexed) == 0 && len(scanned) == 0 {
+		return []*NoteSearchResult{}, nil
+	}
+
+	args := []interface{}{}
+	var sql strings.Builder
+	if len(indexed) > 0 {
+		sql.WriteString(`SELECT n.id, n.title, n.updated_at,
+	highlight(notes_fts, 0, char(2), char(3)) AS title_marked,
+	snippet(notes_fts, 1, char(2), char(3), '…', 24) AS snippet_marked
+FROM notes_fts
+JOIN notes n ON n.search_id = notes_fts.rowid
+WHERE notes_fts MATCH ? AND n.author_id = ? AND n.deleted_at IS NULL`)
+		args = append(args, ftsMatchExpr(indexed), userID)
+	} else {
+		// 短い語のみの場合は索引を使えないため、最初の語の周辺を抜粋する
+		sql.WriteString(`SELECT n.id, n.title, n.updated_at,
+	n.title AS title_marked,
+	substr(n.content, max(instr(lower(n.content), lower(?)) - 24, 1), 64) AS snippet_marked
+FROM notes n
+WHERE n.author_id = ? AND n.deleted_at IS NULL`)
+		args = append(args, scanned[0], userID)
+	}
+	for _, term := range scanned {
+		sql.WriteString(` AND (n.title LIKE ? ESCAPE '\' OR n.content LIKE ? ESCAPE '\')`)
+		pattern := likePattern(term)
+		args = append(args, pattern, pattern)
+	}
+	if len(indexed) > 0 {
+		// タイトルの一致を本文より重視する
+		sql.WriteString(` ORDER BY bm25(notes_fts, 5.0, 1.0)`)
+	} else {
+		sql.WriteString(` ORDER BY n.updated_at DESC`)
+	}
+	sql.WriteString(` LIMIT ? OFFSET ?`)
+	args = append(args, limit, offset)
+
+	var rows []searchRow
+	if err := n.db.Raw(sql.String(), args...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	results := make([]*NoteSearchResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, &NoteSearchResult{
+			ID:          row.ID,
+			Title:       row.Title,
+			TitleHTML:   markedToHTML(markTerms(row.TitleMarked, scanned)),
+			SnippetHTML: markedToHTML(markTerms(row.SnippetMarked, scanned)),
+			UpdatedAt:   row.UpdatedAt,
+		})
+	}
+	return results, nil
+}
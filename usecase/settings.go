@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IUserSettingsUsecase interface {
+	TitleFormat(userID string) (string, error)
+	SetTitleFormat(userID string, format string) error
+}
+
+type userSettingsUsecase struct {
+	db *gorm.DB
+}
+
+func NewUserSettingsUsecase() IUserSettingsUsecase {
+	db := sqlite.GetDB()
+	return &userSettingsUsecase{
+		db: db,
+	}
+}
+
+func (u *userSettingsUsecase) newQuery() (*repository.Query, repository.IUserSettingDo) {
+	q := repository.Use(u.db)
+	do := q.UserSetting.WithContext(u.db.Statement.Context)
+	return q, do
+}
+
+// TitleFormat returns the format of the title of new notes of userID.
+func (u *userSettingsUsecase) TitleFormat(userID string) (string, error) {
+	q, _ := u.newQuery()
+	return titleFormatOf(q, userID)
+}
+
+// SetTitleFormat stores the title format of userID. An empty format resets
+// it to DefaultTitleFormat.
+func (u *userSettingsUsecase) SetTitleFormat(userID string, format string) error {
+	format, err := NormalizeTitleFormat(format)
+	if err != nil {
+		return err
+	}
+	q, do := u.newQuery()
+	setting := &domain.UserSetting{UserID: userID}
+	if format != "" {
+		setting.DefaultTitleFormat = &format
+	}
+	return do.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: q.UserSetting.UserID.ColumnName().String()}},
+		DoUpdates: clause.AssignmentColumns([]string{q.UserSetting.DefaultTitleFormat.ColumnName().String()}),
+	}).Create(setting)
+}
@@ -1,26 +1,94 @@
 package usecase
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/ToshihiroOgino/elib/domain"
 	"github.com/ToshihiroOgino/elib/infra/sqlite"
 	"github.com/ToshihiroOgino/elib/repository"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gen/field"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrShareExpired is returned when a share link is past its expiry or has
+	// been viewed as often as it allows.
+	ErrShareExpired = errors.New("share link has expired")
+	// ErrInvalidShareOptions is returned when a limit of a new share link is
+	// already over or cannot be met.
+	ErrInvalidShareOptions = errors.New("invalid share options")
+	// ErrInvalidSharePassword is returned when the password of a share link
+	// does not match.
+	ErrInvalidSharePassword = errors.New("invalid share password")
+	// ErrTooManyUnlockAttempts is returned while a share link refuses
+	// passwords after too many wrong ones.
+	ErrTooManyUnlockAttempts = errors.New("too many unlock attempts")
+)
+
+const (
+	// ShareUnlockMaxFailures is how many wrong passwords a share link accepts
+	// within ShareUnlockLockout before it refuses all passwords.
+	ShareUnlockMaxFailures = 5
+	// ShareUnlockLockout is how long a share link refuses passwords after
+	// ShareUnlockMaxFailures wrong ones.
+	ShareUnlockLockout = 15 * time.Minute
+	// sharePasswordMaxBytes is the longest password bcrypt can hash.
+	sharePasswordMaxBytes = 72
+)
+
+// ShareOptions are the limits of a new share link. Nil means no limit.
+type ShareOptions struct {
+	Editable bool
+	// ExpiresAt is when the link stops working.
+	ExpiresAt *time.Time
+	// MaxViews is how often the shared note can be opened. 1 makes a link
+	// that can be read only once.
+	MaxViews *int32
+	// EditableUntil is when an editable link becomes read-only.
+	EditableUntil *time.Time
+	// Password must be entered before the link shows the note. Empty means
+	// no password.
+	Password string
+}
+
 type IShareUsecase interface {
-	ShareNote(note *domain.Note, editable bool) (*domain.SharingInfo, error)
+	ShareNote(note *domain.Note, options ShareOptions) (*domain.SharingInfo, error)
 	FindByNote(note *domain.Note) ([]*domain.SharingInfo, error)
 	Delete(share *domain.SharingInfo) error
 	Find(shareId string) (*domain.SharingInfo, error)
+	FindIncludingExpired(shareId string) (*domain.SharingInfo, error)
+	FindViewed(shareId string) (*domain.SharingInfo, error)
+	RecordView(share *domain.SharingInfo) error
+	SetPassword(share *domain.SharingInfo, password string) error
+	Unlock(share *domain.SharingInfo, password string) error
 }
 
 type shareUsecase struct {
 	db *gorm.DB
 }
 
+// unlockFailures counts unlock attempts per share link. It is kept in
+// memory for all shareUsecase instances, like noteEvents.
+var unlockFailures = &shareUnlockThrottle{entries: map[string]*shareUnlockFailure{}}
+
+type shareUnlockThrottle struct {
+	mu      sync.Mutex
+	entries map[string]*shareUnlockFailure
+}
+
+type shareUnlockFailure struct {
+	// count includes the attempts whose password is still being checked.
+	count int
+	// since is when the first attempt of the current window happened.
+	since time.Time
+}
+
 func NewShareUsecase() IShareUsecase {
 	db := sqlite.GetDB()
 	return &shareUsecase{
@@ -34,15 +102,79 @@ func (s *shareUsecase) newQuery() (*repository.Query, repository.ISharingInfoDo)
 	return q, do
 }
 
-func (s *shareUsecase) ShareNote(note *domain.Note, editable bool) (*domain.SharingInfo, error) {
+// ShareExpired reports whether share can no longer be used at now.
+func ShareExpired(share *domain.SharingInfo, now time.Time) bool {
+	if share.ExpiresAt != nil && !now.Before(*share.ExpiresAt) {
+		return true
+	}
+	return share.MaxViews != nil && share.ViewCount >= *share.MaxViews
+}
+
+// ShareEditable reports whether share allows editing at now.
+func ShareEditable(share *domain.SharingInfo, now time.Time) bool {
+	return share.Editable && (share.EditableUntil == nil || now.Before(*share.EditableUntil))
+}
+
+// SharePasswordProtected reports whether share asks for a password.
+func SharePasswordProtected(share *domain.SharingInfo) bool {
+	return share.PasswordHash != nil && len(*share.PasswordHash) > 0
+}
+
+// ShareUnlockKey identifies the current password of share. Unlocks issued
+// for an earlier password do not match it after the password changes.
+func ShareUnlockKey(share *domain.SharingInfo) string {
+	if !SharePasswordProtected(share) {
+		return ""
+	}
+	sum := sha256.Sum256(*share.PasswordHash)
+	return hex.EncodeToString(sum[:8])
+}
+
+func hashSharePassword(password string) (*[]byte, error) {
+	if password == "" {
+		return nil, nil
+	}
+	if len(password) > sharePasswordMaxBytes {
+		return nil, ErrInvalidShareOptions
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	return &hash, nil
+}
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+func (s *shareUsecase) ShareNote(note *domain.Note, options ShareOptions) (*domain.SharingInfo, error) {
 	if note == nil {
 		return nil, errors.New("note cannot be nil")
 	}
+	now := time.Now()
+	if options.ExpiresAt != nil && !now.Before(*options.ExpiresAt) ||
+		options.MaxViews != nil && *options.MaxViews < 1 ||
+		options.EditableUntil != nil && (!options.Editable || !now.Before(*options.EditableUntil)) {
+		return nil, ErrInvalidShareOptions
+	}
+	passwordHash, err := hashSharePassword(options.Password)
+	if err != nil {
+		return nil, err
+	}
 
 	sharingInfo := &domain.SharingInfo{
-		ID:       newUUID(),
-		NoteID:   note.ID,
-		Editable: editable,
+		ID:            newUUID(),
+		NoteID:        note.ID,
+		Editable:      options.Editable,
+		ExpiresAt:     utcTime(options.ExpiresAt),
+		MaxViews:      options.MaxViews,
+		EditableUntil: utcTime(options.EditableUntil),
+		PasswordHash:  passwordHash,
 	}
 
 	_, do := s.newQuery()
@@ -71,16 +203,62 @@ func (s *shareUsecase) Delete(share *domain.SharingInfo) error {
 	if share == nil {
 		return errors.New("share cannot be nil")
 	}
-	q, do := s.newQuery()
-	res, err := do.Where(q.SharingInfo.ID.Eq(share.ID)).Delete()
+	q, _ := s.newQuery()
+	err := q.Transaction(func(tx *repository.Query) error {
+		ctx := s.db.Statement.Context
+		// 外部キー制約は有効化していないため、アクセス記録も明示的に削除する
+		if _, err := tx.ShareAccessLog.WithContext(ctx).Where(tx.ShareAccessLog.ShareID.Eq(share.ID)).Delete(); err != nil {
+			return err
+		}
+		res, err := tx.SharingInfo.WithContext(ctx).Where(tx.SharingInfo.ID.Eq(share.ID)).Delete()
+		if err != nil {
+			return err
+		}
+		slog.Info("deleted sharing info", "id", share.ID, "rowsAffected", res.RowsAffected)
+		return nil
+	})
 	if err != nil {
 		return err
 	}
-	slog.Info("deleted sharing info", "id", share.ID, "rowsAffected", res.RowsAffected)
+	publishNoteEvent(share.NoteID, NoteEvent{Type: NoteEventAccessChanged})
 	return nil
 }
 
+// Find returns a share link that can still be used, or ErrShareExpired. An
+// editable link past its EditableUntil is returned as read-only.
 func (s *shareUsecase) Find(shareId string) (*domain.SharingInfo, error) {
+	share, err := s.FindIncludingExpired(shareId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if ShareExpired(share, now) {
+		return nil, ErrShareExpired
+	}
+	share.Editable = ShareEditable(share, now)
+	return share, nil
+}
+
+// FindViewed returns a share link for the page of a view that has already
+// been counted. Unlike Find it still returns a link whose MaxViews are used
+// up, so that the page can load its attachments and updates after the last
+// allowed view, but not a link past its ExpiresAt.
+func (s *shareUsecase) FindViewed(shareId string) (*domain.SharingInfo, error) {
+	share, err := s.FindIncludingExpired(shareId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if share.ExpiresAt != nil && !now.Before(*share.ExpiresAt) {
+		return nil, ErrShareExpired
+	}
+	share.Editable = ShareEditable(share, now)
+	return share, nil
+}
+
+// FindIncludingExpired returns a share link as it is stored, for its owner
+// to manage.
+func (s *shareUsecase) FindIncludingExpired(shareId string) (*domain.SharingInfo, error) {
 	if shareId == "" {
 		return nil, errors.New("shareId cannot be empty")
 	}
@@ -93,3 +271,118 @@ func (s *shareUsecase) Find(shareId string) (*domain.SharingInfo, error) {
 
 	return share, nil
 }
+
+// RecordView counts an opening of the shared note. It returns ErrShareExpired
+// if the link has been used up in the meantime, so that concurrent viewers
+// cannot exceed MaxViews.
+func (s *shareUsecase) RecordView(share *domain.SharingInfo) error {
+	if share == nil {
+		return errors.New("share cannot be nil")
+	}
+	q, do := s.newQuery()
+	si := q.SharingInfo
+	res, err := do.Where(si.ID.Eq(share.ID), field.Or(si.MaxViews.IsNull(), si.ViewCount.LtCol(si.MaxViews))).
+		UpdateSimple(si.ViewCount.Add(1))
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return ErrShareExpired
+	}
+	share.ViewCount++
+	return nil
+}
+
+// SetPassword replaces the password of share. An empty password removes it.
+// Changing the password locks out everyone who unlocked the link before.
+func (s *shareUsecase) SetPassword(share *domain.SharingInfo, password string) error {
+	if share == nil {
+		return errors.New("share cannot be nil")
+	}
+	passwordHash, err := hashSharePassword(password)
+	if err != nil {
+		return err
+	}
+	q, do := s.newQuery()
+	si := q.SharingInfo
+	var value interface{}
+	if passwordHash != nil {
+		value = *passwordHash
+	}
+	if _, err := do.Where(si.ID.Eq(share.ID)).UpdateColumn(si.PasswordHash, value); err != nil {
+		return err
+	}
+	share.PasswordHash = passwordHash
+	unlockFailures.reset(share.ID)
+	publishNoteEvent(share.NoteID, NoteEvent{Type: NoteEventAccessChanged})
+	return nil
+}
+
+// Unlock checks password against share. After ShareUnlockMaxFailures wrong
+// passwords within ShareUnlockLockout, it returns ErrTooManyUnlockAttempts
+// without checking until the lockout is over.
+func (s *shareUsecase) Unlock(share *domain.SharingInfo, password string) error {
+	if share == nil {
+		return errors.New("share cannot be nil")
+	}
+	if !SharePasswordProtected(share) {
+		return nil
+	}
+	if !unlockFailures.attempt(share.ID, time.Now()) {
+		return ErrTooManyUnlockAttempts
+	}
+	if bcrypt.CompareHashAndPassword(*share.PasswordHash, []byte(password)) != nil {
+		slog.Warn("wrong share password", "shareId", share.ID)
+		return ErrInvalidSharePassword
+	}
+	unlockFailures.undo(share.ID)
+	return nil
+}
+
+// attempt counts an unlock attempt of shareID as a failure before its
+// password is checked, so that guesses sent in parallel cannot exceed
+// ShareUnlockMaxFailures. It returns false while the share link refuses
+// passwords. Entries whose window is over are removed on the way.
+func (t *shareUnlockThrottle) attempt(shareID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, entry := range t.entries {
+		if now.Sub(entry.since) >= ShareUnlockLockout {
+			delete(t.entries, id)
+		}
+	}
+	entry, ok := t.entries[shareID]
+	if !ok {
+		entry = &shareUnlockFailure{since: now}
+		t.entries[shareID] = entry
+	}
+	if entry.count >= ShareUnlockMaxFailures {
+		return false
+	}
+	entry.count++
+	if entry.count == ShareUnlockMaxFailures {
+		// 上限に達した時点から ShareUnlockLockout の間拒否する
+		entry.since = now
+	}
+	return true
+}
+
+// undo takes back an attempt of shareID whose password was correct.
+func (t *shareUnlockThrottle) undo(shareID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[shareID]
+	if !ok {
+		return
+	}
+	entry.count--
+	if entry.count <= 0 {
+		delete(t.entries, shareID)
+	}
+}
+
+func (t *shareUnlockThrottle) reset(shareID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, shareID)
+}
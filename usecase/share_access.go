@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/env"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+)
+
+// Actions recorded in the share access log.
+const (
+	ShareAccessView = "view"
+	ShareAccessEdit = "edit"
+)
+
+const (
+	// shareAccessQueueSize is how many accesses can wait to be written.
+	// Accesses beyond it are dropped rather than slowing down the request.
+	shareAccessQueueSize = 1024
+	// shareAccessBatchSize is the most accesses written in one insert.
+	shareAccessBatchSize     = 100
+	shareAccessPurgeInterval = time.Hour
+	// shareAccessUserAgentMaxBytes is how much of the User-Agent header is
+	// kept.
+	shareAccessUserAgentMaxBytes = 256
+	// ShareAccessRecentLimit is how many recent accesses are listed.
+	ShareAccessRecentLimit = 20
+)
+
+// shareAccessQueue hands accesses from the request handlers to the writer
+// started by StartShareAccessLogger.
+var shareAccessQueue = make(chan *domain.ShareAccessLog, shareAccessQueueSize)
+
+// ShareAccess is a view or edit through a share link.
+type ShareAccess struct {
+	Share     *domain.SharingInfo
+	Action    string
+	IP        string
+	UserAgent string
+	// User is the logged-in user who opened the link, if any.
+	User *domain.User
+}
+
+// ShareAccessStats summarizes the accesses to a share link.
+type ShareAccessStats struct {
+	Views int64 `json:"views"`
+	Edits int64 `json:"edits"`
+	// Visitors is the number of distinct IP addresses.
+	Visitors       int64      `json:"visitors"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+}
+
+// ShareAccessItem is a recorded access to a share link. IPHash is a keyed
+// hash of the IP address, so that accesses from the same address can be told
+// apart without storing the address.
+type ShareAccessItem struct {
+	Action    string     `json:"action"`
+	IPHash    string     `json:"ip_hash"`
+	UserAgent string     `json:"user_agent"`
+	UserEmail *string    `json:"user_email"`
+	CreatedAt *time.Time `json:"created_at"`
+}
+
+type IShareAccessUsecase interface {
+	Record(access ShareAccess)
+	StatsByShares(shares []*domain.SharingInfo) (map[string]*ShareAccessStats, error)
+	Recent(share *domain.SharingInfo, limit int) ([]*ShareAccessItem, error)
+	Purge(before time.Time) (int64, error)
+}
+
+type shareAccessUsecase struct {
+	db *gorm.DB
+}
+
+func NewShareAccessUsecase() IShareAccessUsecase {
+	db := sqlite.GetDB()
+	return &shareAccessUsecase{
+		db: db,
+	}
+}
+
+func (s *shareAccessUsecase) newQuery() (*repository.Query, repository.IShareAccessLogDo) {
+	q := repository.Use(s.db)
+	do := q.ShareAccessLog.WithContext(s.db.Statement.Context)
+	return q, do
+}
+
+// hashShareAccessIP returns a keyed hash of ip. The key is the JWT secret, so
+// that the hash cannot be reversed by trying every address.
+func hashShareAccessIP(ip string) string {
+	mac := hmac.New(sha256.New, []byte(env.Get().JWTSecret))
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// truncateUTF8 shortens s to at most maxBytes without splitting a character.
+func truncateUTF8(s string, maxBytes int) string {
+	if len(s) <= maxBytes {
+		return s
+	}
+	s = s[:maxBytes]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+// Record queues access to be written to the access log in the background. It
+// never blocks; if the queue is full the access is dropped.
+func (s *shareAccessUsecase) Record(access ShareAccess) {
+	if access.Share == nil {
+		return
+	}
+	now := time.Now().UTC()
+	entry := &domain.ShareAccessLog{
+		ID:        newUUID(),
+		ShareID:   access.Share.ID,
+		NoteID:    access.Share.NoteID,
+		Action:    access.Action,
+		IPHash:    hashShareAccessIP(access.IP),
+		UserAgent: truncateUTF8(strings.TrimSpace(access.UserAgent), shareAccessUserAgentMaxBytes),
+		CreatedAt: &now,
+	}
+	if access.User != nil {
+		entry.UserID = &access.User.ID
+	}
+	select {
+	case shareAccessQueue <- entry:
+	default:
+		slog.Warn("share access log queue is full", "shareId", entry.ShareID, "action", entry.Action)
+	}
+}
+
+// StatsByShares returns the access statistics of shares by share ID. Shares
+// that have never been accessed have zero statistics.
+func (s *shareAccessUsecase) StatsByShares(shares []*domain.SharingInfo) (map[string]*ShareAccessStats, error) {
+	stats := make(map[string]*ShareAccessStats, len(shares))
+	if len(shares) == 0 {
+		return stats, nil
+	}
+	shareIDs := make([]string, 0, len(shares))
+	for _, share := range shares {
+		shareIDs = append(shareIDs, share.ID)
+		stats[share.ID] = &ShareAccessStats{}
+	}
+
+	q, do := s.newQuery()
+	al := q.ShareAccessLog
+	var counts []struct {
+		ShareID string
+		Action  string
+		Count   int64
+	}
+	err := do.Select(al.ShareID, al.Action, al.ID.Count().As("count")).
+		Where(al.ShareID.In(shareIDs...)).
+		Group(al.ShareID, al.Action).
+		Scan(&counts)
+	if err != nil {
+		return nil, err
+	}
+	for _, count := range counts {
+		switch count.Action {
+		case ShareAccessView:
+			stats[count.ShareID].Views = count.Count
+		case ShareAccessEdit:
+			stats[count.ShareID].Edits = count.Count
+		}
+	}
+
+	var visitors []struct {
+		ShareID  string
+		Visitors int64
+	}
+	err = do.Select(al.ShareID, al.IPHash.Distinct().Count().As("visitors")).
+		Where(al.ShareID.In(shareIDs...)).
+		Group(al.ShareID).
+		Scan(&visitors)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range visitors {
+		stats[v.ShareID].Visitors = v.Visitors
+	}
+
+	// MAX(created_at) は SQLite が日時として返さないため、リンクごとに最新の記録を取得する
+	for _, shareID := range shareIDs {
+		if stats[shareID].Views+stats[shareID].Edits == 0 {
+			continue
+		}
+		latest, err := do.Where(al.ShareID.Eq(shareID)).Order(al.CreatedAt.Desc()).First()
+		if err != nil {
+			return nil, err
+		}
+		stats[shareID].LastAccessedAt = latest.CreatedAt
+	}
+	return stats, nil
+}
+
+// Recent returns the latest accesses to share, newest first.
+func (s *shareAccessUsecase) Recent(share *domain.SharingInfo, limit int) ([]*ShareAccessItem, error) {
+	if share == nil {
+		return nil, errors.New("share cannot be nil")
+	}
+	q, do := s.newQuery()
+	al := q.ShareAccessLog
+	var items []*ShareAccessItem
+	err := do.Select(al.Action, al.IPHash, al.UserAgent, q.User.Email.As("user_email"), al.CreatedAt).
+		LeftJoin(q.User, q.User.ID.EqCol(al.UserID)).
+		Where(al.ShareID.Eq(share.ID)).
+		Order(al.CreatedAt.Desc()).
+		Limit(limit).
+		Scan(&items)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Purge deletes the accesses recorded before the given time.
+func (s *shareAccessUsecase) Purge(before time.Time) (int64, error) {
+	q, do := s.newQuery()
+	res, err := do.Where(q.ShareAccessLog.CreatedAt.Lt(before.UTC())).Delete()
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
+
+// write inserts the queued entries, logging instead of failing the requests
+// they came from.
+func (s *shareAccessUsecase) write(entries []*domain.ShareAccessLog) {
+	_, do := s.newQuery()
+	if err := do.CreateInBatches(entries, shareAccessBatchSize); err != nil {
+		slog.Error("failed to write share access log", "count", len(entries), "error", err)
+	}
+}
+
+// StartShareAccessLogger writes the accesses queued by Record in the
+// background and deletes the ones older than retention every hour. A
+// non-positive retention keeps them forever.
+func StartShareAccessLogger(retention time.Duration) {
+	accessUsecase := &shareAccessUsecase{db: sqlite.GetDB()}
+	go func() {
+		purge := time.NewTicker(shareAccessPurgeInterval)
+		defer purge.Stop()
+		accessUsecase.purgeOlderThan(retention)
+		for {
+			select {
+			case entry := <-shareAccessQueue:
+				entries := []*domain.ShareAccessLog{entry}
+				// 溜まっている記録はまとめて書き込む
+			drain:
+				for len(entries) < shareAccessBatchSize {
+					select {
+					case next := <-shareAccessQueue:
+						entries = append(entries, next)
+					default:
+						break drain
+					}
+				}
+				accessUsecase.write(entries)
+			case <-purge.C:
+				accessUsecase.purgeOlderThan(retention)
+			}
+		}
+	}()
+}
+
+func (s *shareAccessUsecase) purgeOlderThan(retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	purged, err := s.Purge(time.Now().Add(-retention))
+	if err != nil {
+		slog.Error("failed to purge share access log", "error", err)
+	} else if purged > 0 {
+		slog.Info("purged share access log", "count", purged)
+	}
+}
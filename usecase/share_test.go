@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+)
+
+func TestShareUnlockKeyChangesWithPassword(t *testing.T) {
+	protect := func(password string) *domain.SharingInfo {
+		t.Helper()
+		hash, err := hashSharePassword(password)
+		if err != nil {
+			t.Fatalf("hash %q: %v", password, err)
+		}
+		return &domain.SharingInfo{ID: "share", PasswordHash: hash}
+	}
+
+	first := ShareUnlockKey(protect("secret"))
+	if first == "" {
+		t.Fatal("a protected share has an empty unlock key")
+	}
+	if changed := ShareUnlockKey(protect("another")); changed == first {
+		t.Error("changing the password keeps the unlock key")
+	}
+	// 同じパスワードを設定し直しても、以前の解除は無効になる
+	if reset := ShareUnlockKey(protect("secret")); reset == first {
+		t.Error("setting the same password again keeps the unlock key")
+	}
+	if removed := ShareUnlockKey(protect("")); removed != "" {
+		t.Errorf("unlock key without a password = %q, want empty", removed)
+	}
+}
+
+func TestShareUnlockThrottleCountsParallelAttempts(t *testing.T) {
+	throttle := &shareUnlockThrottle{entries: map[string]*shareUnlockFailure{}}
+	now := time.Now()
+
+	// パスワードの確認中の試行も数えるので、並行した試行でも上限を超えない
+	allowed := 0
+	for range ShareUnlockMaxFailures * 2 {
+		if throttle.attempt("share", now) {
+			allowed++
+		}
+	}
+	if allowed != ShareUnlockMaxFailures {
+		t.Fatalf("allowed %d attempts, want %d", allowed, ShareUnlockMaxFailures)
+	}
+
+	throttle.undo("share")
+	if !throttle.attempt("share", now) {
+		t.Error("a correct password does not give back its attempt")
+	}
+
+	if !throttle.attempt("other", now.Add(ShareUnlockLockout)) {
+		t.Error("another share link is locked")
+	}
+	if _, ok := throttle.entries["share"]; ok {
+		t.Error("an entry past its lockout is kept")
+	}
+}
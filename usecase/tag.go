@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxTagNameLength = 50
+
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+// TagSummary is a tag with the number of notes it is attached to.
+type TagSummary struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	NoteCount int64  `json:"note_count"`
+}
+
+type ITagUsecase interface {
+	Find(tagId string) (*domain.Tag, error)
+	FindByUser(userID string) ([]*TagSummary, error)
+	FindByNote(note *domain.Note) ([]*domain.Tag, error)
+	AddToNote(note *domain.Note, name string) (*domain.Tag, error)
+	RemoveFromNote(note *domain.Note, tag *domain.Tag) error
+	Rename(tag *domain.Tag, name string) (*domain.Tag, error)
+	Merge(target *domain.Tag, sources []*domain.Tag) error
+	Delete(tag *domain.Tag) error
+}
+
+type tagUsecase struct {
+	db *gorm.DB
+}
+
+func NewTagUsecase() ITagUsecase {
+	db := sqlite.GetDB()
+	return &tagUsecase{
+		db: db,
+	}
+}
+
+func (t *tagUsecase) newQuery() (*repository.Query, repository.ITagDo) {
+	q := repository.Use(t.db)
+	do := q.Tag.WithContext(t.db.Statement.Context)
+	return q, do
+}
+
+// normalizeTagName trims and collapses whitespace in a tag name.
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxTagNameLength || strings.ContainsAny(name, ",") {
+		return "", ErrInvalidTagName
+	}
+	return name, nil
+}
+
+func (t *tagUsecase) Find(tagId string) (*domain.Tag, error) {
+	q, do := t.newQuery()
+	return do.Where(q.Tag.ID.Eq(tagId)).First()
+}
+
+func (t *tagUsecase) FindByUser(userID string) ([]*TagSummary, error) {
+	q, do := t.newQuery()
+	var summaries []*TagSummary
+	// ゴミ箱のメモは件数に含めない
+	err := do.Select(q.Tag.ID, q.Tag.Name, q.Note.ID.Count().As("note_count")).
+		LeftJoin(q.NoteTag, q.NoteTag.TagID.EqCol(q.Tag.ID)).
+		LeftJoin(q.Note, q.Note.ID.EqCol(q.NoteTag.NoteID), q.Note.DeletedAt.IsNull()).
+		Where(q.Tag.UserID.Eq(userID)).
+		Group(q.Tag.ID).
+		Order(q.Tag.Name).
+		Scan(&summaries)
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (t *tagUsecase) FindByNote(note *domain.Note) ([]*domain.Tag, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	q, do := t.newQuery()
+	return do.Join(q.NoteTag, q.NoteTag.TagID.EqCol(q.Tag.ID)).
+		Where(q.NoteTag.NoteID.Eq(note.ID)).
+		Order(q.Tag.Name).
+		Find()
+}
+
+// AddToNote attaches the tag with the given name to note, creating the tag
+// for the note's author if it does not exist yet.
+func (t *tagUsecase) AddToNote(note *domain.Note, name string) (*domain.Tag, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag *domain.Tag
+	q, _ := t.newQuery()
+	err = q.Transaction(func(tx *repository.Query) error {
+		ctx := context.Background()
+		tagDo := tx.Tag.WithContext(ctx)
+		tag, err = tagDo.Where(tx.Tag.UserID.Eq(note.AuthorID), tx.Tag.Name.Eq(name)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			tag = &domain.Tag{
+				ID:     newUUID(),
+				UserID: note.AuthorID,
+				Name:   name,
+			}
+			err = tagDo.Create(tag)
+		}
+		if err != nil {
+			return err
+		}
+		return tx.NoteTag.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&domain.NoteTag{NoteID: note.ID, TagID: tag.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (t *tagUsecase) RemoveFromNote(note *domain.Note, tag *domain.Tag) error {
+	if note == nil || tag == nil {
+		return errors.New("note and tag cannot be nil")
+	}
+	q, _ := t.newQuery()
+	do := q.NoteTag.WithContext(t.db.Statement.Context)
+	_, err := do.Where(q.NoteTag.NoteID.Eq(note.ID), q.NoteTag.TagID.Eq(tag.ID)).Delete()
+	return err
+}
+
+// Rename changes the name of tag. If the user already has a tag with the new
+// name, tag is merged into it so that every note keeps a single tag.
+func (t *tagUsecase) Rename(tag *domain.Tag, name string) (*domain.Tag, error) {
+	if tag == nil {
+		return nil, errors.New("tag cannot be nil")
+	}
+	name, err := normalizeTagName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := tag
+	q, _ := t.newQuery()
+	err = q.Transaction(func(tx *repository.Query) error {
+		tagDo := tx.Tag.WithContext(context.Background())
+		existing, err := tagDo.Where(tx.Tag.UserID.Eq(tag.UserID), tx.Tag.Name.Eq(name)).First()
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if existing != nil && existing.ID != tag.ID {
+			result = existing
+			return mergeTags(tx, existing, []*domain.Tag{tag})
+		}
+		if _, err := tagDo.Where(tx.Tag.ID.Eq(tag.ID)).Update(tx.Tag.Name, name); err != nil {
+			return err
+		}
+		tag.Name = name
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (t *tagUsecase) Merge(target *domain.Tag, sources []*domain.Tag) error {
+	if target == nil {
+		return errors.New("target cannot be nil")
+	}
+	for _, source := range sources {
+		if source.UserID != target.UserID {
+			return errors.New("cannot merge tags of different users")
+		}
+	}
+	q, _ := t.newQuery()
+	return q.Transaction(func(tx *repository.Query) error {
+		return mergeTags(tx, target, sources)
+	})
+}
+
+// mergeTags moves every note of sources to target and deletes sources.
+func mergeTags(tx *repository.Query, target *domain.Tag, sources []*domain.Tag) error {
+	ctx := context.Background()
+	noteTagDo := tx.NoteTag.WithContext(ctx)
+	for _, source := range sources {
+		if source.ID == target.ID {
+			continue
+		}
+		var noteIDs []string
+		if err := noteTagDo.Where(tx.NoteTag.TagID.Eq(source.ID)).Pluck(tx.NoteTag.NoteID, &noteIDs); err != nil {
+			return err
+		}
+		for _, noteID := range noteIDs {
+			err := noteTagDo.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&domain.NoteTag{NoteID: noteID, TagID: target.ID})
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := noteTagDo.Where(tx.NoteTag.TagID.Eq(source.ID)).Delete(); err != nil {
+			return err
+		}
+		if _, err := tx.Tag.WithContext(ctx).Where(tx.Tag.ID.Eq(source.ID)).Delete(); err != nil {
+			return err
+		}
+		slog.Info("merged tag", "source", source.ID, "target", target.ID, "notes", len(noteIDs))
+	}
+	return nil
+}
+
+func (t *tagUsecase) Delete(tag *domain.Tag) error {
+	if tag == nil {
+		return errors.New("tag cannot be nil")
+	}
+	q, _ := t.newQuery()
+	return q.Transaction(func(tx *repository.Query) error {
+		ctx := context.Background()
+		if _, err := tx.NoteTag.WithContext(ctx).Where(tx.NoteTag.TagID.Eq(tag.ID)).Delete(); err != nil {
+			return err
+		}
+		_, err := tx.Tag.WithContext(ctx).Where(tx.Tag.ID.Eq(tag.ID)).Delete()
+		return err
+	})
+}
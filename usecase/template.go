@@ -0,0 +1,238 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTemplateNameLength = 100
+	maxTitleFormatLength  = 200
+	// maxExpandedTitleBytes is the title limit of the save endpoints. A title
+	// longer than this after expansion is cut.
+	maxExpandedTitleBytes = 500
+)
+
+// DefaultTitleFormat is the title of a new note for users who have not set
+// their own format.
+const DefaultTitleFormat = "Untitled_{{timestamp}}"
+
+// TemplatePlaceholders lists the placeholders expanded in templates and in
+// title formats.
+var TemplatePlaceholders = []string{"date", "time", "datetime", "timestamp", "user.email"}
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z.]+)\s*\}\}`)
+
+var (
+	ErrInvalidTemplateName = errors.New("invalid template name")
+	// ErrTemplateNameTaken is returned when a template is renamed to the name
+	// of another template of the same user.
+	ErrTemplateNameTaken  = errors.New("template name is already used")
+	ErrInvalidTitleFormat = errors.New("invalid title format")
+	// ErrTemplateTooLarge is returned when the content of a template is
+	// longer than the note limit once its placeholders are expanded.
+	ErrTemplateTooLarge = errors.New("expanded template is too large")
+)
+
+// expandPlaceholders replaces the placeholders in text. Unknown placeholders
+// are left as they are.
+func expandPlaceholders(text string, user *domain.User, now time.Time) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		switch placeholderPattern.FindStringSubmatch(match)[1] {
+		case "date":
+			return now.Format("2006-01-02")
+		case "time":
+			return now.Format("15:04")
+		case "datetime":
+			return now.Format("2006-01-02 15:04")
+		case "timestamp":
+			return now.Format("20060102150405")
+		case "user.email":
+			return user.Email
+		}
+		return match
+	})
+}
+
+// expandTitle expands a title format, cutting the result at the title limit
+// and falling back to DefaultTitleFormat if nothing is left.
+func expandTitle(format string, user *domain.User, now time.Time) string {
+	title := strings.TrimSpace(expandPlaceholders(format, user, now))
+	if len(title) > maxExpandedTitleBytes {
+		title = title[:maxExpandedTitleBytes]
+		for !utf8.ValidString(title) {
+			title = title[:len(title)-1]
+		}
+	}
+	if title == "" {
+		return expandPlaceholders(DefaultTitleFormat, user, now)
+	}
+	return title
+}
+
+// titleFormatOf returns the title format the user has set, or
+// DefaultTitleFormat.
+func titleFormatOf(q *repository.Query, userID string) (string, error) {
+	s := q.UserSetting
+	// 設定のないユーザーが普通なので、First で見つからないエラーをログに出さない
+	settings, err := s.WithContext(context.Background()).Where(s.UserID.Eq(userID)).Limit(1).Find()
+	if err != nil {
+		return "", err
+	}
+	if len(settings) == 0 || settings[0].DefaultTitleFormat == nil || *settings[0].DefaultTitleFormat == "" {
+		return DefaultTitleFormat, nil
+	}
+	return *settings[0].DefaultTitleFormat, nil
+}
+
+// NormalizeTitleFormat trims format and checks its length. An empty format
+// means DefaultTitleFormat.
+func NormalizeTitleFormat(format string) (string, error) {
+	format = strings.TrimSpace(format)
+	if utf8.RuneCountInString(format) > maxTitleFormatLength || strings.ContainsAny(format, "\r\n") {
+		return "", ErrInvalidTitleFormat
+	}
+	return format, nil
+}
+
+type ITemplateUsecase interface {
+	Find(templateID string) (*domain.NoteTemplate, error)
+	FindByUser(userID string) ([]*domain.NoteTemplate, error)
+	SaveFromNote(note *domain.Note, name string) (*domain.NoteTemplate, error)
+	Rename(template *domain.NoteTemplate, name string) (*domain.NoteTemplate, error)
+	Delete(template *domain.NoteTemplate) error
+}
+
+type templateUsecase struct {
+	db *gorm.DB
+}
+
+func NewTemplateUsecase() ITemplateUsecase {
+	db := sqlite.GetDB()
+	return &templateUsecase{
+		db: db,
+	}
+}
+
+func (t *templateUsecase) newQuery() (*repository.Query, repository.INoteTemplateDo) {
+	q := repository.Use(t.db)
+	do := q.NoteTemplate.WithContext(t.db.Statement.Context)
+	return q, do
+}
+
+func normalizeTemplateName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > maxTemplateNameLength {
+		return "", ErrInvalidTemplateName
+	}
+	return name, nil
+}
+
+func (t *templateUsecase) Find(templateID string) (*domain.NoteTemplate, error) {
+	q, do := t.newQuery()
+	return do.Where(q.NoteTemplate.ID.Eq(templateID)).First()
+}
+
+// FindByUser returns the templates of userID by name, without their content.
+func (t *templateUsecase) FindByUser(userID string) ([]*domain.NoteTemplate, error) {
+	q, do := t.newQuery()
+	return do.Omit(q.NoteTemplate.Content).
+		Where(q.NoteTemplate.UserID.Eq(userID)).
+		Order(q.NoteTemplate.Name).
+		Find()
+}
+
+// SaveFromNote stores the title, content and format of note as a template of
+// its author. If the author already has a template with the name, that
+// template is overwritten.
+func (t *templateUsecase) SaveFromNote(note *domain.Note, name string) (*domain.NoteTemplate, error) {
+	if note == nil {
+		return nil, errors.New("note cannot be nil")
+	}
+	name, err := normalizeTemplateName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var template *domain.NoteTemplate
+	q, _ := t.newQuery()
+	err = q.Transaction(func(tx *repository.Query) error {
+		tpl := tx.NoteTemplate
+		do := tpl.WithContext(t.db.Statement.Context)
+		existing, err := do.Where(tpl.UserID.Eq(note.AuthorID), tpl.Name.Eq(name)).First()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			template = &domain.NoteTemplate{
+				ID:      newUUID(),
+				UserID:  note.AuthorID,
+				Name:    name,
+				Title:   note.Title,
+				Content: note.Content,
+				Format:  note.Format,
+			}
+			return do.Create(template)
+		}
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		if _, err := do.Where(tpl.ID.Eq(existing.ID)).UpdateSimple(
+			tpl.Title.Value(note.Title),
+			tpl.Content.Value(note.Content),
+			tpl.Format.Value(note.Format),
+			tpl.UpdatedAt.Value(now),
+		); err != nil {
+			return err
+		}
+		existing.Title = note.Title
+		existing.Content = note.Content
+		existing.Format = note.Format
+		existing.UpdatedAt = &now
+		template = existing
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (t *templateUsecase) Rename(template *domain.NoteTemplate, name string) (*domain.NoteTemplate, error) {
+	if template == nil {
+		return nil, errors.New("template cannot be nil")
+	}
+	name, err := normalizeTemplateName(name)
+	if err != nil {
+		return nil, err
+	}
+	q, do := t.newQuery()
+	count, err := do.Where(q.NoteTemplate.UserID.Eq(template.UserID), q.NoteTemplate.Name.Eq(name), q.NoteTemplate.ID.Neq(template.ID)).Count()
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrTemplateNameTaken
+	}
+	if _, err := do.Where(q.NoteTemplate.ID.Eq(template.ID)).Update(q.NoteTemplate.Name, name); err != nil {
+		return nil, err
+	}
+	template.Name = name
+	return template, nil
+}
+
+func (t *templateUsecase) Delete(template *domain.NoteTemplate) error {
+	if template == nil {
+		return errors.New("template cannot be nil")
+	}
+	q, do := t.newQuery()
+	_, err := do.Where(q.NoteTemplate.ID.Eq(template.ID)).Delete()
+	return err
+}
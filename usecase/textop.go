@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrTextOpMismatch is returned when an operation does not fit the length of
+// the text it is applied to or transformed against.
+var ErrTextOpMismatch = errors.New("text operation does not match the document")
+
+// TextOp is an operational-transformation edit of a text. It is a sequence of
+// components that retain, insert or delete Unicode code points from the start
+// to the end of the document. In JSON it uses the ot.js encoding: a positive
+// number retains, a negative number deletes and a string inserts.
+type TextOp []textOpComponent
+
+type textOpComponent struct {
+	retain int
+	insert string
+	delete int
+}
+
+func (c textOpComponent) isRetain() bool { return c.retain > 0 }
+func (c textOpComponent) isInsert() bool { return c.insert != "" }
+func (c textOpComponent) isDelete() bool { return c.delete > 0 }
+
+// Retain appends a component that keeps the next n code points.
+func (op TextOp) Retain(n int) TextOp {
+	if n <= 0 {
+		return op
+	}
+	if last := len(op) - 1; last >= 0 && op[last].isRetain() {
+		op[last].retain += n
+		return op
+	}
+	return append(op, textOpComponent{retain: n})
+}
+
+// Insert appends a component that inserts s. An insert directly after a
+// delete is placed before it, so that equal edits have a single encoding.
+func (op TextOp) Insert(s string) TextOp {
+	if s == "" {
+		return op
+	}
+	last := len(op) - 1
+	if last >= 0 && op[last].isInsert() {
+		op[last].insert += s
+		return op
+	}
+	if last >= 0 && op[last].isDelete() {
+		if last > 0 && op[last-1].isInsert() {
+			op[last-1].insert += s
+			return op
+		}
+		op = append(op, op[last])
+		op[last] = textOpComponent{insert: s}
+		return op
+	}
+	return append(op, textOpComponent{insert: s})
+}
+
+// Delete appends a component that removes the next n code points.
+func (op TextOp) Delete(n int) TextOp {
+	if n <= 0 {
+		return op
+	}
+	if last := len(op) - 1; last >= 0 && op[last].isDelete() {
+		op[last].delete += n
+		return op
+	}
+	return append(op, textOpComponent{delete: n})
+}
+
+// BaseLen is the length in code points of the text op can be applied to.
+func (op TextOp) BaseLen() int {
+	n := 0
+	for _, c := range op {
+		n += c.retain + c.delete
+	}
+	return n
+}
+
+// TargetLen is the length in code points of the text after applying op.
+func (op TextOp) TargetLen() int {
+	n := 0
+	for _, c := range op {
+		n += c.retain + utf8.RuneCountInString(c.insert)
+	}
+	return n
+}
+
+// IsNoop reports whether op leaves the text unchanged.
+func (op TextOp) IsNoop() bool {
+	for _, c := range op {
+		if !c.isRetain() {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the text produced by applying op to text.
+func (op TextOp) Apply(text []rune) ([]rune, error) {
+	if op.BaseLen() != len(text) {
+		return nil, ErrTextOpMismatch
+	}
+	// 長さの合計があふれた操作でも、範囲外を読んだり過大に確保したりしない
+	result := make([]rune, 0, len(text))
+	pos := 0
+	for _, c := range op {
+		if c.retain > len(text)-pos || c.delete > len(text)-pos {
+			return nil, ErrTextOpMismatch
+		}
+		switch {
+		case c.isRetain():
+			result = append(result, text[pos:pos+c.retain]...)
+			pos += c.retain
+		case c.isInsert():
+			result = append(result, []rune(c.insert)...)
+		case c.isDelete():
+			pos += c.delete
+		}
+	}
+	return result, nil
+}
+
+// TransformTextOps takes two operations a and b made concurrently on the same
+// text and returns a' and b' such that applying a then b' gives the same text
+// as applying b then a'. When both insert at the same position, the text of a
+// comes first.
+func TransformTextOps(a, b TextOp) (TextOp, TextOp, error) {
+	if a.BaseLen() != b.BaseLen() {
+		return nil, nil, ErrTextOpMismatch
+	}
+	var aPrime, bPrime TextOp
+	ia, ib := &textOpIter{op: a}, &textOpIter{op: b}
+	c1, c2 := ia.next(), ib.next()
+	for c1 != nil || c2 != nil {
+		if c1 != nil && c1.isInsert() {
+			aPrime = aPrime.Insert(c1.insert)
+			bPrime = bPrime.Retain(utf8.RuneCountInString(c1.insert))
+			c1 = ia.next()
+			continue
+		}
+		if c2 != nil && c2.isInsert() {
+			aPrime = aPrime.Retain(utf8.RuneCountInString(c2.insert))
+			bPrime = bPrime.Insert(c2.insert)
+			c2 = ib.next()
+			continue
+		}
+		if c1 == nil || c2 == nil {
+			return nil, nil, ErrTextOpMismatch
+		}
+
+		n := min(c1.retain+c1.delete, c2.retain+c2.delete)
+		switch {
+		case c1.isRetain() && c2.isRetain():
+			aPrime = aPrime.Retain(n)
+			bPrime = bPrime.Retain(n)
+		case c1.isDelete() && c2.isRetain():
+			aPrime = aPrime.Delete(n)
+		case c1.isRetain() && c2.isDelete():
+			bPrime = bPrime.Delete(n)
+		}
+		// 両方が削除した範囲はどちらの結果にも現れない
+		c1 = ia.consume(c1, n)
+		c2 = ib.consume(c2, n)
+	}
+	return aPrime, bPrime, nil
+}
+
+// textOpIter walks the components of an operation while they are split by
+// TransformTextOps.
+type textOpIter struct {
+	op  TextOp
+	pos int
+}
+
+func (it *textOpIter) next() *textOpComponent {
+	if it.pos >= len(it.op) {
+		return nil
+	}
+	c := it.op[it.pos]
+	it.pos++
+	return &c
+}
+
+// consume shortens the retain or delete c by n code points and moves on to
+// the next component once c is used up.
+func (it *textOpIter) consume(c *textOpComponent, n int) *textOpComponent {
+	if c.isRetain() {
+		c.retain -= n
+	} else {
+		c.delete -= n
+	}
+	if c.retain > 0 || c.delete > 0 {
+		return c
+	}
+	return it.next()
+}
+
+// diffTextOp returns an operation that turns from into to by replacing the
+// part between their common prefix and suffix.
+func diffTextOp(from, to []rune) TextOp {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix &&
+		from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	var op TextOp
+	op = op.Retain(prefix)
+	op = op.Insert(string(to[prefix : len(to)-suffix]))
+	op = op.Delete(len(from) - prefix - suffix)
+	return op.Retain(suffix)
+}
+
+func (op TextOp) MarshalJSON() ([]byte, error) {
+	components := make([]any, 0, len(op))
+	for _, c := range op {
+		switch {
+		case c.isRetain():
+			components = append(components, c.retain)
+		case c.isInsert():
+			components = append(components, c.insert)
+		case c.isDelete():
+			components = append(components, -c.delete)
+		}
+	}
+	return json.Marshal(components)
+}
+
+func (op *TextOp) UnmarshalJSON(data []byte) error {
+	var components []json.RawMessage
+	if err := json.Unmarshal(data, &components); err != nil {
+		return err
+	}
+	var parsed TextOp
+	for _, raw := range components {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			if s == "" {
+				return errors.New("empty insert in text operation")
+			}
+			parsed = parsed.Insert(s)
+			continue
+		}
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return fmt.Errorf("invalid text operation component: %s", raw)
+		}
+		switch {
+		case n > 0:
+			parsed = parsed.Retain(n)
+		case n < 0:
+			parsed = parsed.Delete(-n)
+		default:
+			return errors.New("zero-length component in text operation")
+		}
+	}
+	*op = parsed
+	return nil
+}
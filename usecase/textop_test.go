@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func mustTextOp(t *testing.T, encoded string) TextOp {
+	t.Helper()
+	var op TextOp
+	if err := json.Unmarshal([]byte(encoded), &op); err != nil {
+		t.Fatalf("invalid op %s: %v", encoded, err)
+	}
+	return op
+}
+
+func applyTextOp(t *testing.T, op TextOp, text string) string {
+	t.Helper()
+	result, err := op.Apply([]rune(text))
+	if err != nil {
+		t.Fatalf("apply %v to %q: %v", op, text, err)
+	}
+	return string(result)
+}
+
+func TestTransformTextOpsConverges(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		a    string
+		b    string
+		want string
+	}{
+		{"insert vs insert at the same position", "abc", `[1,"X",2]`, `[1,"Y",2]`, "aXYbc"},
+		{"insert vs insert at the start", "abc", `["X",3]`, `["Y",3]`, "XYabc"},
+		{"insert vs insert at the end", "abc", `[3,"X"]`, `[3,"Y"]`, "abcXY"},
+		{"insert vs insert at different positions", "abc", `["X",3]`, `[3,"Y"]`, "XabcY"},
+		{"insert inside a deleted range", "abcdef", `[3,"X",3]`, `[1,-4,1]`, "aXf"},
+		{"delete vs delete with overlap", "abcdef", `[1,-3,2]`, `[2,-3,1]`, "af"},
+		{"delete vs delete of the same range", "abcdef", `[2,-2,2]`, `[2,-2,2]`, "abef"},
+		{"delete vs delete where one contains the other", "abcdef", `[-6]`, `[2,-2,2]`, ""},
+		{"replace vs replace", "abcdef", `[2,"XY",-2,2]`, `[3,"Z",-2,1]`, "abXYZf"},
+		{"multi-byte text", "日本語のメモ", `[2,"英",-1,3]`, `[4,-2,"帳"]`, "日本英の帳"},
+		{"surrogate pairs count as one code point", "a😀b😀c", `[2,"🎉",3]`, `[1,-1,"✅",3]`, "a✅🎉b😀c"},
+		{"noop vs edit", "abc", `[3]`, `[1,-1,"Z",1]`, "aZc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := mustTextOp(t, tt.a), mustTextOp(t, tt.b)
+			aPrime, bPrime, err := TransformTextOps(a, b)
+			if err != nil {
+				t.Fatalf("transform: %v", err)
+			}
+			ab := applyTextOp(t, bPrime, applyTextOp(t, a, tt.doc))
+			ba := applyTextOp(t, aPrime, applyTextOp(t, b, tt.doc))
+			if ab != ba {
+				t.Fatalf("did not converge: a then b' = %q, b then a' = %q", ab, ba)
+			}
+			if ab != tt.want {
+				t.Errorf("got %q, want %q", ab, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformTextOpsRejectsDifferentBaseLengths(t *testing.T) {
+	_, _, err := TransformTextOps(mustTextOp(t, `[3]`), mustTextOp(t, `[2,"X"]`))
+	if !errors.Is(err, ErrTextOpMismatch) {
+		t.Errorf("got %v, want ErrTextOpMismatch", err)
+	}
+}
+
+func TestTextOpApplyRejectsOutOfRangeOps(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		op   TextOp
+	}{
+		{"retain past the end", "abc", TextOp{}.Retain(4)},
+		{"delete past the end", "abc", TextOp{}.Retain(2).Delete(2)},
+		{"shorter than the text", "abc", TextOp{}.Retain(2)},
+		{"multi-byte text counted in bytes", "日本語", TextOp{}.Retain(9)},
+		// 長さの合計があふれて BaseLen が一致しても、範囲外は読まない
+		{"overflowing lengths", "abc", TextOp{{retain: 1 << 62}, {delete: 1 << 62}, {retain: 1 << 62}, {delete: 1<<62 + 3}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.op.Apply([]rune(tt.doc)); !errors.Is(err, ErrTextOpMismatch) {
+				t.Errorf("got %v, want ErrTextOpMismatch", err)
+			}
+		})
+	}
+}
+
+func TestDiffTextOp(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+	}{
+		{"", ""},
+		{"", "abc"},
+		{"abc", ""},
+		{"abc", "abc"},
+		{"abcdef", "abXYef"},
+		{"aaa", "aaaa"},
+		{"日本語のメモ", "日本のメモ帳"},
+		{"a😀b", "a😀😀b"},
+	}
+	for _, tt := range tests {
+		op := diffTextOp([]rune(tt.from), []rune(tt.to))
+		if got := applyTextOp(t, op, tt.from); got != tt.to {
+			t.Errorf("diff %q -> %q: got %q", tt.from, tt.to, got)
+		}
+	}
+}
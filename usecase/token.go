@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/sqlite"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gorm"
+)
+
+// Scopes of personal access tokens. A token can only call the API routes
+// that require one of its scopes.
+const (
+	ScopeNotesRead    = "notes:read"
+	ScopeNotesWrite   = "notes:write"
+	ScopeSharesManage = "shares:manage"
+)
+
+// AccessTokenScopes lists every scope a token can be given.
+var AccessTokenScopes = []string{ScopeNotesRead, ScopeNotesWrite, ScopeSharesManage}
+
+// accessTokenPrefix makes tokens recognizable, e.g. by secret scanners.
+const accessTokenPrefix = "elib_pat_"
+
+// accessTokenTouchInterval limits how often LastUsedAt is written, so that a
+// busy client does not cause a write on every request.
+const accessTokenTouchInterval = time.Minute
+
+var (
+	// ErrInvalidAccessToken is returned when a token does not exist or has
+	// been revoked.
+	ErrInvalidAccessToken = errors.New("invalid access token")
+	// ErrAccessTokenExpired is returned when a token is past its expiry.
+	ErrAccessTokenExpired = errors.New("access token has expired")
+	// ErrInvalidAccessTokenScope is returned when a token is created without
+	// scopes or with an unknown one.
+	ErrInvalidAccessTokenScope = errors.New("invalid access token scope")
+)
+
+type IAccessTokenUsecase interface {
+	Create(user *domain.User, name string, scopes []string, expiresAt *time.Time) (*domain.PersonalAccessToken, string, error)
+	FindByUser(userID string) ([]*domain.PersonalAccessToken, error)
+	Revoke(userID string, tokenID string) error
+	Authenticate(token string) (*domain.User, []string, error)
+}
+
+type accessTokenUsecase struct {
+	db *gorm.DB
+}
+
+func NewAccessTokenUsecase() IAccessTokenUsecase {
+	db := sqlite.GetDB()
+	return &accessTokenUsecase{
+		db: db,
+	}
+}
+
+func (a *accessTokenUsecase) newQuery() (*repository.Query, repository.IPersonalAccessTokenDo) {
+	q := repository.Use(a.db)
+	do := q.PersonalAccessToken.WithContext(a.db.Statement.Context)
+	return q, do
+}
+
+// AccessTokenScopeList splits the scopes stored with a token.
+func AccessTokenScopeList(token *domain.PersonalAccessToken) []string {
+	return strings.Fields(token.Scopes)
+}
+
+func hashAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAccessToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return accessTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Create issues a token for user and returns it with its plain text value.
+// Only the hash is stored, so the plain text cannot be shown again.
+func (a *accessTokenUsecase) Create(user *domain.User, name string, scopes []string, expiresAt *time.Time) (*domain.PersonalAccessToken, string, error) {
+	if user == nil {
+		return nil, "", errors.New("user cannot be nil")
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidAccessTokenScope
+	}
+	// 保存する順序を揃え、重複を取り除く
+	var granted []string
+	for _, scope := range AccessTokenScopes {
+		if slices.Contains(scopes, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AccessTokenScopes, scope) {
+			return nil, "", ErrInvalidAccessTokenScope
+		}
+	}
+
+	plain, err := generateAccessToken()
+	if err != nil {
+		return nil, "", err
+	}
+	token := &domain.PersonalAccessToken{
+		ID:        newUUID(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hashAccessToken(plain),
+		Scopes:    strings.Join(granted, " "),
+		ExpiresAt: expiresAt,
+	}
+	_, do := a.newQuery()
+	if err := do.Create(token); err != nil {
+		return nil, "", err
+	}
+	slog.Info("created access token", "userId", user.ID, "tokenId", token.ID, "scopes", token.Scopes)
+	return token, plain, nil
+}
+
+func (a *accessTokenUsecase) FindByUser(userID string) ([]*domain.PersonalAccessToken, error) {
+	q, do := a.newQuery()
+	return do.Where(q.PersonalAccessToken.UserID.Eq(userID)).
+		Order(q.PersonalAccessToken.CreatedAt.Desc()).
+		Find()
+}
+
+// Revoke deletes a token of userID. It returns gorm.ErrRecordNotFound if the
+// user has no such token.
+func (a *accessTokenUsecase) Revoke(userID string, tokenID string) error {
+	q, do := a.newQuery()
+	res, err := do.Where(q.PersonalAccessToken.ID.Eq(tokenID), q.PersonalAccessToken.UserID.Eq(userID)).Delete()
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	slog.Info("revoked access token", "userId", userID, "tokenId", tokenID)
+	return nil
+}
+
+// Authenticate returns the owner and the scopes of a plain text token, and
+// records when it was used.
+func (a *accessTokenUsecase) Authenticate(plain string) (*domain.User, []string, error) {
+	if !strings.HasPrefix(plain, accessTokenPrefix) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	q, do := a.newQuery()
+	token, err := do.Where(q.PersonalAccessToken.TokenHash.Eq(hashAccessToken(plain))).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if token.ExpiresAt != nil && !now.Before(*token.ExpiresAt) {
+		return nil, nil, ErrAccessTokenExpired
+	}
+
+	user, err := q.User.WithContext(a.db.Statement.Context).Where(q.User.ID.Eq(token.UserID)).First()
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrInvalidAccessToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= accessTokenTouchInterval {
+		if _, err := do.Where(q.PersonalAccessToken.ID.Eq(token.ID)).
+			UpdateColumnSimple(q.PersonalAccessToken.LastUsedAt.Value(now)); err != nil {
+			slog.Warn("failed to record access token use", "tokenId", token.ID, "error", err)
+		}
+	}
+	return user, AccessTokenScopeList(token), nil
+}
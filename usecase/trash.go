@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/ToshihiroOgino/elib/domain"
+	"github.com/ToshihiroOgino/elib/infra/storage"
+	"github.com/ToshihiroOgino/elib/repository"
+	"gorm.io/gen/field"
+	"gorm.io/gorm"
+)
+
+// trashPurgeInterval is how often the purger looks for expired notes.
+const trashPurgeInterval = time.Hour
+
+// FindTrashed returns a note that is in the trash.
+func (n *noteUsecase) FindTrashed(noteId string) (*domain.Note, error) {
+	q, do := n.newQuery()
+	return do.Unscoped().Where(q.Note.ID.Eq(noteId), q.Note.DeletedAt.IsNotNull()).First()
+}
+
+func (n *noteUsecase) FindTrashByUserID(userID string) ([]*domain.Note, error) {
+	q, do := n.newQuery()
+	return do.Unscoped().
+		Where(q.Note.AuthorID.Eq(userID), q.Note.DeletedAt.IsNotNull()).
+		Order(q.Note.DeletedAt.Desc()).
+		Find()
+}
+
+// Restore takes note out of the trash. If its folder has been deleted in the
+// meantime, the note is restored to the top level.
+func (n *noteUsecase) Restore(note *domain.Note) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	q, _ := n.newQuery()
+	err := q.Transaction(func(tx *repository.Query) error {
+		assigns := []field.AssignExpr{tx.Note.DeletedAt.Null()}
+		if note.FolderID != nil {
+			count, err := tx.Folder.WithContext(n.db.Statement.Context).Where(tx.Folder.ID.Eq(*note.FolderID)).Count()
+			if err != nil {
+				return err
+			}
+			if count == 0 {
+				assigns = append(assigns, tx.Note.FolderID.Null())
+			}
+		}
+		_, err := tx.Note.WithContext(n.db.Statement.Context).Unscoped().Where(tx.Note.ID.Eq(note.ID)).UpdateColumnSimple(assigns...)
+		if err != nil {
+			return err
+		}
+		// 同じタイトルへのリンクが、ゴミ箱から戻したメモを指すようにする
+		return resolveNoteLinksByTitle(tx, note.AuthorID, []string{note.Title})
+	})
+	if err != nil {
+		return err
+	}
+	slog.Info("Note restored from trash", "noteID", note.ID)
+	return nil
+}
+
+// DeletePermanently removes a note and everything that refers to it.
+func (n *noteUsecase) DeletePermanently(note *domain.Note) error {
+	if note == nil {
+		return errors.New("note cannot be nil")
+	}
+	deleted, err := n.deletePermanently([]string{note.ID})
+	if err != nil {
+		return err
+	}
+	slog.Info("Note deleted permanently", "noteID", note.ID, "rowsAffected", deleted)
+	return nil
+}
+
+// EmptyTrash permanently deletes every note in the user's trash.
+func (n *noteUsecase) EmptyTrash(userID string) (int64, error) {
+	q, do := n.newQuery()
+	var noteIDs []string
+	err := do.Unscoped().
+		Where(q.Note.AuthorID.Eq(userID), q.Note.DeletedAt.IsNotNull()).
+		Pluck(q.Note.ID, &noteIDs)
+	if err != nil {
+		return 0, err
+	}
+	return n.deletePermanently(noteIDs)
+}
+
+// PurgeTrash permanently deletes notes that were moved to the trash before
+// the given time.
+func (n *noteUsecase) PurgeTrash(before time.Time) (int64, error) {
+	q, do := n.newQuery()
+	var noteIDs []string
+	err := do.Unscoped().
+		Where(q.Note.DeletedAt.IsNotNull(), q.Note.DeletedAt.Lt(gorm.DeletedAt{Time: before, Valid: true})).
+		Pluck(q.Note.ID, &noteIDs)
+	if err != nil {
+		return 0, err
+	}
+	return n.deletePermanently(noteIDs)
+}
+
+func (n *noteUsecase) deletePermanently(noteIDs []string) (int64, error) {
+	var deleted int64
+	var blobKeys []string
+	q, _ := n.newQuery()
+	err := q.Transaction(func(tx *repository.Query) error {
+		var err error
+		deleted, blobKeys, err = deleteNotes(tx, noteIDs)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(blobKeys) > 0 {
+		blobMu.Lock()
+		defer blobMu.Unlock()
+		collectBlobs(n.db, storage.GetStorage(), blobKeys)
+	}
+	return deleted, nil
+}
+
+// StartTrashPurger runs PurgeTrash in the background so that notes are
+// deleted permanently once they have been in the trash for longer than
+// retention. A non-positive retention disables purging.
+func StartTrashPurger(retention time.Duration) {
+	if retention <= 0 {
+		slog.Info("trash purge is disabled")
+		return
+	}
+	noteUsecase := NewNoteUsecase()
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purged, err := noteUsecase.PurgeTrash(time.Now().Add(-retention))
+			if err != nil {
+				slog.Error("failed to purge trash", "error", err)
+			} else if purged > 0 {
+				slog.Info("purged notes from trash", "count", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"log/slog"
+	"strings"
 
 	"github.com/google/uuid"
 )
@@ -14,3 +15,22 @@ func newUUID() string {
 	}
 	return id.String()
 }
+
+// isControlRune reports whether r is a control character that is not allowed
+// in notes. Newlines and tabs are allowed.
+func isControlRune(r rune) bool {
+	return (r < 32 && r != '\n' && r != '\r' && r != '\t') || (r >= 127 && r < 160)
+}
+
+// stripControlChars removes the characters rejected by isControlRune.
+func stripControlChars(s string) string {
+	if strings.IndexFunc(s, isControlRune) < 0 {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isControlRune(r) {
+			return -1
+		}
+		return r
+	}, s)
+}